@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/injection"
+	"github.com/leonardotrapani/hyprvoice/internal/recording"
+	"github.com/leonardotrapani/hyprvoice/internal/transcript"
+	"github.com/spf13/cobra"
+)
+
+func dictateCmd() *cobra.Command {
+	var stdout bool
+	var silence time.Duration
+	var overrides overrideFlags
+	var verbose bool
+
+	cmd := &cobra.Command{
+		Use:   "dictate",
+		Short: "Record one dictation without the daemon",
+		Long: `Record one dictation, transcribe it and optionally clean it up with the
+LLM, without a running daemon. Recording stops on Enter (when stdin is a
+terminal), on SIGINT/SIGTERM, after --silence of silence following speech, or
+at recording.timeout. A second signal aborts.
+
+With --stdout the text is printed instead of injected, for shell and rofi
+scripts. Status messages go to stderr.
+
+Examples:
+  git commit -m "$(hyprvoice dictate --stdout)"
+  hyprvoice dictate --stdout --language de --no-llm | wl-copy
+  hyprvoice dictate --backend clipboard`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !verbose {
+				log.SetOutput(io.Discard)
+			}
+
+			cfg, err := loadConfigQuiet()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			cfg, err = applyFileOverrides(cfg, "", overrides)
+			if err != nil {
+				return err
+			}
+
+			// a signal stops recording; once stopped, a signal cancels everything
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			stopCh := make(chan struct{})
+			stop := sync.OnceFunc(func() { close(stopCh) })
+
+			sigCh := make(chan os.Signal, 1)
+			signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+			defer signal.Stop(sigCh)
+			go func() {
+				for range sigCh {
+					select {
+					case <-stopCh:
+						cancel()
+					default:
+						stop()
+					}
+				}
+			}()
+
+			if isTerminal(os.Stdin) {
+				go func() {
+					bufio.NewReader(os.Stdin).ReadString('\n')
+					stop()
+				}()
+			}
+
+			recCfg := cfg.ToRecordingConfig()
+			var detector *recording.SilenceDetector
+			if silence > 0 {
+				detector = recording.NewSilenceDetector(recCfg, recording.DefaultSilenceLevel, silence)
+			}
+
+			status := func(format string, args ...interface{}) {
+				if isTerminal(os.Stderr) {
+					fmt.Fprintf(os.Stderr, format, args...)
+				}
+			}
+			if isTerminal(os.Stdin) {
+				status("Recording... press Enter to stop\n")
+			} else {
+				status("Recording... send SIGINT to stop\n")
+			}
+
+			pcm, err := recordUntil(ctx, recording.NewRecorder(recCfg), stopCh, detector, recording.NewMeter(recCfg), recCfg.Timeout)
+			if err != nil {
+				return err
+			}
+			if len(pcm) == 0 {
+				return fmt.Errorf("no audio recorded")
+			}
+
+			status("Transcribing...\n")
+			result, err := transcript.Transcribe(ctx, cfg, pcm)
+			if err != nil {
+				return err
+			}
+			for _, warning := range result.Warnings {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+			}
+			// segments of one dictation read as one paragraph
+			text := strings.Join(strings.Fields(result.Text), " ")
+			if text == "" {
+				return fmt.Errorf("no speech recognized")
+			}
+
+			if stdout {
+				fmt.Println(text)
+				return nil
+			}
+			injector := injection.NewInjector(cfg.ToInjectionConfig())
+			if err := injector.Inject(ctx, text); err != nil {
+				return fmt.Errorf("failed to inject text: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&stdout, "stdout", false, "print the text instead of injecting it")
+	cmd.Flags().DurationVar(&silence, "silence", 2*time.Second, "stop after this much silence following speech (0 to disable)")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "log recording and provider details to stderr")
+	overrides.register(cmd)
+
+	return cmd
+}
+
+// recordUntil records until stopCh closes, the detector hears enough
+// silence after speech, timeout passes or ctx ends, and returns the audio
+// including the frames still buffered when recording stopped. It fails when
+// ctx ends or the recorder fails before any audio arrived. A meter, if
+// given, warns when the microphone only records silence.
+func recordUntil(ctx context.Context, recorder recording.Recorder, stopCh <-chan struct{}, detector *recording.SilenceDetector, meter *recording.Meter, timeout time.Duration) ([]byte, error) {
+	frameCh, errCh, err := recorder.Start(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start recording: %w", err)
+	}
+	defer recorder.Stop()
+
+	var timeoutCh <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+
+	// stopping stops the recorder and keeps reading until it closes frameCh
+	stopping := false
+	finish := func() {
+		if !stopping {
+			stopping = true
+			stopCh, timeoutCh, detector = nil, nil, nil
+			go recorder.Stop()
+		}
+	}
+
+	var pcm []byte
+	for {
+		select {
+		case frame, ok := <-frameCh:
+			if !ok {
+				return pcm, nil
+			}
+			pcm = append(pcm, frame.Data...)
+			if detector != nil && detector.Feed(frame.Data) {
+				finish()
+			}
+			if meter != nil {
+				meter.Feed(frame.Data)
+				if meter.Silent() {
+					fmt.Fprintf(os.Stderr, "Warning: no sound from the microphone for %v, check that it is not muted and recording.device\n", recording.SilentMicAfter)
+				}
+			}
+		case err, ok := <-errCh:
+			if !ok {
+				errCh = nil
+				continue
+			}
+			if err != nil && len(pcm) == 0 && !stopping {
+				return nil, fmt.Errorf("recording failed: %w", err)
+			}
+			if err != nil {
+				slog.Warn("Recording error, keeping the audio so far", "bytes", len(pcm), "err", err)
+			}
+			finish()
+		case <-stopCh:
+			finish()
+		case <-timeoutCh:
+			fmt.Fprintf(os.Stderr, "Recording timeout (%v) reached\n", timeout)
+			finish()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/config"
+	"github.com/leonardotrapani/hyprvoice/internal/history"
+	"github.com/spf13/cobra"
+)
+
+func historyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Browse and export past dictations",
+		Long: `Browse and export past dictations.
+
+Every dictation is saved to ~/.local/share/hyprvoice/history.jsonl with the raw
+transcript, LLM output, provider/model, language, stage durations, injection
+backend and any errors. Configure retention and encryption in [history].`,
+	}
+
+	cmd.AddCommand(historyListCmd())
+	cmd.AddCommand(historyShowCmd())
+	cmd.AddCommand(historySearchCmd())
+	cmd.AddCommand(historyExportCmd())
+
+	return cmd
+}
+
+func historyListCmd() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recent dictations (newest first)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := loadHistory()
+			if err != nil {
+				return err
+			}
+			printHistoryEntries(os.Stdout, entries, limit)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "number of entries to show (0 = all)")
+
+	return cmd
+}
+
+func historyShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <id|last>",
+		Short: "Show a single dictation",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := loadHistory()
+			if err != nil {
+				return err
+			}
+			entry, err := history.Find(entries, args[0])
+			if err != nil {
+				return err
+			}
+			printHistoryEntry(os.Stdout, entry)
+			return nil
+		},
+	}
+}
+
+func historySearchCmd() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "search <text>",
+		Short: "Search transcripts and LLM output",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := loadHistory()
+			if err != nil {
+				return err
+			}
+			matches := history.Search(entries, strings.Join(args, " "))
+			if len(matches) == 0 {
+				return fmt.Errorf("no dictations match %q", strings.Join(args, " "))
+			}
+			printHistoryEntries(os.Stdout, matches, limit)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 0, "number of matches to show (0 = all)")
+
+	return cmd
+}
+
+func historyExportCmd() *cobra.Command {
+	var format string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export history as JSON, CSV or Markdown",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := loadHistory()
+			if err != nil {
+				return err
+			}
+
+			var w io.Writer = os.Stdout
+			if output != "" && output != "-" {
+				f, err := os.OpenFile(output, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+				if err != nil {
+					return fmt.Errorf("failed to create export file: %w", err)
+				}
+				defer f.Close()
+				w = f
+			}
+
+			if err := history.Export(w, entries, format); err != nil {
+				return fmt.Errorf("failed to export history: %w", err)
+			}
+			if w != os.Stdout {
+				fmt.Fprintf(os.Stderr, "Exported %d entries to %s\n", len(entries), output)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", history.FormatJSON, "export format: json, csv, md")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write to file instead of stdout")
+
+	return cmd
+}
+
+// loadHistory reads the entries within the configured retention, oldest
+// first. Entries that cannot be decrypted are skipped with a warning.
+func loadHistory() ([]history.Entry, error) {
+	// only the limits are taken from the config; encrypt would create a key
+	var cfg history.Config
+	if conf, err := config.Load(); err == nil {
+		cfg.MaxEntries, cfg.MaxAge = conf.History.MaxEntries, conf.History.MaxAge
+	}
+	store, err := history.NewStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	entries, err := store.List()
+	if errors.Is(err, history.ErrUnreadable) {
+		keyFile, _ := history.DefaultKeyFile()
+		fmt.Fprintf(os.Stderr, "Warning: %v; restore %s to read them\n", err, keyFile)
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return entries, nil
+}
+
+func printHistoryEntries(w io.Writer, entries []history.Entry, limit int) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No dictations yet")
+		return
+	}
+
+	count := 0
+	for i := len(entries) - 1; i >= 0; i-- {
+		if limit > 0 && count == limit {
+			break
+		}
+		e := entries[i]
+
+		marker := " "
+		if len(e.Errors) > 0 {
+			marker = "!"
+		}
+		fmt.Fprintf(w, "%s %s %s  %s\n", marker, e.ID, e.Time.Local().Format("2006-01-02 15:04"), preview(e.Text(), 60))
+		count++
+	}
+}
+
+func printHistoryEntry(w io.Writer, e history.Entry) {
+	fmt.Fprintf(w, "ID:        %s\n", e.ID)
+	fmt.Fprintf(w, "Time:      %s\n", e.Time.Local().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "Model:     %s / %s\n", e.Provider, e.Model)
+	if e.LLMModel != "" {
+		fmt.Fprintf(w, "LLM:       %s / %s\n", e.LLMProvider, e.LLMModel)
+	}
+	if e.Language != "" {
+		fmt.Fprintf(w, "Language:  %s\n", e.Language)
+	}
+	if e.Backend != "" {
+		fmt.Fprintf(w, "Backend:   %s\n", e.Backend)
+	}
+	fmt.Fprintf(w, "Durations: recording %s, transcription %s, llm %s, injection %s\n",
+		formatMs(e.Durations.RecordingMs), formatMs(e.Durations.TranscriptionMs),
+		formatMs(e.Durations.LLMMs), formatMs(e.Durations.InjectionMs))
+	for _, errMsg := range e.Errors {
+		fmt.Fprintf(w, "Error:     %s\n", errMsg)
+	}
+
+	fmt.Fprintf(w, "\nTranscript:\n%s\n", e.Transcript)
+	if e.LLMOutput != "" {
+		fmt.Fprintf(w, "\nLLM output:\n%s\n", e.LLMOutput)
+	}
+}
+
+func preview(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) > max {
+		return string(runes[:max-3]) + "..."
+	}
+	return text
+}
+
+func formatMs(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
@@ -2,41 +2,101 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/leonardotrapani/hyprvoice/internal/bus"
 	"github.com/leonardotrapani/hyprvoice/internal/config"
 	"github.com/leonardotrapani/hyprvoice/internal/daemon"
+	"github.com/leonardotrapani/hyprvoice/internal/doctor"
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 	"github.com/leonardotrapani/hyprvoice/internal/models/whisper"
 	"github.com/leonardotrapani/hyprvoice/internal/provider"
+	"github.com/leonardotrapani/hyprvoice/internal/statusbar"
 	"github.com/leonardotrapani/hyprvoice/internal/tui"
 	"github.com/spf13/cobra"
 )
 
 func main() {
-	_ = rootCmd.Execute()
+	err := rootCmd.Execute()
+	var failed *exitError
+	if errors.As(err, &failed) {
+		os.Exit(1)
+	}
+}
+
+// exitError is a failure of a command whose exit status scripts rely on,
+// such as $(hyprvoice dictate --stdout). Other commands report errors but
+// exit 0 as they always have.
+type exitError struct{ error }
+
+func (e *exitError) Unwrap() error { return e.error }
+
+// exitStatus marks err, if any, as failing the process
+func exitStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{err}
+}
+
+// withExitStatus makes cmd exit non-zero when it fails
+func withExitStatus(cmd *cobra.Command) *cobra.Command {
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return exitStatus(run(cmd, args))
+	}
+	return cmd
 }
 
 var rootCmd = &cobra.Command{
 	Use:   "hyprvoice",
 	Short: "Voice-powered typing for Wayland/Hyprland",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return bus.SetInstance(instanceName)
+	},
 }
 
+// instanceName selects a named daemon instance for every command
+var instanceName string
+
 func init() {
+	rootCmd.PersistentFlags().StringVar(&instanceName, "instance", os.Getenv("HYPRVOICE_INSTANCE"),
+		"named daemon instance to serve or control (default $HYPRVOICE_INSTANCE)")
+
 	rootCmd.AddCommand(
 		serveCmd(),
+		serveAPICmd(),
+		transcribeCmd(),
+		withExitStatus(dictateCmd()),
 		toggleCmd(),
+		startCmd(),
+		stopCmd(),
 		cancelCmd(),
 		statusCmd(),
+		watchCmd(),
+		meterCmd(),
+		logsCmd(),
+		historyCmd(),
+		statsCmd(),
+		lastCmd(),
+		setCmd(),
+		unsetCmd(),
+		profileCmd(),
 		versionCmd(),
-		stopCmd(),
+		withExitStatus(doctorCmd()),
+		quitCmd(),
 		onboardingCmd(),
 		configureCmd(),
 		modelCmd(),
@@ -58,10 +118,31 @@ func serveCmd() *cobra.Command {
 }
 
 func toggleCmd() *cobra.Command {
-	return &cobra.Command{
+	var overrides overrideFlags
+	var wait waitFlags
+
+	cmd := &cobra.Command{
 		Use:   "toggle",
 		Short: "Toggle recording on/off",
+		Long: `Toggle recording on/off.
+
+The override flags only apply to the dictation started by this toggle:
+
+  hyprvoice toggle --language de --model nova-3 --no-llm --backend clipboard
+  hyprvoice toggle --profile email
+
+With --wait the command returns when the dictation it started or stopped
+ends, prints the injected text and exits non-zero if transcription, LLM
+post-processing or injection failed:
+
+  hyprvoice toggle --wait --json | jq -r .transcript`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if wait.enabled() {
+				return exitStatus(wait.run(bus.Request{Cmd: bus.CmdToggle, Args: overrides.args()}))
+			}
+			if overrideArgs := overrides.args(); overrideArgs != nil {
+				return runCommand(bus.Request{Cmd: bus.CmdToggle, Args: overrideArgs})
+			}
 			resp, err := bus.SendCommand('t')
 			if err != nil {
 				return fmt.Errorf("failed to toggle recording: %w", err)
@@ -70,28 +151,144 @@ func toggleCmd() *cobra.Command {
 			return nil
 		},
 	}
+
+	overrides.register(cmd)
+	wait.register(cmd)
+
+	return cmd
 }
 
 func statusCmd() *cobra.Command {
-	return &cobra.Command{
+	var jsonOutput bool
+	var format string
+	var follow bool
+
+	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Get current recording status",
+		Long: `Get current recording status.
+
+Formats:
+  text    legacy "STATUS status=..." line (default)
+  json    structured JSON status from the daemon
+  waybar  Waybar/i3bar custom module JSON (text, alt, tooltip, class)
+
+Use --follow with --format waybar to keep printing one line per update:
+
+  "custom/hyprvoice": {
+    "exec": "hyprvoice status --format waybar --follow",
+    "return-type": "json"
+  }`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			resp, err := bus.SendCommand('s')
+			if jsonOutput {
+				format = "json"
+			}
+			if follow && format != "waybar" {
+				return fmt.Errorf("--follow requires --format waybar")
+			}
+
+			switch format {
+			case "text":
+				resp, err := bus.SendCommand('s')
+				if err != nil {
+					return fmt.Errorf("failed to get status: %w", err)
+				}
+				fmt.Print(resp)
+				return nil
+			case "json":
+				return printJSONResponse(bus.Request{Cmd: bus.CmdStatus})
+			case "waybar":
+				if !follow {
+					return statusbar.Once(os.Stdout)
+				}
+				ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				return statusbar.Follow(ctx, os.Stdout)
+			default:
+				return fmt.Errorf("invalid format: %s (use text, json or waybar)", format)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "print the structured JSON status (same as --format json)")
+	cmd.Flags().StringVar(&format, "format", "text", "output format: text, json, waybar")
+	cmd.Flags().BoolVar(&follow, "follow", false, "keep printing updates (waybar format only)")
+
+	return cmd
+}
+
+func watchCmd() *cobra.Command {
+	var levels bool
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Stream daemon events as JSON lines",
+		Long: `Subscribe to the daemon and print one JSON event per line for every
+pipeline status change, notification, pipeline error and streaming transcript.
+With --levels the input level events sent every 100ms while recording are
+printed too.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			enc := json.NewEncoder(os.Stdout)
+			err := bus.Subscribe(ctx, func(ev bus.Event) error {
+				if ev.Type == bus.EventLevel && !levels {
+					return nil
+				}
+				return enc.Encode(ev)
+			})
 			if err != nil {
-				return fmt.Errorf("failed to get status: %w", err)
+				return fmt.Errorf("failed to watch daemon: %w", err)
 			}
-			fmt.Print(resp)
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&levels, "levels", false, "also print input level events")
+
+	return cmd
+}
+
+func logsCmd() *cobra.Command {
+	var follow bool
+	var lines int
+
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show the daemon log file",
+		Long: `Print the end of the daemon log file. The file is only written when
+file = true is set in [logging]; otherwise use journalctl --user -u hyprvoice.
+Transcripts and LLM output are redacted unless level = "debug".`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := logging.Path(bus.Instance())
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return logging.Tail(ctx, path, os.Stdout, lines, follow)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "keep printing new log lines")
+	cmd.Flags().IntVarP(&lines, "lines", "n", 50, "number of lines to show (0 for all)")
+
+	return cmd
 }
 
 func versionCmd() *cobra.Command {
-	return &cobra.Command{
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Get protocol version",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if jsonOutput {
+				return printJSONResponse(bus.Request{Cmd: bus.CmdVersion})
+			}
 			resp, err := bus.SendCommand('v')
 			if err != nil {
 				return fmt.Errorf("failed to get version: %w", err)
@@ -100,11 +297,193 @@ func versionCmd() *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "print protocol and daemon build version as JSON")
+
+	return cmd
+}
+
+func doctorCmd() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the environment and print fixes",
+		Long: `Diagnose the environment and print fixes.
+
+Checks the config file, daemon socket and PID file, the recording backend,
+every configured injection backend, whisper-cli, ffmpeg, installed whisper
+models and API keys for each provider. Exits with an error when a required check fails.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// checks load the config and probe the daemon, keep their logs out of the report
+			prev := log.Writer()
+			log.SetOutput(io.Discard)
+			report := doctor.Run(cmd.Context())
+			log.SetOutput(prev)
+
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(report); err != nil {
+					return err
+				}
+			} else {
+				report.Print(os.Stdout)
+			}
+
+			if failed := report.Failed(); failed > 0 {
+				return fmt.Errorf("%d check(s) failed", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "print the report as JSON")
+
+	return cmd
+}
+
+// printJSONResponse sends req over the JSON protocol and prints the raw response line
+func printJSONResponse(req bus.Request) error {
+	resp, err := bus.Call(req)
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", req.Cmd, err)
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	fmt.Println(string(data))
+	if !resp.OK {
+		return fmt.Errorf("daemon error: %s", resp.Error)
+	}
+	return nil
+}
+
+func startCmd() *cobra.Command {
+	var overrides overrideFlags
+
+	cmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start recording (push-to-talk press)",
+		Long: `Start recording. Does nothing if a dictation is already running.
+
+Pair with 'hyprvoice stop' for push-to-talk in Hyprland:
+
+  bind = SUPER, R, exec, hyprvoice start
+  bindr = SUPER, R, exec, hyprvoice stop
+
+The override flags only apply to the dictation started by this command.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommand(bus.Request{Cmd: bus.CmdStart, Args: overrides.args()})
+		},
+	}
+
+	overrides.register(cmd)
+
+	return cmd
 }
 
 func stopCmd() *cobra.Command {
-	return &cobra.Command{
+	var wait waitFlags
+
+	cmd := &cobra.Command{
 		Use:   "stop",
+		Short: "Stop recording and inject text (push-to-talk release)",
+		Long: `Stop recording, finalize the transcription and inject it.
+Unlike toggle, stop never aborts the current dictation. Use 'hyprvoice quit'
+to stop the daemon.
+
+With --wait the command returns once the text is injected, prints it and
+exits non-zero if transcription, LLM post-processing or injection failed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if wait.enabled() {
+				return exitStatus(wait.run(bus.Request{Cmd: bus.CmdStop}))
+			}
+			return runCommand(bus.Request{Cmd: bus.CmdStop})
+		},
+	}
+
+	wait.register(cmd)
+
+	return cmd
+}
+
+// waitFlags are the --wait flags shared by toggle and stop
+type waitFlags struct {
+	wait       bool
+	jsonOutput bool
+}
+
+func (f *waitFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.wait, "wait", false, "wait for the dictation to end and print its text")
+	cmd.Flags().BoolVar(&f.jsonOutput, "json", false, "print the raw and processed text and errors as JSON (implies --wait)")
+}
+
+func (f *waitFlags) enabled() bool {
+	return f.wait || f.jsonOutput
+}
+
+// run sends req asking the daemon to reply once the dictation ends, prints
+// the injected text or the JSON result, and fails unless it ended cleanly
+func (f *waitFlags) run(req bus.Request) error {
+	if req.Args == nil {
+		req.Args = map[string]string{}
+	}
+	req.Args[bus.ArgWait] = "true"
+
+	resp, err := bus.Call(req)
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", req.Cmd, err)
+	}
+	if dictation := resp.Dictation; dictation != nil {
+		if f.jsonOutput {
+			data, err := json.Marshal(dictation)
+			if err != nil {
+				return fmt.Errorf("failed to encode result: %w", err)
+			}
+			fmt.Println(string(data))
+		} else if dictation.Injected {
+			fmt.Println(dictation.Text)
+		}
+	}
+	if !resp.OK {
+		return fmt.Errorf("daemon error: %s", resp.Error)
+	}
+	return nil
+}
+
+func lastCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "last",
+		Short: "Re-inject, copy or undo the most recent dictation",
+	}
+
+	actions := []struct {
+		name  string
+		short string
+	}{
+		{"reinject", "Inject the last dictation again (e.g. after focusing the right window)"},
+		{"copy", "Copy the last dictation to the clipboard"},
+		{"undo", "Erase the last injected text, or restore the clipboard if it was copied"},
+	}
+	for _, a := range actions {
+		action := a.name
+		cmd.AddCommand(&cobra.Command{
+			Use:   action,
+			Short: a.short,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runCommand(bus.Request{Cmd: bus.CmdLast, Args: map[string]string{"action": action}})
+			},
+		})
+	}
+
+	return cmd
+}
+
+func quitCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "quit",
 		Short: "Stop the daemon",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			resp, err := bus.SendCommand('q')
@@ -117,11 +496,41 @@ func stopCmd() *cobra.Command {
 	}
 }
 
+// runCommand sends req over the JSON protocol and prints the result like legacy commands
+func runCommand(req bus.Request) error {
+	resp, err := bus.Call(req)
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", req.Cmd, err)
+	}
+	if !resp.OK {
+		return fmt.Errorf("daemon error: %s", resp.Error)
+	}
+	fmt.Printf("OK %s\n", resp.Result)
+	return nil
+}
+
 func cancelCmd() *cobra.Command {
-	return &cobra.Command{
+	var all bool
+	var sessionID int
+
+	cmd := &cobra.Command{
 		Use:   "cancel",
 		Short: "Cancel current operation",
+		Long: `Cancel the current dictation.
+
+A new dictation can start while earlier ones are still transcribing or
+post-processing. Use --session with an ID from 'hyprvoice status --json' to
+cancel one of those, or --all to cancel every running dictation.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case all && sessionID != 0:
+				return fmt.Errorf("--all and --session cannot be combined")
+			case all:
+				return runCommand(bus.Request{Cmd: bus.CmdCancel, Args: map[string]string{"session": "all"}})
+			case sessionID != 0:
+				return runCommand(bus.Request{Cmd: bus.CmdCancel, Args: map[string]string{"session": strconv.Itoa(sessionID)}})
+			}
+
 			resp, err := bus.SendCommand('c')
 			if err != nil {
 				return fmt.Errorf("failed to cancel operation: %w", err)
@@ -130,6 +539,11 @@ func cancelCmd() *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "cancel every running dictation")
+	cmd.Flags().IntVar(&sessionID, "session", 0, "cancel the dictation with this session ID")
+
+	return cmd
 }
 
 func configureCmd() *cobra.Command {
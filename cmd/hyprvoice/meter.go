@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/leonardotrapani/hyprvoice/internal/bus"
+	"github.com/leonardotrapani/hyprvoice/internal/notify"
+	"github.com/leonardotrapani/hyprvoice/internal/recording"
+	"github.com/spf13/cobra"
+)
+
+func meterCmd() *cobra.Command {
+	var width int
+
+	cmd := &cobra.Command{
+		Use:   "meter",
+		Short: "Show the live microphone level while recording",
+		Long: `Subscribe to the daemon and draw the input level of the current recording
+as a bar, to check the microphone. Start a dictation with hyprvoice toggle to
+see it move. A warning is printed when the microphone records nothing but
+digital silence, which usually means it is muted or recording.device is wrong.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			// on a terminal the bar is redrawn in place
+			terminal := isTerminal(os.Stdout)
+			show := func(line string) {
+				if terminal {
+					fmt.Printf("\r\033[K%s", line)
+				} else {
+					fmt.Println(line)
+				}
+			}
+			warn := func(line string) {
+				if terminal {
+					fmt.Print("\r\033[K")
+				}
+				fmt.Println(line)
+			}
+
+			err := bus.Subscribe(ctx, func(ev bus.Event) error {
+				switch ev.Type {
+				case bus.EventStatus:
+					if ev.Status == "recording" || ev.Status == "transcribing" {
+						show(meterLine(0, 0, width))
+					} else {
+						show(fmt.Sprintf("%s, start a dictation to see the input level", ev.Status))
+					}
+				case bus.EventLevel:
+					show(meterLine(ev.Level, ev.Peak, width))
+				case bus.EventNotification:
+					if ev.Notification == notify.MsgMicSilent.String() {
+						warn(fmt.Sprintf("Warning: no sound from the microphone for %v, check that it is not muted and recording.device", recording.SilentMicAfter))
+					}
+				}
+				return nil
+			})
+			if terminal {
+				fmt.Println()
+			}
+			if err != nil {
+				return fmt.Errorf("failed to watch daemon: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&width, "width", 40, "width of the level bar in characters")
+
+	return cmd
+}
+
+// meterLine renders the RMS level as a bar with the peak marked in it
+func meterLine(level, peak float64, width int) string {
+	width = max(width, 1)
+	filled := recording.LevelPercent(level) * width / 100
+	peakAt := min(width-1, recording.LevelPercent(peak)*width/100)
+
+	bar := []rune(strings.Repeat("█", filled) + strings.Repeat("·", width-filled))
+	if recording.LevelPercent(peak) > 0 && peakAt >= filled {
+		bar[peakAt] = '|'
+	}
+	return fmt.Sprintf("[%s] %4.0f dBFS  peak %4.0f dBFS", string(bar), recording.DBFS(level), recording.DBFS(peak))
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leonardotrapani/hyprvoice/internal/bus"
+	"github.com/leonardotrapani/hyprvoice/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// overrideFlags are the per-dictation overrides accepted by toggle and start
+type overrideFlags struct {
+	profile  string
+	language string
+	model    string
+	noLLM    bool
+	backend  string
+}
+
+func (f *overrideFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.profile, "profile", "", "config profile for this dictation (see hyprvoice profile list)")
+	cmd.Flags().StringVar(&f.language, "language", "", `transcription language for this dictation ("auto" to detect)`)
+	cmd.Flags().StringVar(&f.model, "model", "", "transcription model for this dictation (switches provider if needed)")
+	cmd.Flags().BoolVar(&f.noLLM, "no-llm", false, "skip LLM post-processing for this dictation")
+	cmd.Flags().StringVar(&f.backend, "backend", "", "injection backends for this dictation (e.g. clipboard or wtype,clipboard)")
+}
+
+// args returns the overrides as request args, nil when no flag was given
+func (f *overrideFlags) args() map[string]string {
+	args := map[string]string{}
+	if f.profile != "" {
+		args[config.OverrideProfile] = f.profile
+	}
+	if f.language != "" {
+		args[config.OverrideLanguage] = f.language
+	}
+	if f.model != "" {
+		args[config.OverrideModel] = f.model
+	}
+	if f.noLLM {
+		args[config.OverrideLLM] = "off"
+	}
+	if f.backend != "" {
+		args[config.OverrideBackend] = f.backend
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return args
+}
+
+func setCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set [key value]",
+		Short: "Set a runtime override until the daemon restarts",
+		Long: `Set a runtime override that applies to every dictation until the daemon
+restarts, without touching config.toml. Without arguments, list the current
+overrides. Flags passed to toggle or start win over these.
+
+Keys:
+  profile   a [profiles.<name>] table, applied before the other keys
+  language  transcription language code, "auto" to detect
+  model     transcription model (switches provider if needed)
+  llm       "on" or "off"
+  backend   injection backends, e.g. clipboard or wtype,clipboard
+
+Examples:
+  hyprvoice set language fr
+  hyprvoice set llm off
+  hyprvoice unset language`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 && len(args) != 2 {
+				return fmt.Errorf("expected a key and a value, e.g. hyprvoice set language fr")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := bus.Request{Cmd: bus.CmdSet}
+			if len(args) == 2 {
+				if strings.TrimSpace(args[1]) == "" {
+					return fmt.Errorf("empty value, use hyprvoice unset %s to clear it", args[0])
+				}
+				req.Args = map[string]string{args[0]: args[1]}
+			}
+			return runSet(req)
+		},
+	}
+}
+
+func unsetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unset [key...]",
+		Short: "Clear runtime overrides (all of them without arguments)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keys := args
+			if len(keys) == 0 {
+				keys = config.OverrideKeys
+			}
+			req := bus.Request{Cmd: bus.CmdSet, Args: map[string]string{}}
+			for _, key := range keys {
+				req.Args[key] = ""
+			}
+			return runSet(req)
+		},
+	}
+}
+
+// runSet sends a set request and prints the resulting overrides
+func runSet(req bus.Request) error {
+	resp, err := bus.Call(req)
+	if err != nil {
+		return fmt.Errorf("failed to set overrides: %w", err)
+	}
+	if !resp.OK {
+		return fmt.Errorf("daemon error: %s", resp.Error)
+	}
+
+	if len(resp.Overrides) == 0 {
+		fmt.Println("No runtime overrides")
+		return nil
+	}
+	for _, key := range config.OverrideKeys {
+		if value, ok := resp.Overrides[key]; ok {
+			fmt.Printf("%s = %s\n", key, value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/leonardotrapani/hyprvoice/internal/bus"
+	"github.com/leonardotrapani/hyprvoice/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func profileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "List and select config profiles",
+		Long: `List and select the [profiles.<name>] tables in config.toml.
+
+A profile overrides any part of the config for the dictations it applies to.
+'hyprvoice profile use' selects one until the daemon restarts; the --profile
+flag of toggle and start selects one for a single dictation.`,
+	}
+
+	cmd.AddCommand(profileListCmd())
+	cmd.AddCommand(profileUseCmd())
+	cmd.AddCommand(profileClearCmd())
+
+	return cmd
+}
+
+func profileListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List configured profiles (* marks the active one)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfigQuiet()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			names := cfg.ProfileNames()
+			if len(names) == 0 {
+				fmt.Println("No profiles configured, add [profiles.<name>] tables to config.toml")
+				return nil
+			}
+
+			// the daemon may not be running, in which case nothing is active
+			active := ""
+			if resp, err := bus.Call(bus.Request{Cmd: bus.CmdStatus}); err == nil && resp.OK && resp.Status != nil {
+				active = resp.Status.Overrides[config.OverrideProfile]
+			}
+			for _, name := range names {
+				marker := " "
+				if name == active {
+					marker = "*"
+				}
+				fmt.Printf("%s %s\n", marker, name)
+			}
+			return nil
+		},
+	}
+}
+
+func profileUseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <name>",
+		Short: "Use a profile for every dictation until the daemon restarts",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSet(bus.Request{Cmd: bus.CmdSet, Args: map[string]string{config.OverrideProfile: args[0]}})
+		},
+	}
+}
+
+func profileClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Stop using the active profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSet(bus.Request{Cmd: bus.CmdSet, Args: map[string]string{config.OverrideProfile: ""}})
+		},
+	}
+}
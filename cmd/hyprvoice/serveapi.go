@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/leonardotrapani/hyprvoice/internal/apiserver"
+	"github.com/leonardotrapani/hyprvoice/internal/config"
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+func serveAPICmd() *cobra.Command {
+	var listen string
+	var apiKey string
+	var profile string
+	var origins []string
+
+	cmd := &cobra.Command{
+		Use:   "serve-api",
+		Short: "Serve an OpenAI-compatible transcription API",
+		Long: `Serve an OpenAI-compatible HTTP API backed by the configured transcription
+and LLM providers, so editors, note apps and scripts can reuse hyprvoice's
+provider configuration, keywords and API keys. No daemon is required.
+
+Endpoints:
+  POST /v1/audio/transcriptions  multipart "file" (any format ffmpeg reads),
+                                 optional "language" and "response_format"
+                                 (json, text or verbose_json)
+  POST /v1/chat/completions      runs the last user message through the
+                                 [llm] post-processing
+  GET  /v1/models                lists the configured models
+
+The "model" field of requests is accepted but ignored: the configured
+models answer. config.toml changes apply to the next request. Requests
+from web pages are refused unless their origin is passed to --allow-origin.
+
+Examples:
+  hyprvoice serve-api
+  hyprvoice serve-api --listen 127.0.0.1:9000 --profile meeting
+  curl -F file=@memo.m4a http://127.0.0.1:8765/v1/audio/transcriptions`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configMgr, err := config.NewManager()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			if profile != "" {
+				if _, err := configMgr.GetConfig().WithProfile(profile); err != nil {
+					return err
+				}
+			}
+
+			// only the level applies; the log file belongs to the daemon
+			logCfg := configMgr.GetConfig().ToLoggingConfig()
+			logCfg.File = false
+			if err := logging.Setup(logCfg); err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+			defer stop()
+
+			if err := configMgr.StartWatching(ctx); err != nil {
+				slog.Warn("Failed to start config file watching", "err", err)
+			}
+			defer configMgr.Stop()
+
+			getConfig := func() *config.Config {
+				cfg := configMgr.GetConfig()
+				if profile == "" {
+					return cfg
+				}
+				profiled, err := cfg.WithProfile(profile)
+				if err != nil {
+					slog.Warn("Profile no longer applies, using the base config", "profile", profile, "err", err)
+					return cfg
+				}
+				return profiled
+			}
+
+			server := apiserver.New(getConfig, apiserver.WithAPIKey(apiKey), apiserver.WithAllowedOrigins(origins...))
+			return server.ListenAndServe(ctx, listen)
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", apiserver.DefaultListen, "address to listen on")
+	cmd.Flags().StringVar(&apiKey, "api-key", os.Getenv("HYPRVOICE_API_KEY"),
+		"require this bearer token from clients (default $HYPRVOICE_API_KEY)")
+	cmd.Flags().StringVar(&profile, "profile", "", "config profile to serve with (see hyprvoice profile list)")
+	cmd.Flags().StringSliceVar(&origins, "allow-origin", nil, "let browser pages or apps from this origin call the API (repeatable)")
+
+	return cmd
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/config"
+	"github.com/leonardotrapani/hyprvoice/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+func statsCmd() *cobra.Command {
+	var days int
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show per-stage latency and usage statistics",
+		Long: `Show per-stage latency and usage statistics.
+
+Every dictation records how long each stage took (recorder start, audio,
+transcriber stop/finalize, LLM processing, injection), how many words were
+produced and which provider/model was used. No text is stored. This command
+shows p50/p95 latency per transcription and LLM model, words per day and how
+the last 7 days compare with the 7 before. Disable recording in [stats].`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if days < 1 {
+				return fmt.Errorf("--days must be at least 1")
+			}
+
+			var cfg stats.Config
+			if conf, err := config.Load(); err == nil {
+				cfg.MaxEntries, cfg.MaxAge = conf.Stats.MaxEntries, conf.Stats.MaxAge
+			}
+			store, err := stats.NewStore(cfg)
+			if err != nil {
+				return fmt.Errorf("failed to open stats: %w", err)
+			}
+			records, err := store.List()
+			if err != nil {
+				return err
+			}
+
+			summary := stats.Summarize(records, time.Now(), days)
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(summary)
+			}
+			printStats(os.Stdout, summary, days)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&days, "days", "d", 30, "number of days to include")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "print the summary as JSON")
+
+	return cmd
+}
+
+func printStats(w io.Writer, s stats.Summary, days int) {
+	if s.Count == 0 {
+		fmt.Fprintf(w, "No dictations in the last %d days\n", days)
+		return
+	}
+
+	fmt.Fprintf(w, "Last %d days: %d dictations", days, s.Count)
+	if s.Failed > 0 {
+		fmt.Fprintf(w, " (%d failed)", s.Failed)
+	}
+	audio := (time.Duration(s.AudioMs) * time.Millisecond).Round(time.Second)
+	fmt.Fprintf(w, ", %d words, %s of audio\n", s.Words, audio)
+
+	fmt.Fprintln(w, "\nTranscription latency (p50 / p95)")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  MODEL\tCOUNT\tWORDS\tRECORDER START\tSTOP/FINALIZE\tINJECTION\tTOTAL")
+	for _, m := range s.Models {
+		fmt.Fprintf(tw, "  %s/%s\t%d\t%d\t%s\t%s\t%s\t%s\n",
+			m.Provider, m.Model, m.Count, m.Words,
+			formatLatency(m.RecorderStart), formatLatency(m.Transcription),
+			formatLatency(m.Injection), formatLatency(m.Total))
+	}
+	tw.Flush()
+
+	if len(s.LLMs) > 0 {
+		fmt.Fprintln(w, "\nLLM latency (p50 / p95)")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  MODEL\tCOUNT\tPROCESS")
+		for _, m := range s.LLMs {
+			fmt.Fprintf(tw, "  %s/%s\t%d\t%s\n", m.Provider, m.Model, m.Count, formatLatency(m.LLM))
+		}
+		tw.Flush()
+	}
+
+	fmt.Fprintln(w, "\nWords per day")
+	maxWords := 0
+	for _, d := range s.Days {
+		if d.Words > maxWords {
+			maxWords = d.Words
+		}
+	}
+	for _, d := range s.Days {
+		bar := ""
+		if maxWords > 0 {
+			bar = strings.Repeat("#", d.Words*30/maxWords)
+		}
+		fmt.Fprintf(w, "  %s  %5d  %s\n", d.Date, d.Words, bar)
+	}
+
+	cur, prev := s.Trend.Current, s.Trend.Previous
+	fmt.Fprintln(w, "\nLast 7 days vs previous 7")
+	fmt.Fprintf(w, "  dictations   %d -> %d%s\n", prev.Count, cur.Count, formatChange(int64(prev.Count), int64(cur.Count)))
+	fmt.Fprintf(w, "  words        %d -> %d%s\n", prev.Words, cur.Words, formatChange(int64(prev.Words), int64(cur.Words)))
+	fmt.Fprintf(w, "  p50 latency  %s -> %s%s\n", formatMs(prev.LatencyMs), formatMs(cur.LatencyMs), formatChange(prev.LatencyMs, cur.LatencyMs))
+}
+
+func formatLatency(l stats.Latency) string {
+	if l.P50 == 0 && l.P95 == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%s / %s", formatMs(l.P50), formatMs(l.P95))
+}
+
+func formatChange(prev, cur int64) string {
+	if prev == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%+d%%)", (cur-prev)*100/prev)
+}
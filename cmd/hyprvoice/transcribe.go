@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/leonardotrapani/hyprvoice/internal/audiofile"
+	"github.com/leonardotrapani/hyprvoice/internal/config"
+	"github.com/leonardotrapani/hyprvoice/internal/transcript"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+func transcribeCmd() *cobra.Command {
+	var format string
+	var output string
+	var providerName string
+	var overrides overrideFlags
+	var verbose bool
+
+	cmd := &cobra.Command{
+		Use:   "transcribe <file>",
+		Short: "Transcribe an audio or video file",
+		Long: `Transcribe an audio or video file with the configured transcription
+provider, keywords and optional LLM cleanup. Any file ffmpeg can read works;
+16 kHz mono 16-bit WAV files do not need ffmpeg. No daemon is required.
+
+Long recordings are split at quiet points and transcribed piece by piece.
+The format defaults to the extension of --output (.json, .srt, .vtt) and to
+text otherwise.
+
+Examples:
+  hyprvoice transcribe memo.m4a
+  hyprvoice transcribe meeting.mp4 -o meeting.srt
+  hyprvoice transcribe call.ogg --format json --language de --no-llm
+  hyprvoice transcribe lecture.mp3 --provider whisper-cpp --model large-v3-turbo`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format == "" {
+				format = transcript.FormatForPath(output)
+			}
+			if err := transcript.ValidateFormat(format); err != nil {
+				return err
+			}
+
+			if !verbose {
+				log.SetOutput(io.Discard)
+			}
+
+			cfg, err := loadConfigQuiet()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			cfg, err = applyFileOverrides(cfg, providerName, overrides)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+			defer stop()
+
+			pcm, err := audiofile.Decode(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			if len(pcm) == 0 {
+				return fmt.Errorf("%s contains no audio", args[0])
+			}
+
+			segmentLength := transcript.DefaultSegmentLength
+			if transcript.IsSubtitle(format) {
+				segmentLength = transcript.SubtitleSegmentLength
+			}
+			opts := []transcript.Option{transcript.WithSegmentLength(segmentLength)}
+			if isTerminal(os.Stderr) {
+				opts = append(opts, transcript.WithProgress(func(done, total int) {
+					fmt.Fprintf(os.Stderr, "\rTranscribing %s: %d/%d", args[0], done, total)
+					if done == total {
+						fmt.Fprintln(os.Stderr)
+					}
+				}))
+			}
+
+			result, err := transcript.Transcribe(ctx, cfg, pcm, opts...)
+			if err != nil {
+				return err
+			}
+			for _, warning := range result.Warnings {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+			}
+
+			if output == "" || output == "-" {
+				return transcript.Write(os.Stdout, format, result)
+			}
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			if err := transcript.Write(f, format, result); err != nil {
+				f.Close()
+				return fmt.Errorf("failed to write transcript: %w", err)
+			}
+			return f.Close()
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "", "output format: text, json, srt or vtt")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write the transcript to this file instead of stdout")
+	cmd.Flags().StringVar(&providerName, "provider", "", "transcription provider (uses its default model unless --model is given)")
+	cmd.Flags().StringVar(&overrides.profile, "profile", "", "config profile to transcribe with (see hyprvoice profile list)")
+	cmd.Flags().StringVar(&overrides.language, "language", "", `transcription language ("auto" to detect)`)
+	cmd.Flags().StringVar(&overrides.model, "model", "", "transcription model (switches provider if needed)")
+	cmd.Flags().BoolVar(&overrides.noLLM, "no-llm", false, "skip LLM post-processing")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "log provider requests to stderr")
+
+	return cmd
+}
+
+// applyFileOverrides applies the profile, then the provider, then the
+// remaining overrides, so --model and --language win over both
+func applyFileOverrides(cfg *config.Config, providerName string, f overrideFlags) (*config.Config, error) {
+	var err error
+	if f.profile != "" {
+		if cfg, err = cfg.WithOverrides(config.Overrides{config.OverrideProfile: f.profile}); err != nil {
+			return nil, err
+		}
+	}
+	if providerName != "" {
+		if cfg, err = cfg.WithTranscriptionProvider(providerName); err != nil {
+			return nil, err
+		}
+	}
+
+	o := config.Overrides{}
+	for key, value := range f.args() {
+		if key != config.OverrideProfile {
+			o[key] = value
+		}
+	}
+	return cfg.WithOverrides(o)
+}
+
+// isTerminal reports whether f is a terminal
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd())
+}
@@ -0,0 +1,438 @@
+// Package apiserver serves hyprvoice's transcription and LLM cleanup over an
+// OpenAI-compatible HTTP API, so other local tools can reuse its providers,
+// keywords and API keys.
+package apiserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/audiofile"
+	"github.com/leonardotrapani/hyprvoice/internal/config"
+	"github.com/leonardotrapani/hyprvoice/internal/llm"
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
+	"github.com/leonardotrapani/hyprvoice/internal/transcriber"
+)
+
+var logger = logging.For("apiserver")
+
+const (
+	DefaultListen = "127.0.0.1:8765"
+
+	maxUploadBytes = 512 << 20
+	maxChatBytes   = 4 << 20
+	requestTimeout = 10 * time.Minute
+)
+
+// Factory types for dependency injection
+type TranscriberFactory func(cfg transcriber.Config) (transcriber.Transcriber, error)
+type LLMAdapterFactory func(cfg llm.Config) (llm.Adapter, error)
+type DecodeFunc func(ctx context.Context, path string) ([]byte, error)
+
+// Option configures the server
+type Option func(*Server)
+
+// WithTranscriberFactory sets a custom transcriber factory
+func WithTranscriberFactory(f TranscriberFactory) Option {
+	return func(s *Server) {
+		s.transcriberFactory = f
+	}
+}
+
+// WithLLMAdapterFactory sets a custom LLM adapter factory
+func WithLLMAdapterFactory(f LLMAdapterFactory) Option {
+	return func(s *Server) {
+		s.llmAdapterFactory = f
+	}
+}
+
+// WithDecoder sets a custom audio file decoder
+func WithDecoder(f DecodeFunc) Option {
+	return func(s *Server) {
+		s.decode = f
+	}
+}
+
+// WithAPIKey requires clients to send "Authorization: Bearer <key>"
+func WithAPIKey(key string) Option {
+	return func(s *Server) {
+		s.apiKey = key
+	}
+}
+
+// WithAllowedOrigins lets browser pages and apps from these origins (for
+// example "app://obsidian.md") call the API. Requests carrying any other
+// Origin header are rejected.
+func WithAllowedOrigins(origins ...string) Option {
+	return func(s *Server) {
+		s.origins = origins
+	}
+}
+
+// Server answers /v1/audio/transcriptions, /v1/chat/completions and
+// /v1/models with the config returned by getConfig at request time, so a
+// reloaded config applies to the next request.
+type Server struct {
+	getConfig func() *config.Config
+	apiKey    string
+	origins   []string
+	hosts     []string // accepted Host headers, any when empty
+	requests  atomic.Int64
+
+	// dependency factories (for testing)
+	transcriberFactory TranscriberFactory
+	llmAdapterFactory  LLMAdapterFactory
+	decode             DecodeFunc
+}
+
+func New(getConfig func() *config.Config, opts ...Option) *Server {
+	s := &Server{
+		getConfig:          getConfig,
+		transcriberFactory: transcriber.NewTranscriber,
+		llmAdapterFactory:  llm.NewAdapter,
+		decode:             audiofile.Decode,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Handler returns the HTTP handler for the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/audio/transcriptions", s.handleTranscription)
+	mux.HandleFunc("POST /v1/chat/completions", s.handleChat)
+	mux.HandleFunc("GET /v1/models", s.handleModels)
+	return s.authorize(mux)
+}
+
+// ListenAndServe serves the API on addr until ctx is done
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	if s.apiKey == "" && !isLoopback(ln.Addr()) {
+		logger.Warn("Listening without an API key, anyone who can reach it can use your providers", "addr", ln.Addr())
+	}
+	s.hosts = hostsFor(ln.Addr())
+
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("Listening", "url", fmt.Sprintf("http://%s/v1", ln.Addr()))
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("API server failed: %w", err)
+	}
+	return nil
+}
+
+func isLoopback(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
+
+// hostsFor returns the Host headers that name the listen address addr. A
+// page on another site can resolve its own name to 127.0.0.1, so requests
+// naming any other host are refused. On a wildcard address the names are
+// not known and every host is accepted.
+func hostsFor(addr net.Addr) []string {
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok || tcp.IP.IsUnspecified() {
+		return nil
+	}
+	hosts := []string{tcp.String()}
+	if tcp.IP.IsLoopback() {
+		hosts = append(hosts, net.JoinHostPort("localhost", strconv.Itoa(tcp.Port)))
+	}
+	return hosts
+}
+
+// authorize rejects requests for another host, requests sent by web pages
+// from origins that were not allowed and, with an API key, requests
+// without it
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(s.hosts) > 0 && !slices.Contains(s.hosts, r.Host) {
+			writeError(w, http.StatusMisdirectedRequest, "invalid_request_error", fmt.Sprintf("unexpected Host header %q", r.Host))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if !slices.Contains(s.origins, origin) {
+				writeError(w, http.StatusForbidden, "invalid_request_error", fmt.Sprintf("origin %s is not allowed", origin))
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+			if r.Method == http.MethodOptions {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+				w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		if s.apiKey != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.apiKey)) != 1 {
+				writeError(w, http.StatusUnauthorized, "invalid_api_key", "missing or invalid API key")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleTranscription(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	defer cancel()
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBytes)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("missing audio file: %v", err))
+		return
+	}
+	defer file.Close()
+
+	format := r.FormValue("response_format")
+	switch format {
+	case "", "json", "text", "verbose_json":
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("unsupported response_format: %s (must be json, text or verbose_json)", format))
+		return
+	}
+
+	cfg := s.getConfig()
+	if language := r.FormValue("language"); language != "" {
+		cfg, err = cfg.WithOverrides(config.Overrides{config.OverrideLanguage: language})
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+			return
+		}
+	}
+
+	pcm, err := s.decodeUpload(ctx, file)
+	if errors.Is(err, audiofile.ErrFFmpegMissing) {
+		writeError(w, http.StatusUnsupportedMediaType, "invalid_request_error", err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	tcfg := cfg.ToTranscriberConfig()
+	t, err := s.transcriberFactory(tcfg)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", fmt.Sprintf("failed to create transcriber: %v", err))
+		return
+	}
+
+	start := time.Now()
+	text, err := transcriber.TranscribeAudio(ctx, t, pcm)
+	if err != nil {
+		logger.Error("Transcription failed", "err", err)
+		writeError(w, http.StatusBadGateway, "upstream_error", fmt.Sprintf("transcription failed: %v", err))
+		return
+	}
+	logger.Info("Transcribed audio", "audio", audiofile.Duration(pcm).Round(time.Millisecond), "provider", tcfg.Provider, "model", tcfg.Model, "duration", time.Since(start).Round(time.Millisecond))
+
+	switch format {
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, text)
+	case "verbose_json":
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"task":     "transcribe",
+			"language": tcfg.Language,
+			"duration": audiofile.Duration(pcm).Seconds(),
+			"text":     text,
+		})
+	default:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"text": text})
+	}
+}
+
+// decodeUpload stores the upload in a temporary file so ffmpeg can seek in
+// containers such as mp4, then decodes it
+func (s *Server) decodeUpload(ctx context.Context, file io.Reader) ([]byte, error) {
+	tmp, err := os.CreateTemp("", "hyprvoice-upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to store upload: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := io.Copy(tmp, file); err != nil {
+		return nil, fmt.Errorf("failed to store upload: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to store upload: %w", err)
+	}
+
+	pcm, err := s.decode(ctx, tmp.Name())
+	if err != nil {
+		return nil, err
+	}
+	if len(pcm) == 0 {
+		return nil, fmt.Errorf("audio file contains no audio")
+	}
+	return pcm, nil
+}
+
+// chatRequest is the subset of an OpenAI chat completion request the
+// cleanup endpoint reads
+type chatRequest struct {
+	Model    string `json:"model"`
+	Stream   bool   `json:"stream"`
+	Messages []struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	} `json:"messages"`
+}
+
+// handleChat runs the last user message through the configured LLM
+// post-processing. Other messages are ignored: the system prompt is built
+// from the [llm] config and keywords like it is for dictations.
+func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	defer cancel()
+
+	// a JSON body cannot be sent by a plain HTML form
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "invalid_request_error", "Content-Type must be application/json")
+		return
+	}
+
+	var req chatRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxChatBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if req.Stream {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "streaming is not supported")
+		return
+	}
+
+	var text string
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			text = messageText(req.Messages[i].Content)
+			break
+		}
+	}
+	if strings.TrimSpace(text) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "no user message with text content")
+		return
+	}
+
+	cfg := s.getConfig()
+	if cfg.LLM.Provider == "" || cfg.LLM.Model == "" {
+		writeError(w, http.StatusServiceUnavailable, "server_error", "LLM post-processing is not configured (set llm.provider and llm.model)")
+		return
+	}
+	adapter, err := s.llmAdapterFactory(llm.Config(cfg.ToLLMConfig()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", fmt.Sprintf("failed to create LLM adapter: %v", err))
+		return
+	}
+
+	processed, err := adapter.Process(ctx, text)
+	if err != nil {
+		logger.Error("LLM processing failed", "err", err)
+		writeError(w, http.StatusBadGateway, "upstream_error", fmt.Sprintf("LLM processing failed: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":      fmt.Sprintf("chatcmpl-hyprvoice-%d", s.requests.Add(1)),
+		"object":  "chat.completion",
+		"created": time.Now().Unix(),
+		"model":   cfg.LLM.Model,
+		"choices": []map[string]interface{}{{
+			"index":         0,
+			"message":       map[string]string{"role": "assistant", "content": processed},
+			"finish_reason": "stop",
+		}},
+	})
+}
+
+// messageText returns the text of a message content, which is either a
+// string or a list of content parts
+func messageText(content json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return text
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(content, &parts); err != nil {
+		return ""
+	}
+	var texts []string
+	for _, part := range parts {
+		if part.Type == "text" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// handleModels lists the configured models. Clients may send any model
+// name; requests are always answered by the configured one.
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	cfg := s.getConfig()
+	models := []map[string]interface{}{}
+	add := func(id, owner string) {
+		if id != "" {
+			models = append(models, map[string]interface{}{"id": id, "object": "model", "owned_by": owner})
+		}
+	}
+	add(cfg.Transcription.Model, cfg.Transcription.Provider)
+	if cfg.LLM.Provider != "" {
+		add(cfg.LLM.Model, cfg.LLM.Provider)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"object": "list", "data": models})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Warn("Failed to write response", "err", err)
+	}
+}
+
+// writeError writes an error in the OpenAI error format
+func writeError(w http.ResponseWriter, status int, errType, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{"message": message, "type": errType},
+	})
+}
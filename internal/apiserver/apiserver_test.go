@@ -0,0 +1,315 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/leonardotrapani/hyprvoice/internal/audiofile"
+	"github.com/leonardotrapani/hyprvoice/internal/config"
+	"github.com/leonardotrapani/hyprvoice/internal/llm"
+	"github.com/leonardotrapani/hyprvoice/internal/testutil"
+	"github.com/leonardotrapani/hyprvoice/internal/transcriber"
+)
+
+// upload builds a multipart transcription request body
+func upload(t *testing.T, fields map[string]string) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "memo.m4a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write([]byte("fake audio"))
+	for key, value := range fields {
+		mw.WriteField(key, value)
+	}
+	mw.Close()
+	return &body, mw.FormDataContentType()
+}
+
+func newTestServer(t *testing.T, cfg *config.Config, opts ...Option) (*httptest.Server, *transcriber.Config) {
+	t.Helper()
+	var got transcriber.Config
+	opts = append([]Option{
+		WithDecoder(func(ctx context.Context, path string) ([]byte, error) {
+			return make([]byte, audiofile.BytesPerSecond), nil
+		}),
+		WithTranscriberFactory(func(tcfg transcriber.Config) (transcriber.Transcriber, error) {
+			got = tcfg
+			return testutil.NewMockTranscriber("hello world"), nil
+		}),
+	}, opts...)
+	srv := httptest.NewServer(New(func() *config.Config { return cfg }, opts...).Handler())
+	t.Cleanup(srv.Close)
+	return srv, &got
+}
+
+func TestServer_Transcriptions(t *testing.T) {
+	cfg := testutil.TestConfig()
+	cfg.Keywords = []string{"Hyprland"}
+
+	tests := []struct {
+		name       string
+		fields     map[string]string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "json", fields: map[string]string{"model": "whisper-1"}, wantStatus: http.StatusOK, wantBody: `{"text":"hello world"}`},
+		{name: "text", fields: map[string]string{"response_format": "text"}, wantStatus: http.StatusOK, wantBody: "hello world"},
+		{name: "verbose json", fields: map[string]string{"response_format": "verbose_json", "language": "de"}, wantStatus: http.StatusOK, wantBody: `{"duration":1,"language":"de","task":"transcribe","text":"hello world"}`},
+		{name: "unsupported format", fields: map[string]string{"response_format": "srt"}, wantStatus: http.StatusBadRequest, wantBody: "unsupported response_format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, got := newTestServer(t, cfg)
+			body, contentType := upload(t, tt.fields)
+			resp, err := http.Post(srv.URL+"/v1/audio/transcriptions", contentType, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			data, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", resp.StatusCode, tt.wantStatus, data)
+			}
+			if !strings.Contains(string(data), tt.wantBody) {
+				t.Errorf("body = %s, want %s", data, tt.wantBody)
+			}
+			if tt.wantStatus == http.StatusOK {
+				if got.Provider != "openai" || got.APIKey != "test-api-key" || len(got.Keywords) != 1 {
+					t.Errorf("transcriber config = %+v, want the configured provider, key and keywords", *got)
+				}
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		srv, _ := newTestServer(t, cfg)
+		resp, err := http.Post(srv.URL+"/v1/audio/transcriptions", "application/json", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("transcription error", func(t *testing.T) {
+		srv, _ := newTestServer(t, cfg, WithTranscriberFactory(func(tcfg transcriber.Config) (transcriber.Transcriber, error) {
+			mock := testutil.NewMockTranscriber("")
+			mock.StopError = fmt.Errorf("quota exceeded")
+			return mock, nil
+		}))
+		body, contentType := upload(t, nil)
+		resp, err := http.Post(srv.URL+"/v1/audio/transcriptions", contentType, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if resp.StatusCode != http.StatusBadGateway || !strings.Contains(apiErr.Error.Message, "quota exceeded") {
+			t.Errorf("got %d %q, want 502 with the provider error", resp.StatusCode, apiErr.Error.Message)
+		}
+	})
+}
+
+func TestServer_ChatCompletions(t *testing.T) {
+	cfg := testutil.TestConfig()
+	cfg.LLM.Provider = "openai"
+	cfg.LLM.Model = "gpt-4o-mini"
+
+	var adapter *testutil.MockLLMAdapter
+	srv, _ := newTestServer(t, cfg, WithLLMAdapterFactory(func(lcfg llm.Config) (llm.Adapter, error) {
+		adapter = testutil.NewMockLLMAdapter("Hello, world.")
+		return adapter, nil
+	}))
+
+	post := func(body string) (*http.Response, []byte) {
+		resp, err := http.Post(srv.URL+"/v1/chat/completions", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp, data
+	}
+
+	t.Run("string content", func(t *testing.T) {
+		resp, data := post(`{"model":"x","messages":[{"role":"system","content":"ignored"},{"role":"user","content":"hello uh world"}]}`)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d (%s)", resp.StatusCode, data)
+		}
+		var completion struct {
+			Object  string `json:"object"`
+			Model   string `json:"model"`
+			Choices []struct {
+				Message struct {
+					Role    string `json:"role"`
+					Content string `json:"content"`
+				} `json:"message"`
+			} `json:"choices"`
+		}
+		if err := json.Unmarshal(data, &completion); err != nil {
+			t.Fatal(err)
+		}
+		if completion.Object != "chat.completion" || completion.Model != "gpt-4o-mini" ||
+			len(completion.Choices) != 1 || completion.Choices[0].Message.Content != "Hello, world." {
+			t.Errorf("completion = %s", data)
+		}
+		if adapter.InputText != "hello uh world" {
+			t.Errorf("LLM input = %q, want the last user message", adapter.InputText)
+		}
+	})
+
+	t.Run("content parts", func(t *testing.T) {
+		resp, data := post(`{"messages":[{"role":"user","content":[{"type":"text","text":"first"},{"type":"text","text":"second"}]}]}`)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d (%s)", resp.StatusCode, data)
+		}
+		if adapter.InputText != "first\nsecond" {
+			t.Errorf("LLM input = %q, want the text parts joined", adapter.InputText)
+		}
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		for _, body := range []string{
+			`not json`,
+			`{"messages":[]}`,
+			`{"stream":true,"messages":[{"role":"user","content":"hi"}]}`,
+		} {
+			if resp, data := post(body); resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("%s: status = %d (%s), want 400", body, resp.StatusCode, data)
+			}
+		}
+	})
+
+	t.Run("llm not configured", func(t *testing.T) {
+		srv, _ := newTestServer(t, testutil.TestConfig())
+		resp, err := http.Post(srv.URL+"/v1/chat/completions", "application/json",
+			strings.NewReader(`{"messages":[{"role":"user","content":"hi"}]}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+		}
+	})
+}
+
+func TestServer_APIKey(t *testing.T) {
+	srv, _ := newTestServer(t, testutil.TestConfig(), WithAPIKey("secret"))
+
+	for _, tt := range []struct {
+		auth string
+		want int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"Bearer secret", http.StatusOK},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/v1/models", nil)
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("Authorization %q: status = %d, want %d", tt.auth, resp.StatusCode, tt.want)
+		}
+		if tt.want == http.StatusOK && !strings.Contains(string(data), `"id":"whisper-1"`) {
+			t.Errorf("models = %s, want the configured transcription model", data)
+		}
+	}
+}
+
+func TestServer_RequestChecks(t *testing.T) {
+	server := New(func() *config.Config { return testutil.TestConfig() }, WithAllowedOrigins("app://obsidian.md"))
+	srv := httptest.NewServer(server.Handler())
+	t.Cleanup(srv.Close)
+	server.hosts = hostsFor(srv.Listener.Addr())
+
+	get := func(host, origin string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/v1/models", nil)
+		if host != "" {
+			req.Host = host
+		}
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	port := srv.Listener.Addr().(*net.TCPAddr).Port
+	tests := []struct {
+		name         string
+		host, origin string
+		want         int
+	}{
+		{"listen address", "", "", http.StatusOK},
+		{"localhost", fmt.Sprintf("localhost:%d", port), "", http.StatusOK},
+		{"rebound name", fmt.Sprintf("evil.example:%d", port), "", http.StatusMisdirectedRequest},
+		{"web page", "", "https://evil.example", http.StatusForbidden},
+		{"allowed origin", "", "app://obsidian.md", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := get(tt.host, tt.origin); resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+
+	t.Run("allowed origin preflight", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodOptions, srv.URL+"/v1/chat/completions", nil)
+		req.Header.Set("Origin", "app://obsidian.md")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent || resp.Header.Get("Access-Control-Allow-Origin") != "app://obsidian.md" {
+			t.Errorf("preflight = %d %v", resp.StatusCode, resp.Header)
+		}
+	})
+
+	t.Run("chat needs JSON", func(t *testing.T) {
+		resp, err := http.Post(srv.URL+"/v1/chat/completions", "text/plain", strings.NewReader(`{"messages":[{"role":"user","content":"hi"}]}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnsupportedMediaType {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusUnsupportedMediaType)
+		}
+	})
+}
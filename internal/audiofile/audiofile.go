@@ -0,0 +1,186 @@
+// Package audiofile decodes audio and video files to the 16 kHz mono s16le
+// PCM the transcribers expect.
+package audiofile
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+const (
+	SampleRate     = 16000
+	BytesPerSecond = SampleRate * 2 // s16 mono
+)
+
+// ErrFFmpegMissing is returned for files that need ffmpeg when it is not installed
+var ErrFFmpegMissing = errors.New("ffmpeg not found (install ffmpeg to transcribe files other than 16 kHz mono 16-bit WAV)")
+
+// Duration returns the length of pcm
+func Duration(pcm []byte) time.Duration {
+	return time.Duration(len(pcm)) * time.Second / BytesPerSecond
+}
+
+// Decode returns the audio of the file at path as 16 kHz mono s16le PCM.
+// WAV files already in that format are read directly; anything else ffmpeg
+// can open, including video, is converted with ffmpeg.
+func Decode(ctx context.Context, path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audio file: %w", err)
+	}
+	defer f.Close()
+
+	if pcm, ok := readPCMWAV(f); ok {
+		return pcm, nil
+	}
+	return decodeFFmpeg(ctx, path)
+}
+
+// decodeFFmpeg converts path with ffmpeg
+func decodeFFmpeg(ctx context.Context, path string) ([]byte, error) {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, ErrFFmpegMissing
+	}
+
+	cmd := exec.CommandContext(ctx, ffmpeg,
+		"-nostdin", "-hide_banner", "-loglevel", "error",
+		"-i", path,
+		"-vn", "-f", "s16le", "-acodec", "pcm_s16le", "-ac", "1", "-ar", fmt.Sprint(SampleRate),
+		"pipe:1")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return nil, fmt.Errorf("ffmpeg failed to decode %s: %s", path, msg)
+		}
+		return nil, fmt.Errorf("ffmpeg failed to decode %s: %w", path, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// readPCMWAV returns the samples of a canonical 16 kHz mono 16-bit PCM WAV
+// file. ok is false for any other file, which is then left to ffmpeg.
+func readPCMWAV(r io.Reader) (pcm []byte, ok bool) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return nil, false
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return nil, false
+	}
+
+	formatOK := false
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return nil, false
+		}
+		id := string(header[0:4])
+		size := int64(binary.LittleEndian.Uint32(header[4:8]))
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, false
+			}
+			var format [16]byte
+			if _, err := io.ReadFull(r, format[:]); err != nil {
+				return nil, false
+			}
+			audioFormat := binary.LittleEndian.Uint16(format[0:2])
+			channels := binary.LittleEndian.Uint16(format[2:4])
+			sampleRate := binary.LittleEndian.Uint32(format[4:8])
+			bitsPerSample := binary.LittleEndian.Uint16(format[14:16])
+			if audioFormat != 1 || channels != 1 || sampleRate != SampleRate || bitsPerSample != 16 {
+				return nil, false
+			}
+			formatOK = true
+			if _, err := io.CopyN(io.Discard, r, size-16+size%2); err != nil {
+				return nil, false
+			}
+		case "data":
+			if !formatOK {
+				return nil, false
+			}
+			data, err := io.ReadAll(io.LimitReader(r, size))
+			if err != nil || int64(len(data)) != size {
+				return nil, false
+			}
+			return data, true
+		default:
+			if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+				return nil, false
+			}
+		}
+	}
+}
+
+// Chunk is a piece of a longer recording
+type Chunk struct {
+	Start time.Duration
+	PCM   []byte
+}
+
+// End returns where the chunk ends in the recording
+func (c Chunk) End() time.Duration {
+	return c.Start + Duration(c.PCM)
+}
+
+// splitWindow is the length of the quiet stretch Split looks for, moved in 10ms steps
+const splitWindow = BytesPerSecond / 10
+
+// Split cuts pcm into chunks no longer than max. Each cut is placed in the
+// quietest 100ms of the last third of the chunk, the latest one on ties, so
+// words are rarely cut in half.
+func Split(pcm []byte, max time.Duration) []Chunk {
+	maxBytes := int(max.Seconds()*BytesPerSecond) &^ 1
+	if maxBytes < 2*splitWindow {
+		maxBytes = 2 * splitWindow
+	}
+
+	var chunks []Chunk
+	pos := 0
+	for len(pcm)-pos > maxBytes {
+		cut := pos + maxBytes
+		quietest := -1.0
+		const step = splitWindow / 10
+		for w := pos + maxBytes*2/3/step*step; w+splitWindow <= pos+maxBytes; w += step {
+			if energy := meanSquare(pcm[w : w+splitWindow]); quietest < 0 || energy <= quietest {
+				quietest = energy
+				cut = w + splitWindow/2
+			}
+		}
+		chunks = append(chunks, Chunk{Start: Duration(pcm[:pos]), PCM: pcm[pos:cut]})
+		pos = cut
+	}
+	if pos < len(pcm) {
+		chunks = append(chunks, Chunk{Start: Duration(pcm[:pos]), PCM: pcm[pos:]})
+	}
+	return chunks
+}
+
+// meanSquare returns the mean squared amplitude of s16le samples
+func meanSquare(pcm []byte) float64 {
+	n := len(pcm) / 2
+	if n == 0 {
+		return 0
+	}
+	var sum float64
+	for i := 0; i < n; i++ {
+		sample := float64(int16(binary.LittleEndian.Uint16(pcm[2*i:])))
+		sum += sample * sample
+	}
+	return sum / float64(n)
+}
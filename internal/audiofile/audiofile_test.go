@@ -0,0 +1,125 @@
+package audiofile
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// wav builds a PCM WAV file with an extra LIST chunk before the data
+func wav(sampleRate uint32, channels uint16, pcm []byte) []byte {
+	var buf bytes.Buffer
+	list := []byte("INFOjunk!") // odd size, padded
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(4+8+16+8+len(list)+1+8+len(pcm)))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))
+	binary.Write(&buf, binary.LittleEndian, uint16(1))
+	binary.Write(&buf, binary.LittleEndian, channels)
+	binary.Write(&buf, binary.LittleEndian, sampleRate)
+	binary.Write(&buf, binary.LittleEndian, sampleRate*uint32(channels)*2)
+	binary.Write(&buf, binary.LittleEndian, channels*2)
+	binary.Write(&buf, binary.LittleEndian, uint16(16))
+	buf.WriteString("LIST")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(list)))
+	buf.Write(list)
+	buf.WriteByte(0)
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(pcm)))
+	buf.Write(pcm)
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	dir := t.TempDir()
+	pcm := []byte{1, 0, 2, 0, 3, 0, 4, 0}
+
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("16 kHz mono wav is read directly", func(t *testing.T) {
+		t.Setenv("PATH", "")
+		got, err := Decode(context.Background(), write("direct.wav", wav(16000, 1, pcm)))
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		if !bytes.Equal(got, pcm) {
+			t.Errorf("Decode() = %v, want %v", got, pcm)
+		}
+	})
+
+	t.Run("other formats need ffmpeg", func(t *testing.T) {
+		t.Setenv("PATH", "")
+		for name, data := range map[string][]byte{
+			"stereo.wav": wav(16000, 2, pcm),
+			"44k.wav":    wav(44100, 1, pcm),
+			"memo.ogg":   []byte("OggS not really"),
+		} {
+			_, err := Decode(context.Background(), write(name, data))
+			if !errors.Is(err, ErrFFmpegMissing) {
+				t.Errorf("Decode(%s) error = %v, want ErrFFmpegMissing", name, err)
+			}
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := Decode(context.Background(), filepath.Join(dir, "nope.wav")); err == nil {
+			t.Errorf("Decode() should fail for a missing file")
+		}
+	})
+}
+
+func TestDuration(t *testing.T) {
+	if got := Duration(make([]byte, BytesPerSecond*3/2)); got != 1500*time.Millisecond {
+		t.Errorf("Duration() = %v, want 1.5s", got)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	// 25s of loud audio with a quiet 100ms at 8.0s and 17.5s
+	pcm := make([]byte, 25*BytesPerSecond)
+	for i := 0; i < len(pcm); i += 2 {
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(int16(10000)))
+	}
+	for _, at := range []time.Duration{8 * time.Second, 17500 * time.Millisecond} {
+		start := int(at.Seconds() * BytesPerSecond)
+		clear(pcm[start : start+BytesPerSecond/10])
+	}
+
+	chunks := Split(pcm, 10*time.Second)
+	if len(chunks) != 3 {
+		t.Fatalf("Split() = %d chunks, want 3", len(chunks))
+	}
+	wantStarts := []time.Duration{0, 8050 * time.Millisecond, 17550 * time.Millisecond}
+	total := 0
+	for i, c := range chunks {
+		if c.Start != wantStarts[i] {
+			t.Errorf("chunk %d starts at %v, want %v", i, c.Start, wantStarts[i])
+		}
+		if Duration(c.PCM) > 10*time.Second {
+			t.Errorf("chunk %d is %v long, want at most 10s", i, Duration(c.PCM))
+		}
+		total += len(c.PCM)
+	}
+	if total != len(pcm) || chunks[2].End() != 25*time.Second {
+		t.Errorf("chunks cover %d of %d bytes", total, len(pcm))
+	}
+
+	if chunks := Split(pcm[:BytesPerSecond], time.Minute); len(chunks) != 1 || len(chunks[0].PCM) != BytesPerSecond {
+		t.Errorf("Split() of a short recording should return it whole")
+	}
+	if chunks := Split(nil, time.Minute); len(chunks) != 0 {
+		t.Errorf("Split(nil) = %d chunks, want none", len(chunks))
+	}
+}
@@ -3,21 +3,47 @@ package bus
 import (
 	"bufio"
 	"fmt"
-	"log"
 	"net"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"syscall"
+
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 )
 
+var logger = logging.For("bus")
+
 const (
 	SockName = "control.sock"
 	PidName  = "hyprvoice.pid"
 	ProtoVer = "0.1"
 )
 
+// instance is the named daemon instance selected with SetInstance, "" for
+// the default one
+var instance string
+
+var instanceNameRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// SetInstance selects the named daemon instance that the socket and PID file
+// functions refer to, so several daemons can run side by side. An empty name
+// selects the default instance.
+func SetInstance(name string) error {
+	if name != "" && !instanceNameRe.MatchString(name) {
+		return fmt.Errorf("invalid instance name %q (use letters, digits, - and _)", name)
+	}
+	instance = name
+	return nil
+}
+
+// Instance returns the selected instance name, "" for the default instance
+func Instance() string {
+	return instance
+}
+
 type pidManager struct {
 	path string
 }
@@ -31,32 +57,29 @@ func newPidManager() (*pidManager, error) {
 }
 
 func (pm *pidManager) checkExisting() error {
-	log.Printf("Checking for existing daemon at: %s", pm.path)
+	logger.Debug("Checking for existing daemon", "pid_file", pm.path)
 
 	pidData, err := os.ReadFile(pm.path)
 	if os.IsNotExist(err) {
-		log.Printf("No PID file found, daemon not running")
+		logger.Debug("No PID file found, daemon not running")
 		return nil
 	}
 	if err != nil {
 		return fmt.Errorf("error reading PID file: %w", err)
 	}
 
-	log.Printf("Found PID file with content: %s", string(pidData))
-
 	pid, err := strconv.Atoi(string(pidData))
 	if err != nil {
-		log.Printf("Invalid PID in file, removing stale PID file: %v", err)
+		logger.Warn("Invalid PID in file, removing stale PID file", "err", err)
 		pm.removeStaleFile()
 		return nil
 	}
 
 	if pm.isProcessAlive(pid) {
-		log.Printf("Process %d is alive, daemon already running", pid)
 		return fmt.Errorf("daemon already running with PID %d", pid)
 	}
 
-	log.Printf("Process %d not alive, removing stale PID file", pid)
+	logger.Info("Removing stale PID file", "pid", pid)
 	pm.removeStaleFile()
 	return nil
 }
@@ -67,7 +90,7 @@ func (pm *pidManager) create() error {
 	}
 
 	pid := os.Getpid()
-	log.Printf("Creating PID file at %s with PID %d", pm.path, pid)
+	logger.Debug("Creating PID file", "path", pm.path, "pid", pid)
 
 	err := os.WriteFile(pm.path, []byte(strconv.Itoa(pid)), 0o600)
 	if err != nil {
@@ -78,7 +101,7 @@ func (pm *pidManager) create() error {
 }
 
 func (pm *pidManager) remove() error {
-	log.Printf("Removing PID file: %s", pm.path)
+	logger.Debug("Removing PID file", "path", pm.path)
 	if err := os.Remove(pm.path); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove PID file: %w", err)
 	}
@@ -86,30 +109,28 @@ func (pm *pidManager) remove() error {
 }
 
 func (pm *pidManager) isProcessAlive(pid int) bool {
-	log.Printf("Checking if process %d is alive", pid)
-
 	proc, err := os.FindProcess(pid)
 	if err != nil {
-		log.Printf("Process %d not found: %v", pid, err)
+		logger.Debug("Process not found", "pid", pid, "err", err)
 		return false
 	}
 
 	err = proc.Signal(syscall.Signal(0))
 	if err != nil {
-		log.Printf("Process %d not alive (signal failed: %v)", pid, err)
+		logger.Debug("Process not alive", "pid", pid, "err", err)
 		return false
 	}
 
 	// Verify the process is actually hyprvoice and not a recycled PID
 	cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
 	if err != nil {
-		log.Printf("Process %d alive but cannot read cmdline, assuming stale: %v", pid, err)
+		logger.Debug("Process alive but cannot read cmdline, assuming stale", "pid", pid, "err", err)
 		return false
 	}
 
 	exe := string(cmdline)
 	if len(exe) == 0 || !strings.Contains(exe, "hyprvoice") {
-		log.Printf("Process %d is alive but is not hyprvoice (cmdline: %q), stale PID file", pid, exe)
+		logger.Debug("Process alive but is not hyprvoice, stale PID file", "pid", pid, "cmdline", exe)
 		return false
 	}
 
@@ -118,7 +139,7 @@ func (pm *pidManager) isProcessAlive(pid int) bool {
 
 func (pm *pidManager) removeStaleFile() {
 	if err := os.Remove(pm.path); err != nil && !os.IsNotExist(err) {
-		log.Printf("Warning: failed to remove stale PID file: %v", err)
+		logger.Warn("Failed to remove stale PID file", "err", err)
 	}
 }
 
@@ -135,9 +156,15 @@ func newSocketManager() (*socketManager, error) {
 }
 
 func (sm *socketManager) listen() (net.Listener, error) {
-	if err := os.MkdirAll(filepath.Dir(sm.path), 0o700); err != nil {
+	dir := filepath.Dir(sm.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
 		return nil, fmt.Errorf("failed to create socket directory: %w", err)
 	}
+	// MkdirAll keeps the mode of an existing directory. A private directory
+	// also covers the moment between creating the socket and chmod below.
+	if err := os.Chmod(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to set socket directory permissions: %w", err)
+	}
 
 	os.Remove(sm.path)
 
@@ -145,8 +172,12 @@ func (sm *socketManager) listen() (net.Listener, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to listen on socket %s: %w", sm.path, err)
 	}
+	if err := os.Chmod(sm.path, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
 
-	return listener, nil
+	return &peerListener{Listener: listener, uid: os.Getuid()}, nil
 }
 
 func (sm *socketManager) dial() (net.Conn, error) {
@@ -157,20 +188,40 @@ func (sm *socketManager) dial() (net.Conn, error) {
 	return conn, nil
 }
 
+// runtimeDir returns the directory holding the socket and PID file:
+// $XDG_RUNTIME_DIR/hyprvoice, falling back to the user cache dir when
+// XDG_RUNTIME_DIR is unset. Named instances use hyprvoice-<name>.
+func runtimeDir() (string, error) {
+	base := os.Getenv("XDG_RUNTIME_DIR")
+	if base == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		base = dir
+	}
+
+	name := "hyprvoice"
+	if instance != "" {
+		name += "-" + instance
+	}
+	return filepath.Join(base, name), nil
+}
+
 func getSockPath() (string, error) {
-	dir, err := os.UserCacheDir()
+	dir, err := runtimeDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(dir, "hyprvoice", SockName), nil
+	return filepath.Join(dir, SockName), nil
 }
 
 func getPidPath() (string, error) {
-	dir, err := os.UserCacheDir()
+	dir, err := runtimeDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(dir, "hyprvoice", PidName), nil
+	return filepath.Join(dir, PidName), nil
 }
 
 func SockPath() (string, error) {
@@ -217,6 +268,35 @@ func RemovePidFile() error {
 	return pm.remove()
 }
 
+// PidPath returns the daemon PID file path
+func PidPath() (string, error) {
+	return getPidPath()
+}
+
+// PidFileStatus reports the PID recorded in the PID file and whether it is a
+// live hyprvoice process. pid is 0 when there is no PID file.
+func PidFileStatus() (pid int, alive bool, err error) {
+	pm, err := newPidManager()
+	if err != nil {
+		return 0, false, err
+	}
+
+	pidData, err := os.ReadFile(pm.path)
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("error reading PID file: %w", err)
+	}
+
+	pid, err = strconv.Atoi(strings.TrimSpace(string(pidData)))
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid PID file content %q", string(pidData))
+	}
+
+	return pid, pm.isProcessAlive(pid), nil
+}
+
 func SendCommand(cmd byte) (string, error) {
 	c, err := Dial()
 	if err != nil {
@@ -1,6 +1,9 @@
 package bus
 
 import (
+	"bufio"
+	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
@@ -13,6 +16,7 @@ func TestPidManager_CheckExisting(t *testing.T) {
 		tempDir := t.TempDir()
 		originalCacheDir := os.Getenv("XDG_CACHE_HOME")
 		os.Setenv("XDG_CACHE_HOME", tempDir)
+		t.Setenv("XDG_RUNTIME_DIR", "")
 		defer func() {
 			if originalCacheDir == "" {
 				os.Unsetenv("XDG_CACHE_HOME")
@@ -37,6 +41,7 @@ func TestPidManager_CheckExisting(t *testing.T) {
 		tempDir := t.TempDir()
 		originalCacheDir := os.Getenv("XDG_CACHE_HOME")
 		os.Setenv("XDG_CACHE_HOME", tempDir)
+		t.Setenv("XDG_RUNTIME_DIR", "")
 		defer func() {
 			if originalCacheDir == "" {
 				os.Unsetenv("XDG_CACHE_HOME")
@@ -69,6 +74,7 @@ func TestPidManager_Create(t *testing.T) {
 	tempDir := t.TempDir()
 	originalCacheDir := os.Getenv("XDG_CACHE_HOME")
 	os.Setenv("XDG_CACHE_HOME", tempDir)
+	t.Setenv("XDG_RUNTIME_DIR", "")
 	defer func() {
 		if originalCacheDir == "" {
 			os.Unsetenv("XDG_CACHE_HOME")
@@ -100,6 +106,7 @@ func TestPidManager_Remove(t *testing.T) {
 	tempDir := t.TempDir()
 	originalCacheDir := os.Getenv("XDG_CACHE_HOME")
 	os.Setenv("XDG_CACHE_HOME", tempDir)
+	t.Setenv("XDG_RUNTIME_DIR", "")
 	defer func() {
 		if originalCacheDir == "" {
 			os.Unsetenv("XDG_CACHE_HOME")
@@ -137,6 +144,7 @@ func TestSocketManager_Listen(t *testing.T) {
 	tempDir := t.TempDir()
 	originalCacheDir := os.Getenv("XDG_CACHE_HOME")
 	os.Setenv("XDG_CACHE_HOME", tempDir)
+	t.Setenv("XDG_RUNTIME_DIR", "")
 	defer func() {
 		if originalCacheDir == "" {
 			os.Unsetenv("XDG_CACHE_HOME")
@@ -168,6 +176,7 @@ func TestSocketManager_Dial(t *testing.T) {
 	tempDir := t.TempDir()
 	originalCacheDir := os.Getenv("XDG_CACHE_HOME")
 	os.Setenv("XDG_CACHE_HOME", tempDir)
+	t.Setenv("XDG_RUNTIME_DIR", "")
 	defer func() {
 		if originalCacheDir == "" {
 			os.Unsetenv("XDG_CACHE_HOME")
@@ -220,6 +229,7 @@ func TestSendCommand(t *testing.T) {
 	tempDir := t.TempDir()
 	originalCacheDir := os.Getenv("XDG_CACHE_HOME")
 	os.Setenv("XDG_CACHE_HOME", tempDir)
+	t.Setenv("XDG_RUNTIME_DIR", "")
 	defer func() {
 		if originalCacheDir == "" {
 			os.Unsetenv("XDG_CACHE_HOME")
@@ -297,6 +307,7 @@ func TestCheckExistingDaemon(t *testing.T) {
 		tempDir := t.TempDir()
 		originalCacheDir := os.Getenv("XDG_CACHE_HOME")
 		os.Setenv("XDG_CACHE_HOME", tempDir)
+		t.Setenv("XDG_RUNTIME_DIR", "")
 		defer func() {
 			if originalCacheDir == "" {
 				os.Unsetenv("XDG_CACHE_HOME")
@@ -316,6 +327,7 @@ func TestCreatePidFile(t *testing.T) {
 	tempDir := t.TempDir()
 	originalCacheDir := os.Getenv("XDG_CACHE_HOME")
 	os.Setenv("XDG_CACHE_HOME", tempDir)
+	t.Setenv("XDG_RUNTIME_DIR", "")
 	defer func() {
 		if originalCacheDir == "" {
 			os.Unsetenv("XDG_CACHE_HOME")
@@ -341,6 +353,7 @@ func TestRemovePidFile(t *testing.T) {
 	tempDir := t.TempDir()
 	originalCacheDir := os.Getenv("XDG_CACHE_HOME")
 	os.Setenv("XDG_CACHE_HOME", tempDir)
+	t.Setenv("XDG_RUNTIME_DIR", "")
 	defer func() {
 		if originalCacheDir == "" {
 			os.Unsetenv("XDG_CACHE_HOME")
@@ -373,6 +386,7 @@ func TestListen(t *testing.T) {
 	tempDir := t.TempDir()
 	originalCacheDir := os.Getenv("XDG_CACHE_HOME")
 	os.Setenv("XDG_CACHE_HOME", tempDir)
+	t.Setenv("XDG_RUNTIME_DIR", "")
 	defer func() {
 		if originalCacheDir == "" {
 			os.Unsetenv("XDG_CACHE_HOME")
@@ -399,6 +413,7 @@ func TestDial(t *testing.T) {
 	tempDir := t.TempDir()
 	originalCacheDir := os.Getenv("XDG_CACHE_HOME")
 	os.Setenv("XDG_CACHE_HOME", tempDir)
+	t.Setenv("XDG_RUNTIME_DIR", "")
 	defer func() {
 		if originalCacheDir == "" {
 			os.Unsetenv("XDG_CACHE_HOME")
@@ -441,3 +456,252 @@ func TestDial(t *testing.T) {
 		t.Errorf("Dial() returned nil connection")
 	}
 }
+
+func TestIsJSONRequest(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"t\n", false},
+		{"s\n", false},
+		{"{\"cmd\":\"status\"}\n", true},
+		{"  {\"cmd\":\"status\"}\n", true},
+		{"\n", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsJSONRequest(tt.line); got != tt.want {
+			t.Errorf("IsJSONRequest(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	req, err := ParseRequest(`{"id":"42","cmd":"toggle","args":{"language":"de"}}`)
+	if err != nil {
+		t.Fatalf("ParseRequest() error = %v", err)
+	}
+	if req.ID != "42" || req.Cmd != CmdToggle || req.Args["language"] != "de" {
+		t.Errorf("ParseRequest() = %+v", req)
+	}
+
+	if _, err := ParseRequest(`{"id":"1"}`); err == nil {
+		t.Errorf("ParseRequest() without cmd should fail")
+	}
+	if _, err := ParseRequest(`{not json`); err == nil {
+		t.Errorf("ParseRequest() with invalid JSON should fail")
+	}
+}
+
+func TestCall(t *testing.T) {
+	tempDir := t.TempDir()
+	originalCacheDir := os.Getenv("XDG_CACHE_HOME")
+	os.Setenv("XDG_CACHE_HOME", tempDir)
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	defer func() {
+		if originalCacheDir == "" {
+			os.Unsetenv("XDG_CACHE_HOME")
+		} else {
+			os.Setenv("XDG_CACHE_HOME", originalCacheDir)
+		}
+	}()
+
+	listener, err := Listen()
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	defer listener.Close()
+
+	// Echo a status response for every request
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			line, err := bufio.NewReader(conn).ReadString('\n')
+			if err != nil {
+				conn.Close()
+				continue
+			}
+			req, err := ParseRequest(line)
+			if err != nil {
+				WriteResponse(conn, Response{Error: err.Error()})
+				conn.Close()
+				continue
+			}
+
+			WriteResponse(conn, Response{
+				ID: req.ID,
+				OK: true,
+				Status: &StatusInfo{
+					Status:   "idle",
+					Provider: "openai",
+					Model:    "whisper-1",
+					Version:  "dev",
+				},
+			})
+			conn.Close()
+		}
+	}()
+
+	resp, err := Call(Request{ID: "1", Cmd: CmdStatus})
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+
+	if resp.ID != "1" {
+		t.Errorf("Call() ID = %q, want %q", resp.ID, "1")
+	}
+	if resp.Proto != ProtoV2 {
+		t.Errorf("Call() Proto = %q, want %q", resp.Proto, ProtoV2)
+	}
+	if !resp.OK || resp.Status == nil {
+		t.Fatalf("Call() = %+v, want OK status response", resp)
+	}
+	if resp.Status.Status != "idle" || resp.Status.Model != "whisper-1" {
+		t.Errorf("Call() Status = %+v", resp.Status)
+	}
+}
+
+func TestRuntimeDir(t *testing.T) {
+	runtimeDir := t.TempDir()
+	cacheDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+	defer SetInstance("")
+
+	sockPath, err := SockPath()
+	if err != nil {
+		t.Fatalf("SockPath() error = %v", err)
+	}
+	if want := filepath.Join(runtimeDir, "hyprvoice", SockName); sockPath != want {
+		t.Errorf("SockPath() = %q, want %q", sockPath, want)
+	}
+
+	if err := SetInstance("test"); err != nil {
+		t.Fatalf("SetInstance() error = %v", err)
+	}
+	pidPath, err := PidPath()
+	if err != nil {
+		t.Fatalf("PidPath() error = %v", err)
+	}
+	if want := filepath.Join(runtimeDir, "hyprvoice-test", PidName); pidPath != want {
+		t.Errorf("PidPath() = %q, want %q", pidPath, want)
+	}
+
+	// without XDG_RUNTIME_DIR the cache dir is used
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	sockPath, _ = SockPath()
+	if want := filepath.Join(cacheDir, "hyprvoice-test", SockName); sockPath != want {
+		t.Errorf("SockPath() = %q, want %q", sockPath, want)
+	}
+}
+
+func TestSetInstance(t *testing.T) {
+	defer SetInstance("")
+
+	for _, name := range []string{"", "seat1", "test_2", "a-b"} {
+		if err := SetInstance(name); err != nil {
+			t.Errorf("SetInstance(%q) error = %v", name, err)
+		}
+		if Instance() != name {
+			t.Errorf("Instance() = %q, want %q", Instance(), name)
+		}
+	}
+	for _, name := range []string{"../x", "a/b", "with space", "."} {
+		if err := SetInstance(name); err == nil {
+			t.Errorf("SetInstance(%q) should fail", name)
+		}
+	}
+}
+
+func TestListen_SocketPermissions(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	// a directory left open by an older version is tightened
+	dir, _ := runtimeDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := Listen()
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer ln.Close()
+
+	sockPath, _ := SockPath()
+	info, err := os.Stat(sockPath)
+	if err != nil {
+		t.Fatalf("socket not created: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("socket permissions = %o, want 600", perm)
+	}
+	info, err = os.Stat(dir)
+	if err != nil {
+		t.Fatalf("stat socket directory: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o700 {
+		t.Errorf("socket directory permissions = %o, want 700", perm)
+	}
+}
+
+func TestPeerListener(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "peer.sock")
+	inner, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	t.Run("same_uid", func(t *testing.T) {
+		ln := &peerListener{Listener: inner, uid: os.Getuid()}
+		go func() {
+			conn, err := ln.Accept()
+			if err == nil {
+				conn.Write([]byte("ok\n"))
+				conn.Close()
+			}
+		}()
+
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			t.Fatalf("dial error = %v", err)
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		if line != "ok\n" {
+			t.Errorf("read %q, want the accepted connection to answer", line)
+		}
+	})
+
+	t.Run("other_uid", func(t *testing.T) {
+		ln := &peerListener{Listener: inner, uid: os.Getuid() + 1}
+		accepted := make(chan net.Conn, 1)
+		go func() {
+			conn, err := ln.Accept()
+			if err == nil {
+				accepted <- conn
+			}
+		}()
+
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			t.Fatalf("dial error = %v", err)
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+			t.Errorf("read error = %v, want the connection closed", err)
+		}
+
+		inner.Close()
+		select {
+		case <-accepted:
+			t.Errorf("Accept() returned a connection from another uid")
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+}
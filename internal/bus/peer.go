@@ -0,0 +1,34 @@
+package bus
+
+import (
+	"net"
+)
+
+// peerListener only hands out connections from processes running as uid,
+// so other users cannot drive the daemon even if they reach the socket
+type peerListener struct {
+	net.Listener
+	uid int
+}
+
+func (l *peerListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		uid, err := peerUID(conn)
+		if err != nil {
+			logger.Warn("Rejecting client: failed to read peer credentials", "err", err)
+			conn.Close()
+			continue
+		}
+		if uid != l.uid {
+			logger.Warn("Rejecting client from another user", "uid", uid, "daemon_uid", l.uid)
+			conn.Close()
+			continue
+		}
+		return conn, nil
+	}
+}
@@ -0,0 +1,276 @@
+package bus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ProtoV2 is the version of the line-delimited JSON control protocol.
+// Clients that write a single command byte keep talking ProtoVer (0.1).
+const ProtoV2 = "2"
+
+// Command names a request understood by the daemon over the JSON protocol
+type Command string
+
+// ArgWait makes toggle and stop hold the connection until the dictation
+// they acted on ends and reply with its DictationResult
+const ArgWait = "wait"
+
+const (
+	CmdToggle  Command = "toggle"
+	CmdStatus  Command = "status"
+	CmdVersion Command = "version"
+	CmdQuit    Command = "quit"
+
+	// CmdStart and CmdStop drive push-to-talk: start never aborts a running
+	// dictation and stop always finalizes and injects
+	CmdStart Command = "start"
+	CmdStop  Command = "stop"
+
+	// CmdCancel aborts the current dictation, or the one named by
+	// args["session"]: "all" or an ID from StatusInfo.Sessions
+	CmdCancel Command = "cancel"
+
+	// CmdLast acts on the most recent dictation; args["action"] is
+	// "reinject", "copy" or "undo"
+	CmdLast Command = "last"
+
+	// CmdSet changes the sticky runtime overrides (args are override keys,
+	// an empty value clears one) and returns the current set. Toggle and
+	// start accept the same args for a single dictation.
+	CmdSet Command = "set"
+
+	// CmdSubscribe keeps the connection open and streams Event lines
+	CmdSubscribe Command = "subscribe"
+)
+
+// Request is a single JSON protocol request, written as one line
+type Request struct {
+	ID   string            `json:"id,omitempty"`
+	Cmd  Command           `json:"cmd"`
+	Args map[string]string `json:"args,omitempty"`
+}
+
+// Response is a single JSON protocol response, written as one line.
+// ID echoes the request ID so clients can match replies.
+type Response struct {
+	ID      string      `json:"id,omitempty"`
+	Proto   string      `json:"proto"`
+	OK      bool        `json:"ok"`
+	Result  string      `json:"result,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	Version string      `json:"version,omitempty"`
+	Status  *StatusInfo `json:"status,omitempty"`
+	Event   *Event      `json:"event,omitempty"`
+
+	Overrides map[string]string `json:"overrides,omitempty"`
+	Dictation *DictationResult  `json:"dictation,omitempty"`
+}
+
+// DictationResult is the outcome of a dictation, returned to toggle and
+// stop requests sent with ArgWait
+type DictationResult struct {
+	Session    int      `json:"session"`
+	Transcript string   `json:"transcript"`          // raw transcription
+	Processed  string   `json:"processed,omitempty"` // LLM output
+	Text       string   `json:"text"`                // text injected, or meant to be
+	Backend    string   `json:"backend,omitempty"`
+	Injected   bool     `json:"injected"`
+	Cancelled  bool     `json:"cancelled,omitempty"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// StatusInfo describes the daemon state returned by the status command
+type StatusInfo struct {
+	Status        string  `json:"status"`
+	Provider      string  `json:"provider"`
+	Model         string  `json:"model"`
+	LastError     string  `json:"last_error,omitempty"`
+	UptimeSeconds float64 `json:"uptime_seconds"`
+	Version       string  `json:"version"`
+
+	// SessionSeconds is how long the current dictation has been running (0 when idle)
+	SessionSeconds float64 `json:"session_seconds,omitempty"`
+
+	// Level and Peak are the latest input levels of the recording
+	// dictation, from 0 (silence) to 1 (full scale)
+	Level float64 `json:"level,omitempty"`
+	Peak  float64 `json:"peak,omitempty"`
+
+	// Overrides are the sticky runtime overrides set with hyprvoice set
+	Overrides map[string]string `json:"overrides,omitempty"`
+
+	// Queue counts earlier dictations that stopped recording but have not
+	// been injected yet; Sessions lists every running dictation when more
+	// than one is
+	Queue    int           `json:"queue,omitempty"`
+	Sessions []SessionInfo `json:"sessions,omitempty"`
+}
+
+// SessionInfo describes one running dictation
+type SessionInfo struct {
+	ID      int     `json:"id"`
+	Status  string  `json:"status"`
+	Seconds float64 `json:"seconds"`
+}
+
+// EventType identifies the kind of event pushed to subscribers
+type EventType string
+
+const (
+	EventStatus       EventType = "status"       // pipeline status transition
+	EventNotification EventType = "notification" // notification message type
+	EventError        EventType = "error"        // pipeline error
+	EventTranscript   EventType = "transcript"   // streaming partial or final transcript
+	EventLevel        EventType = "level"        // input levels while recording, every 100ms
+)
+
+// Event is pushed to subscribed clients as the daemon state changes
+type Event struct {
+	Type         EventType `json:"type"`
+	Time         time.Time `json:"time"`
+	Status       string    `json:"status,omitempty"`
+	Notification string    `json:"notification,omitempty"`
+	Title        string    `json:"title,omitempty"`
+	Message      string    `json:"message,omitempty"`
+	Text         string    `json:"text,omitempty"`
+	Final        bool      `json:"final,omitempty"`
+	Queue        int       `json:"queue,omitempty"` // status events: dictations waiting to inject
+	Level        float64   `json:"level,omitempty"` // level events: RMS level from 0 to 1
+	Peak         float64   `json:"peak,omitempty"`  // level events: peak level from 0 to 1
+
+	// status events: the session that changed and its new status, which
+	// differs from Status when an earlier dictation moves on in the background
+	Session       int    `json:"session,omitempty"`
+	SessionStatus string `json:"session_status,omitempty"`
+}
+
+// IsJSONRequest reports whether a request line uses the JSON protocol
+// rather than a legacy single-byte command.
+func IsJSONRequest(line string) bool {
+	for _, c := range line {
+		switch c {
+		case ' ', '\t':
+			continue
+		case '{':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// ParseRequest decodes one JSON protocol request line
+func ParseRequest(line string) (Request, error) {
+	var req Request
+	if err := json.Unmarshal([]byte(line), &req); err != nil {
+		return Request{}, fmt.Errorf("invalid request: %w", err)
+	}
+	if req.Cmd == "" {
+		return Request{}, fmt.Errorf("invalid request: missing cmd")
+	}
+	return req, nil
+}
+
+// WriteResponse encodes resp as a single JSON line
+func WriteResponse(w io.Writer, resp Response) error {
+	resp.Proto = ProtoV2
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	data = append(data, '\n')
+	_, err = w.Write(data)
+	return err
+}
+
+// Call sends a JSON protocol request to the daemon and returns its response
+func Call(req Request) (*Response, error) {
+	c, err := Dial()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	defer c.Close()
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	data = append(data, '\n')
+	if _, err := c.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	line, err := bufio.NewReader(c).ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &resp, nil
+}
+
+// Subscribe opens an event stream and calls onEvent for every event until
+// ctx is cancelled, the daemon closes the connection, or onEvent fails.
+func Subscribe(ctx context.Context, onEvent func(Event) error) error {
+	c, err := Dial()
+	if err != nil {
+		return fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	defer c.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.Close()
+		case <-done:
+		}
+	}()
+
+	data, err := json.Marshal(Request{Cmd: CmdSubscribe})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	data = append(data, '\n')
+	if _, err := c.Write(data); err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	reader := bufio.NewReader(c)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err == io.EOF {
+				return fmt.Errorf("daemon closed the event stream")
+			}
+			return fmt.Errorf("failed to read event: %w", err)
+		}
+
+		var resp Response
+		if err := json.Unmarshal(line, &resp); err != nil {
+			return fmt.Errorf("failed to decode event: %w", err)
+		}
+		if !resp.OK {
+			return fmt.Errorf("subscribe failed: %s", resp.Error)
+		}
+		if resp.Event == nil {
+			continue
+		}
+		if err := onEvent(*resp.Event); err != nil {
+			return err
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/leonardotrapani/hyprvoice/internal/notify"
 )
 
@@ -2009,3 +2010,649 @@ type = "log"`
 		}
 	})
 }
+
+func TestConfig_HistoryAndStatsDefaults(t *testing.T) {
+	base := `[recording]
+sample_rate = 16000
+channels = 1
+format = "s16"
+buffer_size = 8192
+channel_buffer_size = 30
+timeout = "5m"
+
+[transcription]
+provider = "openai"
+model = "whisper-1"
+
+[providers.openai]
+api_key = "test-key"
+
+[injection]
+backends = ["clipboard"]
+ydotool_timeout = "5s"
+wtype_timeout = "5s"
+clipboard_timeout = "3s"
+
+[notifications]
+type = "log"
+`
+
+	tests := []struct {
+		name         string
+		extra        string
+		wantEnabled  bool
+		wantMax      int
+		wantMaxAge   time.Duration
+		wantStats    bool
+		wantStatsMax int
+	}{
+		{"missing sections keep history off and enable stats", "", false, DefaultHistoryMaxEntries, 0, true, DefaultStatsMaxEntries},
+		{"enabled history gets default retention", "\n[history]\nenabled = true\n", true, DefaultHistoryMaxEntries, 0, true, DefaultStatsMaxEntries},
+		{"explicit values kept", "\n[history]\nenabled = false\nmax_entries = 0\nmax_age = \"720h\"\n\n[stats]\nenabled = false\nmax_entries = 0\n", false, 0, 720 * time.Hour, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			configPath := filepath.Join(tempDir, "hyprvoice", "config.toml")
+			os.MkdirAll(filepath.Dir(configPath), 0755)
+			os.WriteFile(configPath, []byte(base+tt.extra), 0644)
+
+			originalConfigDir := os.Getenv("XDG_CONFIG_HOME")
+			os.Setenv("XDG_CONFIG_HOME", tempDir)
+			defer func() {
+				if originalConfigDir == "" {
+					os.Unsetenv("XDG_CONFIG_HOME")
+				} else {
+					os.Setenv("XDG_CONFIG_HOME", originalConfigDir)
+				}
+			}()
+
+			config, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if config.History.Enabled != tt.wantEnabled || config.History.MaxEntries != tt.wantMax || config.History.MaxAge != tt.wantMaxAge {
+				t.Errorf("History = %+v", config.History)
+			}
+			if config.Stats.Enabled != tt.wantStats {
+				t.Errorf("Stats.Enabled = %v, want %v", config.Stats.Enabled, tt.wantStats)
+			}
+			if config.Stats.MaxEntries != tt.wantStatsMax {
+				t.Errorf("Stats.MaxEntries = %d, want %d", config.Stats.MaxEntries, tt.wantStatsMax)
+			}
+		})
+	}
+}
+
+func TestConfig_TrimDefaults(t *testing.T) {
+	base := `[recording]
+sample_rate = 16000
+channels = 1
+format = "s16"
+buffer_size = 8192
+channel_buffer_size = 30
+timeout = "5m"
+
+[transcription]
+provider = "openai"
+model = "whisper-1"
+
+[providers.openai]
+api_key = "test-key"
+
+[injection]
+backends = ["clipboard"]
+ydotool_timeout = "5s"
+wtype_timeout = "5s"
+clipboard_timeout = "3s"
+
+[notifications]
+type = "log"
+`
+
+	tests := []struct {
+		name  string
+		extra string
+		want  TrimConfig
+	}{
+		{"missing section keeps trimming off", "", TrimConfig{Threshold: 0.01, Margin: DefaultTrimMargin}},
+		{"enabled trimming gets defaults", "\n[transcription.trim]\nenabled = true\n", TrimConfig{Enabled: true, Threshold: 0.01, Margin: DefaultTrimMargin}},
+		{"explicit values kept", "\n[transcription.trim]\nenabled = false\nmargin = \"0s\"\nmax_pause = \"1s\"\n", TrimConfig{Threshold: 0.01, MaxPause: time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			configPath := filepath.Join(tempDir, "hyprvoice", "config.toml")
+			os.MkdirAll(filepath.Dir(configPath), 0755)
+			os.WriteFile(configPath, []byte(base+tt.extra), 0644)
+			t.Setenv("XDG_CONFIG_HOME", tempDir)
+
+			config, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if config.Transcription.Trim != tt.want {
+				t.Errorf("Transcription.Trim = %+v, want %+v", config.Transcription.Trim, tt.want)
+			}
+			if tc := config.ToTranscriberConfig(); tc.Trim.Enabled != tt.want.Enabled || tc.Trim.MaxPause != tt.want.MaxPause {
+				t.Errorf("ToTranscriberConfig().Trim = %+v", tc.Trim)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_History(t *testing.T) {
+	config := &Config{
+		Recording: RecordingConfig{
+			SampleRate:        16000,
+			Channels:          1,
+			Format:            "s16",
+			BufferSize:        8192,
+			ChannelBufferSize: 30,
+			Timeout:           time.Minute,
+		},
+		Transcription: TranscriptionConfig{
+			Provider: "openai",
+			Model:    "whisper-1",
+		},
+		Providers: map[string]ProviderConfig{
+			"openai": {APIKey: "test-key"},
+		},
+		Injection: InjectionConfig{
+			Backends:         []string{"clipboard"},
+			YdotoolTimeout:   time.Second,
+			WtypeTimeout:     time.Second,
+			ClipboardTimeout: time.Second,
+		},
+		Notifications: NotificationsConfig{
+			Type: "log",
+		},
+		History: HistoryConfig{
+			Enabled:    true,
+			MaxEntries: -1,
+		},
+	}
+
+	if err := config.Validate(); err == nil {
+		t.Errorf("Validate() should have failed with negative history.max_entries")
+	}
+
+	config.History.MaxEntries = 10
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestConfig_Validate_VAD(t *testing.T) {
+	config := &Config{
+		Recording: RecordingConfig{
+			SampleRate:        16000,
+			Channels:          1,
+			Format:            "s16",
+			BufferSize:        8192,
+			ChannelBufferSize: 30,
+			Timeout:           time.Minute,
+		},
+		Transcription: TranscriptionConfig{
+			Provider: "openai",
+			Model:    "whisper-1",
+		},
+		Providers: map[string]ProviderConfig{
+			"openai": {APIKey: "test-key"},
+		},
+		Injection: InjectionConfig{
+			Backends:         []string{"clipboard"},
+			YdotoolTimeout:   time.Second,
+			WtypeTimeout:     time.Second,
+			ClipboardTimeout: time.Second,
+		},
+		Notifications: NotificationsConfig{
+			Type: "log",
+		},
+	}
+	config.Recording.VAD = VADConfig{Enabled: true, Threshold: 2, Silence: time.Second}
+
+	if err := config.Validate(); err == nil {
+		t.Errorf("Validate() should have failed with recording.vad.threshold above 1")
+	}
+
+	config.Recording.VAD.Threshold = 0.02
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	config.Recording.Format = "f32"
+	if err := config.Validate(); err == nil {
+		t.Errorf("Validate() should have failed with VAD on f32 audio")
+	}
+
+	// disabled VAD is not checked
+	config.Recording.VAD = VADConfig{}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() with VAD disabled error = %v", err)
+	}
+}
+
+func TestConfig_Validate_Warm(t *testing.T) {
+	config := DefaultConfig()
+	config.Transcription.Provider = "openai"
+	config.Transcription.Model = "whisper-1"
+	config.Providers = map[string]ProviderConfig{"openai": {APIKey: "test-key"}}
+	config.Notifications.Type = "log"
+	config.Recording.Warm = WarmConfig{Enabled: true, PreRoll: 10 * time.Second}
+
+	if err := config.Validate(); err == nil {
+		t.Errorf("Validate() should have failed with recording.warm.pre_roll above the maximum")
+	}
+
+	config.Recording.Warm.PreRoll = 500 * time.Millisecond
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	// disabled warm recorder is not checked
+	config.Recording.Warm = WarmConfig{PreRoll: -time.Second}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() with warm recorder disabled error = %v", err)
+	}
+}
+
+func TestConfig_Validate_Backend(t *testing.T) {
+	config := DefaultConfig()
+	config.Transcription.Provider = "openai"
+	config.Transcription.Model = "whisper-1"
+	config.Providers = map[string]ProviderConfig{"openai": {APIKey: "test-key"}}
+	config.Notifications.Type = "log"
+
+	config.Recording.Backend = "oss"
+	if err := config.Validate(); err == nil {
+		t.Errorf("Validate() should have failed with an unknown recording.backend")
+	}
+
+	config.Recording.Backend = "file"
+	if err := config.Validate(); err == nil {
+		t.Errorf("Validate() should have failed with the file backend and no recording.file")
+	}
+
+	config.Recording.File = "-"
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	config.Recording.Backend = "arecord"
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestConfig_Validate_Logging(t *testing.T) {
+	config := &Config{
+		Recording: RecordingConfig{
+			SampleRate:        16000,
+			Channels:          1,
+			Format:            "s16",
+			BufferSize:        8192,
+			ChannelBufferSize: 30,
+			Timeout:           time.Minute,
+		},
+		Transcription: TranscriptionConfig{
+			Provider: "openai",
+			Model:    "whisper-1",
+		},
+		Providers: map[string]ProviderConfig{
+			"openai": {APIKey: "test-key"},
+		},
+		Injection: InjectionConfig{
+			Backends:         []string{"clipboard"},
+			YdotoolTimeout:   time.Second,
+			WtypeTimeout:     time.Second,
+			ClipboardTimeout: time.Second,
+		},
+		Notifications: NotificationsConfig{
+			Type: "log",
+		},
+		Logging: LoggingConfig{
+			Level: "verbose",
+		},
+	}
+
+	if err := config.Validate(); err == nil {
+		t.Errorf("Validate() should have failed with invalid logging.level")
+	}
+
+	config.Logging.Level = "debug"
+	config.Logging.MaxFiles = -1
+	if err := config.Validate(); err == nil {
+		t.Errorf("Validate() should have failed with negative logging.max_files")
+	}
+
+	config.Logging.MaxFiles = 3
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestConfig_RecordingPolicies(t *testing.T) {
+	config := createTestConfig()
+	config.Recording.OnTimeout = PolicySave
+	config.Recording.OnShutdown = PolicyDiscard
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	config.Recording.OnShutdown = "keep"
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "on_shutdown") {
+		t.Errorf("Validate() error = %v, want invalid recording.on_shutdown", err)
+	}
+
+	config.Recording.OnShutdown = PolicySave
+	config.Recording.TimeoutWarning = -time.Second
+	if err := config.Validate(); err == nil {
+		t.Errorf("Validate() should have failed with negative recording.timeout_warning")
+	}
+
+	// configs written before the policies existed get the defaults
+	config.Recording = RecordingConfig{}
+	config.applyRecordingDefaults(toml.MetaData{})
+	if config.Recording.OnTimeout != PolicySave || config.Recording.OnShutdown != PolicySave || config.Recording.TimeoutWarning != DefaultTimeoutWarning {
+		t.Errorf("Recording = %+v", config.Recording)
+	}
+}
+
+func TestConfig_WithOverrides(t *testing.T) {
+	base := createTestConfig()
+	base.Providers["groq"] = ProviderConfig{APIKey: "gsk_test"}
+
+	cfg, err := base.WithOverrides(Overrides{
+		OverrideLanguage: "de",
+		OverrideModel:    "whisper-large-v3-turbo",
+		OverrideLLM:      "off",
+		OverrideBackend:  "wtype, clipboard",
+	})
+	if err != nil {
+		t.Fatalf("WithOverrides() error = %v", err)
+	}
+	// the model lives in another provider, so the provider follows it
+	if cfg.Transcription.Provider != "groq-transcription" || cfg.Transcription.Model != "whisper-large-v3-turbo" {
+		t.Errorf("transcription = %+v", cfg.Transcription)
+	}
+	if cfg.Transcription.Language != "de" || cfg.LLM.Enabled {
+		t.Errorf("language/llm = %q/%v", cfg.Transcription.Language, cfg.LLM.Enabled)
+	}
+	if len(cfg.Injection.Backends) != 2 || cfg.Injection.Backends[0] != "wtype" || cfg.Injection.Backends[1] != "clipboard" {
+		t.Errorf("backends = %v", cfg.Injection.Backends)
+	}
+	if base.Transcription.Provider != "openai" || len(base.Injection.Backends) != 3 {
+		t.Errorf("WithOverrides() modified the original config")
+	}
+
+	cfg, err = base.WithOverrides(Overrides{OverrideModel: "gpt-4o-transcribe", OverrideLanguage: "auto"})
+	if err != nil {
+		t.Fatalf("WithOverrides() error = %v", err)
+	}
+	if cfg.Transcription.Provider != "openai" || cfg.Transcription.Language != "" {
+		t.Errorf("transcription = %+v", cfg.Transcription)
+	}
+
+	for _, o := range []Overrides{
+		{OverrideModel: "no-such-model"},
+		{OverrideModel: "gpt-4o-mini"}, // an LLM, not a transcription model
+		{OverrideBackend: "xdotool"},
+	} {
+		if _, err := base.WithOverrides(o); err == nil {
+			t.Errorf("WithOverrides(%v) should have failed", o)
+		}
+	}
+
+	if _, err := ParseOverrides(map[string]string{"llm": "maybe"}); err == nil {
+		t.Errorf("ParseOverrides() should reject llm=maybe")
+	}
+	if _, err := ParseOverrides(map[string]string{"voice": "loud"}); err == nil {
+		t.Errorf("ParseOverrides() should reject unknown keys")
+	}
+}
+
+func TestConfig_WithTranscriptionProvider(t *testing.T) {
+	base := createTestConfig()
+	base.Providers["groq"] = ProviderConfig{APIKey: "gsk_test"}
+
+	cfg, err := base.WithTranscriptionProvider("groq")
+	if err != nil {
+		t.Fatalf("WithTranscriptionProvider() error = %v", err)
+	}
+	// whisper-1 is not a groq model, so the groq default is used
+	if cfg.Transcription.Provider != "groq-transcription" || cfg.Transcription.Model != "whisper-large-v3-turbo" {
+		t.Errorf("transcription = %+v", cfg.Transcription)
+	}
+	if base.Transcription.Provider != "openai" || base.Transcription.Model != "whisper-1" {
+		t.Errorf("WithTranscriptionProvider() modified the original config")
+	}
+
+	cfg, err = base.WithTranscriptionProvider("openai")
+	if err != nil {
+		t.Fatalf("WithTranscriptionProvider() error = %v", err)
+	}
+	if cfg.Transcription.Model != "whisper-1" {
+		t.Errorf("model = %s, want the configured whisper-1 kept", cfg.Transcription.Model)
+	}
+
+	t.Setenv("DEEPGRAM_API_KEY", "")
+	for _, name := range []string{"no-such-provider", "deepgram"} { // deepgram has no API key here
+		if _, err := base.WithTranscriptionProvider(name); err == nil {
+			t.Errorf("WithTranscriptionProvider(%s) should have failed", name)
+		}
+	}
+}
+
+func TestConfig_Profiles(t *testing.T) {
+	tempDir := t.TempDir()
+	originalConfigDir := os.Getenv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", tempDir)
+	defer func() {
+		if originalConfigDir == "" {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		} else {
+			os.Setenv("XDG_CONFIG_HOME", originalConfigDir)
+		}
+	}()
+
+	content := `keywords = ["Hyprvoice"]
+
+[recording]
+sample_rate = 16000
+channels = 1
+format = "s16"
+buffer_size = 8192
+channel_buffer_size = 30
+timeout = "5m"
+
+[transcription]
+provider = "openai"
+model = "whisper-1"
+
+[providers.openai]
+api_key = "test-key"
+
+[providers.groq]
+api_key = "gsk_test"
+
+[injection]
+backends = ["ydotool", "wtype", "clipboard"]
+ydotool_timeout = "5s"
+wtype_timeout = "5s"
+clipboard_timeout = "3s"
+
+[notifications]
+type = "log"
+
+[profiles.email]
+keywords = ["Grüße"]
+[profiles.email.transcription]
+language = "de"
+[profiles.email.llm]
+enabled = true
+provider = "openai"
+model = "gpt-4o-mini"
+[profiles.email.llm.custom_prompt]
+enabled = true
+prompt = "Write a polite email."
+
+[profiles.code]
+[profiles.code.transcription]
+provider = "groq-transcription"
+model = "whisper-large-v3-turbo"
+[profiles.code.injection]
+backends = ["clipboard"]
+`
+	configPath := filepath.Join(tempDir, "hyprvoice", "config.toml")
+	os.MkdirAll(filepath.Dir(configPath), 0755)
+	os.WriteFile(configPath, []byte(content), 0644)
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if names := config.ProfileNames(); len(names) != 2 || names[0] != "code" || names[1] != "email" {
+		t.Fatalf("ProfileNames() = %v", names)
+	}
+
+	email, err := config.WithProfile("email")
+	if err != nil {
+		t.Fatalf("WithProfile(email) error = %v", err)
+	}
+	if email.Transcription.Language != "de" || email.Transcription.Model != "whisper-1" {
+		t.Errorf("email transcription = %+v", email.Transcription)
+	}
+	if !email.IsLLMEnabled() || email.ToLLMConfig().CustomPrompt != "Write a polite email." {
+		t.Errorf("email llm = %+v", email.LLM)
+	}
+	if len(email.Keywords) != 1 || email.Keywords[0] != "Grüße" {
+		t.Errorf("email keywords = %v", email.Keywords)
+	}
+	// untouched sections are inherited
+	if email.Recording.Timeout != 5*time.Minute || len(email.Injection.Backends) != 3 {
+		t.Errorf("email inherited recording/injection = %+v / %v", email.Recording, email.Injection.Backends)
+	}
+
+	// overrides are applied after the profile
+	code, err := config.WithOverrides(Overrides{OverrideProfile: "code", OverrideLanguage: "en"})
+	if err != nil {
+		t.Fatalf("WithOverrides(profile=code) error = %v", err)
+	}
+	if code.Transcription.Provider != "groq-transcription" || code.Transcription.Language != "en" || code.Injection.Backends[0] != "clipboard" {
+		t.Errorf("code = %+v / %v", code.Transcription, code.Injection.Backends)
+	}
+
+	// the base config is unchanged
+	if config.Transcription.Language != "" || config.LLM.Enabled || len(config.Keywords) != 1 || config.Keywords[0] != "Hyprvoice" {
+		t.Errorf("WithProfile() modified the base config: %+v", config)
+	}
+
+	if _, err := config.WithProfile("slack"); err == nil || !strings.Contains(err.Error(), "code, email") {
+		t.Errorf("WithProfile(slack) error = %v, want the available profiles", err)
+	}
+
+	// profiles survive a save
+	if err := Save(config); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	reloaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() after Save() error = %v", err)
+	}
+	saved, err := reloaded.WithProfile("email")
+	if err != nil || saved.Transcription.Language != "de" || saved.ToLLMConfig().CustomPrompt != "Write a polite email." {
+		t.Errorf("saved email profile = %+v, %v", saved, err)
+	}
+}
+
+func TestConfig_InvalidProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+	}{
+		{"unknown key", Profile{"transcription": map[string]interface{}{"langauge": "de"}}},
+		{"invalid result", Profile{"transcription": map[string]interface{}{"model": "no-such-model"}}},
+		{"wrong type", Profile{"recording": map[string]interface{}{"sample_rate": "high"}}},
+		{"nested", Profile{"profiles": map[string]interface{}{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createTestConfig()
+			config.Profiles = map[string]Profile{"broken": tt.profile}
+			if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "broken") {
+				t.Errorf("Validate() error = %v, want an error naming the profile", err)
+			}
+		})
+	}
+}
+
+func TestConfig_WindowRules(t *testing.T) {
+	config := createTestConfig()
+	config.Profiles = map[string]Profile{
+		"terminal": {"llm": map[string]interface{}{"enabled": false}},
+		"email":    {"transcription": map[string]interface{}{"language": "de"}},
+	}
+	config.WindowRules = []WindowRule{
+		{Class: "^(kitty|foot|Alacritty)$", Profile: "terminal"},
+		{Class: "^thunderbird$", Title: "^Write:", Profile: "email"},
+		{Title: "Gmail", Profile: "email"},
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		class, title string
+		want         string
+	}{
+		{"kitty", "nvim", "terminal"},
+		{"kitty-dropdown", "nvim", ""},
+		{"thunderbird", "Write: (no subject)", "email"},
+		{"thunderbird", "Inbox", ""},
+		{"firefox", "Inbox - Gmail", "email"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := config.WindowProfile(tt.class, tt.title); got != tt.want {
+			t.Errorf("WindowProfile(%q, %q) = %q, want %q", tt.class, tt.title, got, tt.want)
+		}
+	}
+
+	// patterns are compiled by Validate
+	if (WindowRule{Class: "kitty", Profile: "terminal"}).Matches("kitty", "") {
+		t.Errorf("a rule that was not validated matched")
+	}
+
+	invalid := []struct {
+		name string
+		rule WindowRule
+	}{
+		{"no match", WindowRule{Profile: "terminal"}},
+		{"bad regexp", WindowRule{Class: "(kitty", Profile: "terminal"}},
+		{"no profile", WindowRule{Class: "kitty"}},
+		{"unknown profile", WindowRule{Class: "kitty", Profile: "slack"}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			c := *config
+			c.WindowRules = []WindowRule{tt.rule}
+			if err := c.Validate(); err == nil || !strings.Contains(err.Error(), "window_rules[0]") {
+				t.Errorf("Validate() error = %v, want a window_rules error", err)
+			}
+		})
+	}
+
+	c := *config
+	c.Profiles = map[string]Profile{"loop": {"window_rules": []interface{}{}}}
+	c.WindowRules = nil
+	if err := c.Validate(); err == nil {
+		t.Errorf("Validate() should reject window_rules inside a profile")
+	}
+}
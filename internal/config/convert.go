@@ -3,9 +3,12 @@ package config
 import (
 	"os"
 
+	"github.com/leonardotrapani/hyprvoice/internal/history"
 	"github.com/leonardotrapani/hyprvoice/internal/injection"
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 	"github.com/leonardotrapani/hyprvoice/internal/provider"
 	"github.com/leonardotrapani/hyprvoice/internal/recording"
+	"github.com/leonardotrapani/hyprvoice/internal/stats"
 	"github.com/leonardotrapani/hyprvoice/internal/transcriber"
 )
 
@@ -15,9 +18,21 @@ func (c *Config) ToRecordingConfig() recording.Config {
 		Channels:          c.Recording.Channels,
 		Format:            c.Recording.Format,
 		BufferSize:        c.Recording.BufferSize,
+		Backend:           c.Recording.Backend,
 		Device:            c.Recording.Device,
+		File:              c.Recording.File,
 		ChannelBufferSize: c.Recording.ChannelBufferSize,
 		Timeout:           c.Recording.Timeout,
+		VAD: recording.VADConfig{
+			Enabled:   c.Recording.VAD.Enabled,
+			Threshold: c.Recording.VAD.Threshold,
+			Silence:   c.Recording.VAD.Silence,
+			MinSpeech: c.Recording.VAD.MinSpeech,
+		},
+		Warm: recording.WarmConfig{
+			Enabled: c.Recording.Warm.Enabled,
+			PreRoll: c.Recording.Warm.PreRoll,
+		},
 	}
 }
 
@@ -29,6 +44,12 @@ func (c *Config) ToTranscriberConfig() transcriber.Config {
 		Keywords:  c.Keywords,
 		Threads:   c.Transcription.Threads,
 		Streaming: c.Transcription.Streaming,
+		Trim: transcriber.TrimConfig{
+			Enabled:   c.Transcription.Trim.Enabled,
+			Threshold: c.Transcription.Trim.Threshold,
+			Margin:    c.Transcription.Trim.Margin,
+			MaxPause:  c.Transcription.Trim.MaxPause,
+		},
 	}
 
 	config.APIKey = c.resolveAPIKeyForProvider(c.Transcription.Provider)
@@ -112,3 +133,29 @@ func (c *Config) ToInjectionConfig() injection.Config {
 		ClipboardTimeout: c.Injection.ClipboardTimeout,
 	}
 }
+
+func (c *Config) ToHistoryConfig() history.Config {
+	return history.Config{
+		Enabled:    c.History.Enabled,
+		MaxEntries: c.History.MaxEntries,
+		MaxAge:     c.History.MaxAge,
+		Encrypt:    c.History.Encrypt,
+	}
+}
+
+func (c *Config) ToStatsConfig() stats.Config {
+	return stats.Config{
+		Enabled:    c.Stats.Enabled,
+		MaxEntries: c.Stats.MaxEntries,
+		MaxAge:     c.Stats.MaxAge,
+	}
+}
+
+func (c *Config) ToLoggingConfig() logging.Config {
+	return logging.Config{
+		Level:     c.Logging.Level,
+		File:      c.Logging.File,
+		MaxSizeMB: c.Logging.MaxSizeMB,
+		MaxFiles:  c.Logging.MaxFiles,
+	}
+}
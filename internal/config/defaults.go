@@ -1,6 +1,33 @@
 package config
 
-import "time"
+import (
+	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
+	"github.com/leonardotrapani/hyprvoice/internal/recording"
+)
+
+// DefaultHistoryMaxEntries is the history size used when not configured
+const DefaultHistoryMaxEntries = 1000
+
+// DefaultStatsMaxEntries is the number of stats records kept when not configured
+const DefaultStatsMaxEntries = 10000
+
+// DefaultTrimMargin is how much audio trimming keeps around speech when not
+// configured
+const DefaultTrimMargin = 300 * time.Millisecond
+
+// DefaultTimeoutWarning is how long before recording.timeout the warning
+// notification is shown when not configured
+const DefaultTimeoutWarning = 30 * time.Second
+
+// Policies for a dictation that is still recording when recording.timeout
+// is reached or the daemon shuts down
+const (
+	PolicyInject  = "inject"  // finalize and inject the text
+	PolicySave    = "save"    // finalize and append the text to the recovery file
+	PolicyDiscard = "discard" // drop the recording
+)
 
 // DefaultConfig returns the initial configuration used for onboarding.
 func DefaultConfig() *Config {
@@ -10,14 +37,31 @@ func DefaultConfig() *Config {
 			Channels:          1,
 			Format:            "s16",
 			BufferSize:        8192,
+			Backend:           recording.DefaultBackend,
 			Device:            "",
 			ChannelBufferSize: 30,
 			Timeout:           5 * time.Minute,
+			TimeoutWarning:    DefaultTimeoutWarning,
+			OnTimeout:         PolicySave,
+			OnShutdown:        PolicySave,
+			VAD: VADConfig{
+				Threshold: recording.DefaultSilenceLevel,
+				Silence:   recording.DefaultVADSilence,
+				MinSpeech: recording.DefaultVADMinSpeech,
+			},
+			Warm: WarmConfig{
+				PreRoll: recording.DefaultPreRoll,
+			},
 		},
 		Transcription: TranscriptionConfig{
 			Language:  "",
 			Streaming: false,
 			Threads:   0,
+			Trim: TrimConfig{
+				Enabled:   true,
+				Threshold: recording.DefaultSilenceLevel,
+				Margin:    DefaultTrimMargin,
+			},
 		},
 		Injection: InjectionConfig{
 			Backends:         []string{"ydotool", "wtype", "clipboard"},
@@ -34,5 +78,18 @@ func DefaultConfig() *Config {
 		LLM: LLMConfig{
 			Enabled: false,
 		},
+		History: HistoryConfig{
+			Enabled:    true,
+			MaxEntries: DefaultHistoryMaxEntries,
+		},
+		Stats: StatsConfig{
+			Enabled:    true,
+			MaxEntries: DefaultStatsMaxEntries,
+		},
+		Logging: LoggingConfig{
+			Level:     logging.LevelInfo,
+			MaxSizeMB: logging.DefaultMaxSizeMB,
+			MaxFiles:  logging.DefaultMaxFiles,
+		},
 	}
 }
@@ -3,12 +3,13 @@ package config
 import (
 	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"runtime"
 
 	"github.com/BurntSushi/toml"
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
+	"github.com/leonardotrapani/hyprvoice/internal/recording"
 )
 
 var ErrConfigNotFound = errors.New("config not found")
@@ -33,7 +34,7 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 	if legacy {
-		log.Printf("Config: legacy configuration detected - run hyprvoice onboarding")
+		logger.Warn("Legacy configuration detected, run hyprvoice onboarding")
 		return nil, fmt.Errorf("%w: run hyprvoice onboarding", ErrConfigNotFound)
 	}
 	return config, nil
@@ -53,14 +54,14 @@ func LoadOrLegacy() (*Config, bool, error) {
 		return nil, false, fmt.Errorf("failed to stat config file %s: %w", configPath, err)
 	}
 
-	log.Printf("Config: loading configuration from %s", configPath)
+	logger.Debug("Loading configuration", "path", configPath)
 	var config Config
 	meta, err := toml.DecodeFile(configPath, &config)
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
 	}
 	if isLegacyConfig(meta, &config) {
-		log.Printf("Config: legacy configuration detected - run hyprvoice onboarding")
+		logger.Warn("Legacy configuration detected, run hyprvoice onboarding")
 		return DefaultConfig(), true, nil
 	}
 
@@ -69,9 +70,14 @@ func LoadOrLegacy() (*Config, bool, error) {
 	}
 
 	config.applyLLMDefaults()
+	config.applyRecordingDefaults(meta)
 	config.applyThreadsDefault()
+	config.applyTrimDefaults(meta)
+	config.applyHistoryDefaults(meta)
+	config.applyStatsDefaults(meta)
+	config.applyLoggingDefaults()
 
-	log.Printf("Config: configuration loaded successfully")
+	logger.Debug("Configuration loaded")
 	return &config, false, nil
 }
 
@@ -102,6 +108,83 @@ func (c *Config) applyThreadsDefault() {
 	}
 }
 
+// applyTrimDefaults fills in the trimming settings for configs written before
+// it existed. Trimming stays off for them until the user enables it, so
+// upgrading never changes the audio sent to providers.
+func (c *Config) applyTrimDefaults(meta toml.MetaData) {
+	if c.Transcription.Trim.Threshold == 0 {
+		c.Transcription.Trim.Threshold = recording.DefaultSilenceLevel
+	}
+	if !meta.IsDefined("transcription", "trim", "margin") {
+		c.Transcription.Trim.Margin = DefaultTrimMargin
+	}
+}
+
+// applyRecordingDefaults fills in the timeout policies, VAD and warm
+// recorder settings for configs written before they existed
+func (c *Config) applyRecordingDefaults(meta toml.MetaData) {
+	if c.Recording.Backend == "" {
+		c.Recording.Backend = recording.DefaultBackend
+	}
+	if !meta.IsDefined("recording", "timeout_warning") {
+		c.Recording.TimeoutWarning = DefaultTimeoutWarning
+	}
+	// the text would go to whatever window has focus when the timeout
+	// hits, so injecting it is opt-in
+	if c.Recording.OnTimeout == "" {
+		c.Recording.OnTimeout = PolicySave
+	}
+	if c.Recording.OnShutdown == "" {
+		c.Recording.OnShutdown = PolicySave
+	}
+	if c.Recording.VAD.Threshold == 0 {
+		c.Recording.VAD.Threshold = recording.DefaultSilenceLevel
+	}
+	if c.Recording.VAD.Silence == 0 {
+		c.Recording.VAD.Silence = recording.DefaultVADSilence
+	}
+	if !meta.IsDefined("recording", "vad", "min_speech") {
+		c.Recording.VAD.MinSpeech = recording.DefaultVADMinSpeech
+	}
+	if !meta.IsDefined("recording", "warm", "pre_roll") {
+		c.Recording.Warm.PreRoll = recording.DefaultPreRoll
+	}
+}
+
+// applyHistoryDefaults fills in retention for configs written before
+// history existed. History stays off for them until the user enables it, so
+// upgrading never starts writing dictations to disk.
+func (c *Config) applyHistoryDefaults(meta toml.MetaData) {
+	if !meta.IsDefined("history", "max_entries") {
+		c.History.MaxEntries = DefaultHistoryMaxEntries
+	}
+}
+
+// applyStatsDefaults enables stats for configs written before they existed
+// and caps the records kept for configs written before retention existed
+func (c *Config) applyStatsDefaults(meta toml.MetaData) {
+	if !meta.IsDefined("stats", "enabled") {
+		c.Stats.Enabled = true
+	}
+	if !meta.IsDefined("stats", "max_entries") {
+		c.Stats.MaxEntries = DefaultStatsMaxEntries
+	}
+}
+
+// applyLoggingDefaults fills in the log level and rotation for configs
+// written before [logging] existed
+func (c *Config) applyLoggingDefaults() {
+	if c.Logging.Level == "" {
+		c.Logging.Level = logging.LevelInfo
+	}
+	if c.Logging.MaxSizeMB == 0 {
+		c.Logging.MaxSizeMB = logging.DefaultMaxSizeMB
+	}
+	if c.Logging.MaxFiles == 0 {
+		c.Logging.MaxFiles = logging.DefaultMaxFiles
+	}
+}
+
 // applyLLMDefaults sets default values for LLM config
 func (c *Config) applyLLMDefaults() {
 	pp := &c.LLM.PostProcessing
@@ -2,14 +2,16 @@ package config
 
 import (
 	"context"
-	"log"
 	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 )
 
+var logger = logging.For("config")
+
 type Manager struct {
 	mu      sync.RWMutex
 	config  *Config
@@ -28,21 +30,16 @@ type Manager struct {
 }
 
 func NewManager() (*Manager, error) {
-	log.Printf("Config manager: initializing configuration system...")
-
 	config, legacy, err := LoadOrLegacy()
 	if err != nil {
-		log.Printf("Config manager: failed to load initial configuration: %v", err)
+		logger.Error("Failed to load initial configuration", "err", err)
 		return nil, err
 	}
 
 	if legacy {
-		log.Printf("Config manager: legacy config detected, daemon will prompt for onboarding")
-	} else {
-		log.Printf("Config manager: validating initial configuration...")
-		if err := config.Validate(); err != nil {
-			log.Printf("Config manager: validation warning: %v", err)
-		}
+		logger.Warn("Legacy config detected, daemon will prompt for onboarding")
+	} else if err := config.Validate(); err != nil {
+		logger.Warn("Initial configuration is invalid", "err", err)
 	}
 
 	m := &Manager{
@@ -51,7 +48,6 @@ func NewManager() (*Manager, error) {
 		legacy:        legacy,
 	}
 
-	log.Printf("Config manager: initialization completed successfully")
 	return m, nil
 }
 
@@ -94,7 +90,7 @@ func (m *Manager) StartWatching(ctx context.Context) error {
 	m.wg.Add(1)
 	go m.watchLoop(ctx, configPath)
 
-	log.Printf("Config manager: watching %s for changes", configPath)
+	logger.Info("Watching config for changes", "path", configPath)
 	return nil
 }
 
@@ -132,7 +128,7 @@ func (m *Manager) watchLoop(ctx context.Context, configPath string) {
 
 			// Only react to Write and Create events (ignore Chmod, Remove, etc.)
 			if event.Op&fsnotify.Write == fsnotify.Write || event.Op&fsnotify.Create == fsnotify.Create {
-				log.Printf("Config manager: file change detected: %s. Debouncing reload...", event.Name)
+				logger.Debug("Config file changed, debouncing reload", "path", event.Name)
 				m.debounceReloadConfig()
 			}
 
@@ -140,7 +136,7 @@ func (m *Manager) watchLoop(ctx context.Context, configPath string) {
 			if !ok {
 				return
 			}
-			log.Printf("Config watcher error: %v", err)
+			logger.Warn("Config watcher error", "err", err)
 
 		case <-ctx.Done():
 			return
@@ -149,22 +145,19 @@ func (m *Manager) watchLoop(ctx context.Context, configPath string) {
 }
 
 func (m *Manager) reloadConfig() {
-	log.Printf("Config manager: starting configuration reload...")
-
 	newConfig, legacy, err := LoadOrLegacy()
 	if err != nil {
-		log.Printf("Config manager: failed to reload config: %v", err)
+		logger.Error("Failed to reload config", "err", err)
 		return
 	}
 
 	if legacy {
-		log.Printf("Config manager: config still in legacy format, skipping reload")
+		logger.Warn("Config still in legacy format, skipping reload")
 		return
 	}
 
-	log.Printf("Config manager: validating new configuration...")
 	if err := newConfig.Validate(); err != nil {
-		log.Printf("Config manager: invalid config after reload: %v", err)
+		logger.Error("Invalid config after reload, keeping the previous one", "err", err)
 		return
 	}
 
@@ -178,7 +171,7 @@ func (m *Manager) reloadConfig() {
 		onConfigReload()
 	}
 
-	log.Printf("Config manager: configuration successfully reloaded")
+	logger.Info("Configuration reloaded")
 }
 
 func (m *Manager) SetOnConfigReload(onConfigReload func()) {
@@ -199,7 +192,6 @@ func (m *Manager) debounceReloadConfig() {
 
 	// Create new timer with debounce delay
 	m.debounceTimer = time.AfterFunc(m.debounceDelay, func() {
-		log.Printf("Config manager: debounce period expired, reloading config...")
 		m.reloadConfig()
 	})
 }
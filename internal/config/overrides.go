@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leonardotrapani/hyprvoice/internal/provider"
+)
+
+// Override keys accepted by hyprvoice set and the toggle/start flags
+const (
+	OverrideProfile  = "profile"  // [profiles.<name>] applied before the other keys
+	OverrideLanguage = "language" // language code, "auto" for auto-detect
+	OverrideModel    = "model"    // transcription model, switches provider if needed
+	OverrideLLM      = "llm"      // "on" or "off"
+	OverrideBackend  = "backend"  // comma-separated injection backends
+)
+
+// OverrideKeys lists the override keys in display order
+var OverrideKeys = []string{OverrideProfile, OverrideLanguage, OverrideModel, OverrideLLM, OverrideBackend}
+
+// Overrides are runtime changes applied on top of config.toml without
+// writing it, keyed by OverrideKeys. They travel as request args over the
+// control socket. A missing key leaves the configured value alone.
+type Overrides map[string]string
+
+// ParseOverrides checks that args only holds known override keys with
+// valid values. Empty values are kept so callers can use them to clear a key.
+func ParseOverrides(args map[string]string) (Overrides, error) {
+	o := Overrides{}
+	for key, value := range args {
+		value = strings.TrimSpace(value)
+		switch key {
+		case OverrideProfile, OverrideLanguage, OverrideModel, OverrideBackend:
+		case OverrideLLM:
+			if value != "" && value != "on" && value != "off" {
+				return nil, fmt.Errorf("invalid llm override: %q (must be on or off)", value)
+			}
+		default:
+			return nil, fmt.Errorf("unknown override: %s (must be one of %s)", key, strings.Join(OverrideKeys, ", "))
+		}
+		o[key] = value
+	}
+	return o, nil
+}
+
+// Merge returns o with the non-empty values of other on top
+func (o Overrides) Merge(other Overrides) Overrides {
+	merged := Overrides{}
+	for key, value := range o {
+		merged[key] = value
+	}
+	for key, value := range other {
+		if value != "" {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// WithOverrides returns a copy of c with o applied: the profile first, then
+// the other keys. The result is validated so a bad override fails before a
+// dictation starts.
+func (c *Config) WithOverrides(o Overrides) (*Config, error) {
+	cfg := *c
+	if len(o) == 0 {
+		return &cfg, nil
+	}
+
+	if name := o[OverrideProfile]; name != "" {
+		profiled, err := c.WithProfile(name)
+		if err != nil {
+			return nil, err
+		}
+		cfg = *profiled
+	}
+
+	if language, ok := o[OverrideLanguage]; ok && language != "" {
+		if language == "auto" {
+			language = ""
+		}
+		cfg.Transcription.Language = language
+	}
+
+	if model := o[OverrideModel]; model != "" {
+		providerName, err := findTranscriptionProvider(cfg.Transcription.Provider, model)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Transcription.Provider = providerName
+		cfg.Transcription.Model = model
+	}
+
+	switch o[OverrideLLM] {
+	case "on":
+		cfg.LLM.Enabled = true
+	case "off":
+		cfg.LLM.Enabled = false
+	}
+
+	if backend := o[OverrideBackend]; backend != "" {
+		var backends []string
+		for _, b := range strings.Split(backend, ",") {
+			if b = strings.TrimSpace(b); b != "" {
+				backends = append(backends, b)
+			}
+		}
+		cfg.Injection.Backends = backends
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid override: %w", err)
+	}
+	return &cfg, nil
+}
+
+// findTranscriptionProvider returns the transcription.provider value that
+// offers model, preferring the configured provider
+func findTranscriptionProvider(current, model string) (string, error) {
+	if m, err := provider.GetModel(provider.BaseProviderName(current), model); err == nil && m.Type == provider.Transcription {
+		return current, nil
+	}
+
+	names := provider.ListProvidersWithTranscription()
+	sort.Strings(names)
+	for _, name := range names {
+		if m, err := provider.GetModel(name, model); err == nil && m.Type == provider.Transcription {
+			return provider.ConfigProviderName(name), nil
+		}
+	}
+	return "", fmt.Errorf("unknown transcription model: %s", model)
+}
+
+// WithTranscriptionProvider returns a copy of c transcribing with the named
+// provider. The configured model is kept when the provider offers it,
+// otherwise the provider's default model is used, and the streaming mode
+// follows what that model supports.
+func (c *Config) WithTranscriptionProvider(name string) (*Config, error) {
+	base := provider.BaseProviderName(name)
+	p := provider.GetProvider(base)
+	if p == nil || len(provider.ModelsOfType(p, provider.Transcription)) == 0 {
+		names := provider.ListProvidersWithTranscription()
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown transcription provider: %s (available: %s)", name, strings.Join(names, ", "))
+	}
+
+	cfg := *c
+	cfg.Transcription.Provider = provider.ConfigProviderName(base)
+	model, err := provider.GetModel(base, cfg.Transcription.Model)
+	if err != nil || model.Type != provider.Transcription {
+		cfg.Transcription.Model = p.DefaultModel(provider.Transcription)
+		model, err = provider.GetModel(base, cfg.Transcription.Model)
+	}
+	if err == nil {
+		if cfg.Transcription.Streaming && !model.SupportsStreaming {
+			cfg.Transcription.Streaming = false
+		} else if !cfg.Transcription.Streaming && !model.SupportsBatch {
+			cfg.Transcription.Streaming = true
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid override: %w", err)
+	}
+	return &cfg, nil
+}
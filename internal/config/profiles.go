@@ -0,0 +1,94 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Profile is a raw [profiles.<name>] table. It uses the same layout as the
+// config file and overrides any part of it, e.g.
+//
+//	[profiles.email.transcription]
+//	  language = "de"
+//	[profiles.email.llm.custom_prompt]
+//	  enabled = true
+//	  prompt = "Write a polite email."
+type Profile map[string]interface{}
+
+// ProfileNames returns the configured profile names in sorted order
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithProfile returns a copy of c with the named profile applied on top.
+// The result is not validated.
+func (c *Config) WithProfile(name string) (*Config, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return nil, fmt.Errorf("unknown profile: %s (no profiles configured)", name)
+		}
+		return nil, fmt.Errorf("unknown profile: %s (available: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	if _, nested := profile["profiles"]; nested {
+		return nil, fmt.Errorf("invalid profile %s: profiles cannot be nested", name)
+	}
+	if _, rules := profile["window_rules"]; rules {
+		return nil, fmt.Errorf("invalid profile %s: window_rules cannot be set in a profile", name)
+	}
+
+	// re-encode the table and decode it over a copy, so only the keys the
+	// profile sets change
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(profile); err != nil {
+		return nil, fmt.Errorf("failed to encode profile %s: %w", name, err)
+	}
+
+	cfg := *c
+	// decoding fills maps and slices in place, keep the originals intact
+	cfg.Providers = make(map[string]ProviderConfig, len(c.Providers))
+	for k, v := range c.Providers {
+		cfg.Providers[k] = v
+	}
+	cfg.Keywords = append([]string(nil), c.Keywords...)
+	cfg.Injection.Backends = append([]string(nil), c.Injection.Backends...)
+
+	meta, err := toml.Decode(buf.String(), &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid profile %s: %w", name, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+		return nil, fmt.Errorf("invalid profile %s: unknown keys %s", name, strings.Join(keys, ", "))
+	}
+	return &cfg, nil
+}
+
+// validateProfiles checks that every profile applies cleanly and yields a
+// valid config
+func (c *Config) validateProfiles() error {
+	for _, name := range c.ProfileNames() {
+		cfg, err := c.WithProfile(name)
+		if err != nil {
+			return err
+		}
+		cfg.Profiles = nil
+		cfg.WindowRules = nil
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid profile %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// WindowRule selects a profile when a dictation starts while a matching
+// Hyprland window is focused. Class and Title are regular expressions
+// matched anywhere in the window class and title; a rule with both set
+// needs both to match.
+type WindowRule struct {
+	Class   string `toml:"class"`
+	Title   string `toml:"title"`
+	Profile string `toml:"profile"`
+
+	// compiled by Validate
+	class, title *regexp.Regexp
+}
+
+// Matches reports whether the rule applies to a window with class and title.
+// Rules of a config that was not validated match nothing.
+func (r WindowRule) Matches(class, title string) bool {
+	if r.class == nil && r.title == nil {
+		return false
+	}
+	if r.class != nil && !r.class.MatchString(class) {
+		return false
+	}
+	if r.title != nil && !r.title.MatchString(title) {
+		return false
+	}
+	return true
+}
+
+// WindowProfile returns the profile of the first window rule matching class
+// and title, or "" when none does
+func (c *Config) WindowProfile(class, title string) string {
+	for _, rule := range c.WindowRules {
+		if rule.Matches(class, title) {
+			return rule.Profile
+		}
+	}
+	return ""
+}
+
+// validateWindowRules checks the rules and compiles their patterns. The
+// compiled rules go to a new slice, since copies of a config share the old one.
+func (c *Config) validateWindowRules() error {
+	if len(c.WindowRules) == 0 {
+		return nil
+	}
+	rules := make([]WindowRule, len(c.WindowRules))
+	for i, rule := range c.WindowRules {
+		if rule.Class == "" && rule.Title == "" {
+			return fmt.Errorf("invalid window_rules[%d]: class or title required", i)
+		}
+		var err error
+		if rule.class, err = compilePattern(rule.Class); err != nil {
+			return fmt.Errorf("invalid window_rules[%d].class: %w", i, err)
+		}
+		if rule.title, err = compilePattern(rule.Title); err != nil {
+			return fmt.Errorf("invalid window_rules[%d].title: %w", i, err)
+		}
+		if _, ok := c.Profiles[rule.Profile]; !ok {
+			if rule.Profile == "" {
+				return fmt.Errorf("invalid window_rules[%d]: profile required", i)
+			}
+			return fmt.Errorf("invalid window_rules[%d]: unknown profile %s", i, rule.Profile)
+		}
+		rules[i] = rule
+	}
+	c.WindowRules = rules
+	return nil
+}
+
+// compilePattern compiles a window rule pattern; an empty one matches anything
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile(pattern)
+}
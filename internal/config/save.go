@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/BurntSushi/toml"
 )
 
 // Save writes the config to the config file with formatted TOML output
@@ -59,9 +61,24 @@ func Save(cfg *Config) error {
 	sb.WriteString(fmt.Sprintf("  channels = %d\n", cfg.Recording.Channels))
 	sb.WriteString(fmt.Sprintf("  format = %q\n", cfg.Recording.Format))
 	sb.WriteString(fmt.Sprintf("  buffer_size = %d\n", cfg.Recording.BufferSize))
+	sb.WriteString(fmt.Sprintf("  backend = %q\n", cfg.Recording.Backend))
 	sb.WriteString(fmt.Sprintf("  device = %q\n", cfg.Recording.Device))
+	sb.WriteString(fmt.Sprintf("  file = %q\n", cfg.Recording.File))
 	sb.WriteString(fmt.Sprintf("  channel_buffer_size = %d\n", cfg.Recording.ChannelBufferSize))
 	sb.WriteString(fmt.Sprintf("  timeout = %q\n", cfg.Recording.Timeout.String()))
+	sb.WriteString(fmt.Sprintf("  timeout_warning = %q\n", cfg.Recording.TimeoutWarning.String()))
+	sb.WriteString(fmt.Sprintf("  on_timeout = %q\n", cfg.Recording.OnTimeout))
+	sb.WriteString(fmt.Sprintf("  on_shutdown = %q\n", cfg.Recording.OnShutdown))
+	sb.WriteString("\n")
+	sb.WriteString("[recording.vad]\n")
+	sb.WriteString(fmt.Sprintf("  enabled = %v\n", cfg.Recording.VAD.Enabled))
+	sb.WriteString(fmt.Sprintf("  threshold = %v\n", cfg.Recording.VAD.Threshold))
+	sb.WriteString(fmt.Sprintf("  silence = %q\n", cfg.Recording.VAD.Silence.String()))
+	sb.WriteString(fmt.Sprintf("  min_speech = %q\n", cfg.Recording.VAD.MinSpeech.String()))
+	sb.WriteString("\n")
+	sb.WriteString("[recording.warm]\n")
+	sb.WriteString(fmt.Sprintf("  enabled = %v\n", cfg.Recording.Warm.Enabled))
+	sb.WriteString(fmt.Sprintf("  pre_roll = %q\n", cfg.Recording.Warm.PreRoll.String()))
 	sb.WriteString("\n")
 
 	// Transcription
@@ -74,6 +91,12 @@ func Save(cfg *Config) error {
 	sb.WriteString(fmt.Sprintf("  streaming = %v\n", cfg.Transcription.Streaming))
 	sb.WriteString(fmt.Sprintf("  threads = %d\n", cfg.Transcription.Threads))
 	sb.WriteString("\n")
+	sb.WriteString("[transcription.trim]\n")
+	sb.WriteString(fmt.Sprintf("  enabled = %v\n", cfg.Transcription.Trim.Enabled))
+	sb.WriteString(fmt.Sprintf("  threshold = %v\n", cfg.Transcription.Trim.Threshold))
+	sb.WriteString(fmt.Sprintf("  margin = %q\n", cfg.Transcription.Trim.Margin.String()))
+	sb.WriteString(fmt.Sprintf("  max_pause = %q\n", cfg.Transcription.Trim.MaxPause.String()))
+	sb.WriteString("\n")
 
 	// LLM
 	sb.WriteString(`# LLM Post-Processing Configuration
@@ -119,6 +142,35 @@ func Save(cfg *Config) error {
 	sb.WriteString(fmt.Sprintf("  clipboard_timeout = %q\n", cfg.Injection.ClipboardTimeout.String()))
 	sb.WriteString("\n")
 
+	// History
+	sb.WriteString(`# Dictation History Configuration
+[history]
+`)
+	sb.WriteString(fmt.Sprintf("  enabled = %v\n", cfg.History.Enabled))
+	sb.WriteString(fmt.Sprintf("  max_entries = %d\n", cfg.History.MaxEntries))
+	sb.WriteString(fmt.Sprintf("  max_age = %q\n", cfg.History.MaxAge.String()))
+	sb.WriteString(fmt.Sprintf("  encrypt = %v\n", cfg.History.Encrypt))
+	sb.WriteString("\n")
+
+	// Stats
+	sb.WriteString(`# Latency and Usage Statistics
+[stats]
+`)
+	sb.WriteString(fmt.Sprintf("  enabled = %v\n", cfg.Stats.Enabled))
+	sb.WriteString(fmt.Sprintf("  max_entries = %d\n", cfg.Stats.MaxEntries))
+	sb.WriteString(fmt.Sprintf("  max_age = %q\n", cfg.Stats.MaxAge.String()))
+	sb.WriteString("\n")
+
+	// Logging
+	sb.WriteString(`# Daemon Logging
+[logging]
+`)
+	sb.WriteString(fmt.Sprintf("  level = %q\n", cfg.Logging.Level))
+	sb.WriteString(fmt.Sprintf("  file = %v\n", cfg.Logging.File))
+	sb.WriteString(fmt.Sprintf("  max_size_mb = %d\n", cfg.Logging.MaxSizeMB))
+	sb.WriteString(fmt.Sprintf("  max_files = %d\n", cfg.Logging.MaxFiles))
+	sb.WriteString("\n")
+
 	// Notifications
 	sb.WriteString(`# Desktop Notification Configuration
 [notifications]
@@ -163,6 +215,50 @@ func Save(cfg *Config) error {
 			sb.WriteString("    [notifications.messages.injection_aborted]\n")
 			sb.WriteString(fmt.Sprintf("      body = %q\n", msgs.InjectionAborted.Body))
 		}
+		if msgs.TimeoutWarning.Title != "" || msgs.TimeoutWarning.Body != "" {
+			sb.WriteString("    [notifications.messages.timeout_warning]\n")
+			sb.WriteString(fmt.Sprintf("      title = %q\n", msgs.TimeoutWarning.Title))
+			sb.WriteString(fmt.Sprintf("      body = %q\n", msgs.TimeoutWarning.Body))
+		}
+		if msgs.DictationSaved.Title != "" || msgs.DictationSaved.Body != "" {
+			sb.WriteString("    [notifications.messages.dictation_saved]\n")
+			sb.WriteString(fmt.Sprintf("      title = %q\n", msgs.DictationSaved.Title))
+			sb.WriteString(fmt.Sprintf("      body = %q\n", msgs.DictationSaved.Body))
+		}
+		if msgs.MicSilent.Title != "" || msgs.MicSilent.Body != "" {
+			sb.WriteString("    [notifications.messages.mic_silent]\n")
+			sb.WriteString(fmt.Sprintf("      title = %q\n", msgs.MicSilent.Title))
+			sb.WriteString(fmt.Sprintf("      body = %q\n", msgs.MicSilent.Body))
+		}
+	}
+
+	// Profiles
+	if len(cfg.Profiles) > 0 {
+		sb.WriteString(`
+# Profiles (select with: hyprvoice toggle --profile <name> or hyprvoice profile use <name>)
+`)
+		enc := toml.NewEncoder(&sb)
+		enc.Indent = "  "
+		if err := enc.Encode(map[string]interface{}{"profiles": cfg.Profiles}); err != nil {
+			return fmt.Errorf("failed to encode profiles: %w", err)
+		}
+	}
+
+	// Window rules
+	if len(cfg.WindowRules) > 0 {
+		sb.WriteString(`
+# Window Rules (first rule matching the focused Hyprland window picks the profile)
+`)
+		for _, rule := range cfg.WindowRules {
+			sb.WriteString("[[window_rules]]\n")
+			if rule.Class != "" {
+				sb.WriteString(fmt.Sprintf("  class = %q\n", rule.Class))
+			}
+			if rule.Title != "" {
+				sb.WriteString(fmt.Sprintf("  title = %q\n", rule.Title))
+			}
+			sb.WriteString(fmt.Sprintf("  profile = %q\n", rule.Profile))
+		}
 	}
 
 	if _, err := file.WriteString(sb.String()); err != nil {
@@ -179,7 +275,10 @@ func hasCustomMessages(msgs MessagesConfig) bool {
 		msgs.ConfigReloaded.Title != "" || msgs.ConfigReloaded.Body != "" ||
 		msgs.OperationCancelled.Title != "" || msgs.OperationCancelled.Body != "" ||
 		msgs.RecordingAborted.Body != "" ||
-		msgs.InjectionAborted.Body != ""
+		msgs.InjectionAborted.Body != "" ||
+		msgs.TimeoutWarning.Title != "" || msgs.TimeoutWarning.Body != "" ||
+		msgs.DictationSaved.Title != "" || msgs.DictationSaved.Body != "" ||
+		msgs.MicSilent.Title != "" || msgs.MicSilent.Body != ""
 }
 
 // SaveDefaultConfig writes the default config template to the config file
@@ -231,9 +330,31 @@ keywords = []
   channels = 1                 # Number of audio channels (1 = mono, 2 = stereo)
   format = "s16"               # Audio format (s16 = 16-bit signed integers)
   buffer_size = 8192           # Internal buffer size in bytes (larger = less CPU, more latency)
-  device = ""                  # PipeWire audio device (empty = use default microphone)
+  backend = "pw-record"        # Capture: "pw-record" (PipeWire), "parec" (PulseAudio), "arecord" (ALSA), "ffmpeg" (ffmpeg -f pulse), "file"
+  device = ""                  # Audio device for the backend (empty = use default microphone)
+  # file = "test.wav"          # With backend = "file": WAV file played as the microphone ("-" = raw PCM on stdin)
   channel_buffer_size = 30     # Audio frame buffer size (frames to buffer)
   timeout = "5m"               # Maximum recording duration (e.g., "30s", "2m", "5m")
+  timeout_warning = "30s"      # Notify this long before the timeout ("0s" = no warning)
+  on_timeout = "save"          # At the timeout: "inject", "save" (to recovery file), or "discard"
+  on_shutdown = "save"         # When the daemon stops mid-recording: "inject", "save", or "discard"
+
+# Voice activity detection: stop on its own after you stop talking, like a
+# second toggle (finalize and inject)
+[recording.vad]
+  enabled = false              # Stop the recording after trailing silence
+  threshold = 0.01             # RMS level (0-1) that counts as speech (0.01 is about -40 dBFS)
+  silence = "1.5s"             # Silence after speech that stops the recording
+  min_speech = "300ms"         # Speech needed first, so a click or cough does not stop it
+
+# Warm recorder: keep the capture running while idle so recordings start
+# instantly and keep the first syllable. Privacy: the microphone stays open
+# (and shows as in use) the whole time the daemon runs. Only the last
+# pre_roll of audio is held in memory; nothing is written or sent until you
+# start a recording.
+[recording.warm]
+  enabled = false              # Keep the microphone open while idle
+  pre_roll = "300ms"           # Audio from before the toggle added to each recording (max 5s)
 
 # ─────────────────────────────────────────────────────────────────────────────
 # Speech Transcription
@@ -246,6 +367,14 @@ keywords = []
   language = ""                # ISO 639-1 code (e.g., en, es, de). Empty for auto-detect.
   threads = 0                  # CPU threads for local transcription (0 = auto: uses NumCPU-1)
 
+# Cut silence before batch transcription: smaller uploads, faster whisper-cpp
+# and fewer hallucinated phrases on silent tails (not used when streaming)
+[transcription.trim]
+  enabled = true               # Cut leading and trailing silence
+  threshold = 0.01             # RMS level (0-1) that counts as speech
+  margin = "300ms"             # Audio kept around speech so words are not clipped
+  max_pause = "0s"             # Shorten longer pauses to this ("0s" = keep pauses)
+
 # ─────────────────────────────────────────────────────────────────────────────
 # LLM Post-Processing (Recommended)
 # Cleans up transcribed text: removes stutters, adds punctuation, fixes grammar
@@ -277,6 +406,43 @@ keywords = []
   wtype_timeout = "5s"         # Timeout for wtype commands
   clipboard_timeout = "3s"     # Timeout for clipboard operations
 
+# ─────────────────────────────────────────────────────────────────────────────
+# Dictation History
+# Every dictation is saved to ~/.local/share/hyprvoice/history.jsonl
+# Browse it with: hyprvoice history list|show|search|export
+# ─────────────────────────────────────────────────────────────────────────────
+
+[history]
+  enabled = true               # Save transcripts, LLM output, timings and errors
+  max_entries = 1000           # Keep at most this many entries (0 = unlimited)
+  max_age = "0s"               # Drop entries older than this (e.g., "720h"; "0s" = keep forever)
+  encrypt = false              # Encrypt entries at rest (key stored in ~/.config/hyprvoice/history.key)
+
+# ─────────────────────────────────────────────────────────────────────────────
+# Latency and Usage Statistics
+# Stage timings, word counts and provider/model of every dictation (no text)
+# are saved to ~/.local/share/hyprvoice/stats.jsonl
+# View them with: hyprvoice stats
+# ─────────────────────────────────────────────────────────────────────────────
+
+[stats]
+  enabled = true               # Record per-stage latency and usage
+  max_entries = 10000          # Keep at most this many records (0 = unlimited)
+  max_age = "0s"               # Drop records older than this (e.g., "2160h"; "0s" = keep forever)
+
+# ─────────────────────────────────────────────────────────────────────────────
+# Daemon Logging
+# Logs go to stderr (the journal when run as a service). Transcripts and LLM
+# output are only logged at level "debug"; other levels log their length.
+# Follow the log file with: hyprvoice logs --follow
+# ─────────────────────────────────────────────────────────────────────────────
+
+[logging]
+  level = "info"               # "debug", "info", "warn", or "error"
+  file = false                 # Also log to ~/.local/state/hyprvoice/hyprvoice.log
+  max_size_mb = 10             # Rotate the log file at this size
+  max_files = 3                # Rotated log files to keep
+
 # ─────────────────────────────────────────────────────────────────────────────
 # Desktop Notifications
 # ─────────────────────────────────────────────────────────────────────────────
@@ -307,12 +473,55 @@ keywords = []
   #     body = "Recording Aborted"
   #   [notifications.messages.injection_aborted]
   #     body = "Injection Aborted"
+  #   [notifications.messages.timeout_warning]
+  #     title = "Hyprvoice"
+  #     body = "Recording stops soon"
+  #   [notifications.messages.dictation_saved]
+  #     title = "Hyprvoice"
+  #     body = "Dictation saved to recovery file"
+  #   [notifications.messages.mic_silent]
+  #     title = "Hyprvoice"
+  #     body = "No sound from the microphone, is it muted?"
   #
   # Emoji-only example (for minimal pill-style notifications):
   #   [notifications.messages.recording_started]
   #     title = ""
   #     body = "..."
 
+# ─────────────────────────────────────────────────────────────────────────────
+# Profiles
+# Named overrides for any section above, selected per dictation with
+# hyprvoice toggle --profile <name> or until restart with hyprvoice profile use <name>
+# ─────────────────────────────────────────────────────────────────────────────
+
+# [profiles.email]
+#   keywords = ["Hyprvoice"]
+#   [profiles.email.transcription]
+#     language = "de"
+#   [profiles.email.llm.custom_prompt]
+#     enabled = true
+#     prompt = "Format the text as a polite email."
+#
+# [profiles.code]
+#   [profiles.code.llm]
+#     enabled = false
+#   [profiles.code.injection]
+#     backends = ["clipboard"]
+
+# Window Rules
+# Pick a profile automatically from the window focused when recording starts
+# (Hyprland only). class and title are regular expressions, the first
+# matching rule wins. --profile and "hyprvoice profile use" take precedence.
+#
+# [[window_rules]]
+#   class = "^(kitty|foot|Alacritty)$"
+#   profile = "code"
+#
+# [[window_rules]]
+#   class = "^thunderbird$"
+#   title = "Write:"
+#   profile = "email"
+
 # ─────────────────────────────────────────────────────────────────────────────
 # Reference: Provider Details
 # ─────────────────────────────────────────────────────────────────────────────
@@ -21,6 +21,11 @@ type Config struct {
 	Providers     map[string]ProviderConfig `toml:"providers"`
 	Keywords      []string                  `toml:"keywords"`
 	LLM           LLMConfig                 `toml:"llm"`
+	History       HistoryConfig             `toml:"history"`
+	Stats         StatsConfig               `toml:"stats"`
+	Logging       LoggingConfig             `toml:"logging"`
+	Profiles      map[string]Profile        `toml:"profiles"`
+	WindowRules   []WindowRule              `toml:"window_rules"`
 }
 
 // ProviderConfig holds API key for a provider
@@ -56,17 +61,48 @@ type RecordingConfig struct {
 	Channels          int           `toml:"channels"`
 	Format            string        `toml:"format"`
 	BufferSize        int           `toml:"buffer_size"`
+	Backend           string        `toml:"backend"` // "pw-record", "parec", "arecord", "ffmpeg", "file"
 	Device            string        `toml:"device"`
+	File              string        `toml:"file"` // audio file for the file backend ("-" = raw PCM on stdin)
 	ChannelBufferSize int           `toml:"channel_buffer_size"`
 	Timeout           time.Duration `toml:"timeout"`
+	TimeoutWarning    time.Duration `toml:"timeout_warning"` // notify this long before the timeout (0 = off)
+	OnTimeout         string        `toml:"on_timeout"`      // "inject", "save", "discard"
+	OnShutdown        string        `toml:"on_shutdown"`     // "inject", "save", "discard"
+	VAD               VADConfig     `toml:"vad"`
+	Warm              WarmConfig    `toml:"warm"`
+}
+
+// VADConfig stops a recording on its own after speech followed by silence
+type VADConfig struct {
+	Enabled   bool          `toml:"enabled"`
+	Threshold float64       `toml:"threshold"`  // RMS level (0-1) that counts as speech
+	Silence   time.Duration `toml:"silence"`    // trailing silence that stops the recording
+	MinSpeech time.Duration `toml:"min_speech"` // speech needed before silence can stop it
+}
+
+// WarmConfig keeps the microphone open while idle so recordings start
+// instantly and include the audio from just before the toggle
+type WarmConfig struct {
+	Enabled bool          `toml:"enabled"`
+	PreRoll time.Duration `toml:"pre_roll"` // audio from before the toggle prepended to recordings
 }
 
 type TranscriptionConfig struct {
-	Provider  string `toml:"provider"`
-	Language  string `toml:"language"`
-	Model     string `toml:"model"`
-	Streaming bool   `toml:"streaming"` // use streaming mode if model supports it
-	Threads   int    `toml:"threads"`   // CPU threads for local transcription (0 = auto: NumCPU-1)
+	Provider  string     `toml:"provider"`
+	Language  string     `toml:"language"`
+	Model     string     `toml:"model"`
+	Streaming bool       `toml:"streaming"` // use streaming mode if model supports it
+	Threads   int        `toml:"threads"`   // CPU threads for local transcription (0 = auto: NumCPU-1)
+	Trim      TrimConfig `toml:"trim"`
+}
+
+// TrimConfig cuts silence from recordings before batch transcription
+type TrimConfig struct {
+	Enabled   bool          `toml:"enabled"`
+	Threshold float64       `toml:"threshold"` // RMS level (0-1) that counts as speech
+	Margin    time.Duration `toml:"margin"`    // audio kept around speech
+	MaxPause  time.Duration `toml:"max_pause"` // shorten longer pauses to this ("0s" = keep pauses)
 }
 
 type InjectionConfig struct {
@@ -76,6 +112,29 @@ type InjectionConfig struct {
 	ClipboardTimeout time.Duration `toml:"clipboard_timeout"`
 }
 
+// HistoryConfig controls the local dictation history
+type HistoryConfig struct {
+	Enabled    bool          `toml:"enabled"`
+	MaxEntries int           `toml:"max_entries"` // 0 = unlimited
+	MaxAge     time.Duration `toml:"max_age"`     // 0 = keep forever
+	Encrypt    bool          `toml:"encrypt"`     // AES-GCM with a key stored in the config directory
+}
+
+// StatsConfig controls the local latency and usage statistics
+type StatsConfig struct {
+	Enabled    bool          `toml:"enabled"`
+	MaxEntries int           `toml:"max_entries"` // 0 = unlimited
+	MaxAge     time.Duration `toml:"max_age"`     // 0 = keep forever
+}
+
+// LoggingConfig controls the daemon log level and the optional log file
+type LoggingConfig struct {
+	Level     string `toml:"level"`       // "debug", "info", "warn", "error"; debug also logs dictated text
+	File      bool   `toml:"file"`        // also log to ~/.local/state/hyprvoice/hyprvoice.log
+	MaxSizeMB int    `toml:"max_size_mb"` // rotate the log file at this size
+	MaxFiles  int    `toml:"max_files"`   // rotated log files to keep
+}
+
 type NotificationsConfig struct {
 	Enabled  bool           `toml:"enabled"`
 	Type     string         `toml:"type"` // "desktop", "log", "none"
@@ -95,6 +154,9 @@ type MessagesConfig struct {
 	OperationCancelled MessageConfig `toml:"operation_cancelled"`
 	RecordingAborted   MessageConfig `toml:"recording_aborted"`
 	InjectionAborted   MessageConfig `toml:"injection_aborted"`
+	TimeoutWarning     MessageConfig `toml:"timeout_warning"`
+	DictationSaved     MessageConfig `toml:"dictation_saved"`
+	MicSilent          MessageConfig `toml:"mic_silent"`
 }
 
 // Resolve merges user config with defaults from MessageDefs
@@ -2,9 +2,12 @@ package config
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 	"github.com/leonardotrapani/hyprvoice/internal/provider"
+	"github.com/leonardotrapani/hyprvoice/internal/recording"
 )
 
 // mapConfigProviderToRegistryName maps config provider names to provider registry names
@@ -55,9 +58,46 @@ func (c *Config) Validate() error {
 	if c.Recording.Format == "" {
 		return fmt.Errorf("invalid recording.format: empty")
 	}
+	// an empty backend is filled in with the default on load
+	if backend := c.Recording.Backend; backend != "" && !slices.Contains(recording.Backends, backend) {
+		return fmt.Errorf("invalid recording.backend: %q (must be %s)", backend, strings.Join(recording.Backends, ", "))
+	}
+	if c.Recording.Backend == recording.BackendFile && c.Recording.File == "" {
+		return fmt.Errorf("recording.backend = \"file\" requires recording.file (a WAV file, or \"-\" for stdin)")
+	}
 	if c.Recording.Timeout <= 0 {
 		return fmt.Errorf("invalid recording.timeout: %v", c.Recording.Timeout)
 	}
+	if c.Recording.TimeoutWarning < 0 {
+		return fmt.Errorf("invalid recording.timeout_warning: %v", c.Recording.TimeoutWarning)
+	}
+	// empty policies are filled in with their defaults on load
+	validPolicies := map[string]bool{"": true, PolicyInject: true, PolicySave: true, PolicyDiscard: true}
+	if !validPolicies[c.Recording.OnTimeout] {
+		return fmt.Errorf("invalid recording.on_timeout: %q (must be inject, save, or discard)", c.Recording.OnTimeout)
+	}
+	if !validPolicies[c.Recording.OnShutdown] {
+		return fmt.Errorf("invalid recording.on_shutdown: %q (must be inject, save, or discard)", c.Recording.OnShutdown)
+	}
+	if vad := c.Recording.VAD; vad.Enabled {
+		if vad.Threshold <= 0 || vad.Threshold > 1 {
+			return fmt.Errorf("invalid recording.vad.threshold: %v (must be between 0 and 1)", vad.Threshold)
+		}
+		if vad.Silence <= 0 {
+			return fmt.Errorf("invalid recording.vad.silence: %v", vad.Silence)
+		}
+		if vad.MinSpeech < 0 {
+			return fmt.Errorf("invalid recording.vad.min_speech: %v", vad.MinSpeech)
+		}
+		if c.Recording.Format != "s16" {
+			return fmt.Errorf("recording.vad requires recording.format = \"s16\"")
+		}
+	}
+	if warm := c.Recording.Warm; warm.Enabled {
+		if warm.PreRoll < 0 || warm.PreRoll > recording.MaxPreRoll {
+			return fmt.Errorf("invalid recording.warm.pre_roll: %v (must be between 0s and %v)", warm.PreRoll, recording.MaxPreRoll)
+		}
+	}
 
 	if c.Transcription.Provider == "" {
 		return fmt.Errorf("invalid transcription.provider: empty")
@@ -105,6 +145,18 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	if trim := c.Transcription.Trim; trim.Enabled {
+		if trim.Threshold <= 0 || trim.Threshold > 1 {
+			return fmt.Errorf("invalid transcription.trim.threshold: %v (must be between 0 and 1)", trim.Threshold)
+		}
+		if trim.Margin < 0 {
+			return fmt.Errorf("invalid transcription.trim.margin: %v", trim.Margin)
+		}
+		if trim.MaxPause < 0 {
+			return fmt.Errorf("invalid transcription.trim.max_pause: %v", trim.MaxPause)
+		}
+	}
+
 	// LLM validation
 	if c.LLM.Enabled {
 		if c.LLM.Provider == "" {
@@ -167,12 +219,38 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid injection.clipboard_timeout: %v", c.Injection.ClipboardTimeout)
 	}
 
+	if c.History.MaxEntries < 0 {
+		return fmt.Errorf("invalid history.max_entries: %d", c.History.MaxEntries)
+	}
+	if c.History.MaxAge < 0 {
+		return fmt.Errorf("invalid history.max_age: %v", c.History.MaxAge)
+	}
+	if c.Stats.MaxEntries < 0 {
+		return fmt.Errorf("invalid stats.max_entries: %d", c.Stats.MaxEntries)
+	}
+	if c.Stats.MaxAge < 0 {
+		return fmt.Errorf("invalid stats.max_age: %v", c.Stats.MaxAge)
+	}
+
+	if _, err := logging.ParseLevel(c.Logging.Level); err != nil {
+		return fmt.Errorf("invalid logging.level: %s (must be debug, info, warn, or error)", c.Logging.Level)
+	}
+	if c.Logging.MaxSizeMB < 0 {
+		return fmt.Errorf("invalid logging.max_size_mb: %d", c.Logging.MaxSizeMB)
+	}
+	if c.Logging.MaxFiles < 0 {
+		return fmt.Errorf("invalid logging.max_files: %d", c.Logging.MaxFiles)
+	}
+
 	validTypes := map[string]bool{"desktop": true, "log": true, "none": true}
 	if !validTypes[c.Notifications.Type] {
 		return fmt.Errorf("invalid notifications.type: %s (must be desktop, log, or none)", c.Notifications.Type)
 	}
 
-	return nil
+	if err := c.validateWindowRules(); err != nil {
+		return err
+	}
+	return c.validateProfiles()
 }
 
 // ValidateModelLanguageCompatibility validates that a model supports the given language.
@@ -4,19 +4,34 @@ import (
 	"bufio"
 	"context"
 	"fmt"
-	"log"
+	"io"
 	"net"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/leonardotrapani/hyprvoice/internal/bus"
 	"github.com/leonardotrapani/hyprvoice/internal/config"
+	"github.com/leonardotrapani/hyprvoice/internal/hyprland"
+	"github.com/leonardotrapani/hyprvoice/internal/injection"
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 	"github.com/leonardotrapani/hyprvoice/internal/notify"
 	"github.com/leonardotrapani/hyprvoice/internal/pipeline"
+	"github.com/leonardotrapani/hyprvoice/internal/recording"
 )
 
+var logger = logging.For("daemon")
+
+// Version is the daemon build version, set at build time via -ldflags
+var Version = "dev"
+
+// windowTimeout bounds the Hyprland active window query at recording start
+const windowTimeout = 500 * time.Millisecond
+
 type Daemon struct {
 	mu        sync.RWMutex
 	notifier  notify.Notifier
@@ -25,11 +40,50 @@ type Daemon struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	pipeline pipeline.Pipeline
+	startedAt time.Time
+	lastError string
+	events    *eventHub
+
+	// sessions are the dictations still running, oldest first. A new one
+	// can start while earlier ones finish; queue keeps their injections in
+	// start order.
+	sessions      []*session
+	nextSessionID int
+	queue         pipeline.Queue
+
+	// publishMu orders status events, so the last one delivered reflects
+	// the latest state
+	publishMu sync.Mutex
+
+	// newPipeline creates the pipeline for a session
+	newPipeline func(cfg *config.Config, opts ...pipeline.Option) pipeline.Pipeline
+
+	// lastInjection is the most recent dictation, kept for the last commands
+	lastInjection *injection.Injection
+
+	// overrides are the sticky runtime overrides from hyprvoice set; they
+	// are applied to every new pipeline until the daemon restarts
+	overrides config.Overrides
+
+	// activeWindow returns the focused window for [[window_rules]]
+	activeWindow func(ctx context.Context) (hyprland.Window, error)
+
+	// warm keeps the microphone open between dictations with
+	// [recording.warm] (nil when disabled)
+	warm *recording.Warm
 
 	wg sync.WaitGroup
 }
 
+// session is one dictation run by the daemon
+type session struct {
+	id       int
+	pipeline pipeline.Pipeline
+	started  time.Time
+	levels   recording.Levels // latest input levels, guarded by Daemon.mu
+	done     chan struct{}    // closed once the pipeline has ended
+}
+
 func New() (*Daemon, error) {
 	configMgr, err := config.NewManager()
 	if err != nil {
@@ -50,41 +104,233 @@ func New() (*Daemon, error) {
 		configMgr: configMgr,
 		ctx:       ctx,
 		cancel:    cancel,
+		startedAt: time.Now(),
+		events:    newEventHub(),
+
+		activeWindow: hyprland.ActiveWindow,
+		newPipeline:  pipeline.New,
 	}
 
 	return d, nil
 }
 
 func (d *Daemon) onConfigReload() {
-	log.Printf("Config reloaded, restarting pipeline")
-	d.stopPipeline()
+	logger.Info("Config reloaded, restarting pipeline")
+	d.stopSessions()
 
 	conf := d.configMgr.GetConfig()
+	d.setupLogging(conf)
+	d.setupWarm(conf)
 
 	d.mu.Lock()
 	d.notifier = notify.NewNotifier(conf.Notifications.Type, conf.Notifications.Messages.Resolve())
 	d.mu.Unlock()
 
-	d.notifier.Send(notify.MsgConfigReloaded)
+	d.sendNotification(notify.MsgConfigReloaded)
+}
+
+// setupLogging applies the [logging] section, keeping stderr-only logging
+// when the log file cannot be opened
+func (d *Daemon) setupLogging(conf *config.Config) {
+	logCfg := conf.ToLoggingConfig()
+	path, err := logging.Path(bus.Instance())
+	if err != nil {
+		logger.Warn("Failed to resolve log file path", "err", err)
+		logCfg.File = false
+	}
+	logCfg.Path = path
+	if err := logging.Setup(logCfg); err != nil {
+		logger.Warn("Failed to set up logging", "err", err)
+	}
+}
+
+// setupWarm starts the warm recorder when [recording.warm] is enabled and
+// stops the previous one, so a reload picks up new recording settings. It
+// runs until the daemon exits, not until shutdown starts, so a dictation
+// finalized at shutdown keeps recording to the end.
+func (d *Daemon) setupWarm(conf *config.Config) {
+	var next *recording.Warm
+	if recCfg := conf.ToRecordingConfig(); recCfg.Warm.Enabled {
+		next = recording.NewWarm(recCfg, recording.NewRecorder(recCfg))
+		next.Start(context.WithoutCancel(d.ctx))
+	}
+
+	d.mu.Lock()
+	prev := d.warm
+	d.warm = next
+	d.mu.Unlock()
+
+	if prev != nil {
+		prev.Close()
+	}
+}
+
+func (d *Daemon) getWarm() *recording.Warm {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.warm
+}
+
+// sendNotification shows mt to the user and publishes it to subscribers
+func (d *Daemon) sendNotification(mt notify.MessageType) {
+	d.events.publish(bus.Event{Type: bus.EventNotification, Notification: mt.String()})
+	d.notifier.Send(mt)
+}
+
+// current returns the newest session that has not ended, nil when idle.
+// Commands like stop and toggle act on it.
+func (d *Daemon) current() *session {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for i := len(d.sessions) - 1; i >= 0; i-- {
+		if d.sessions[i].pipeline.Status() != pipeline.Idle {
+			return d.sessions[i]
+		}
+	}
+	return nil
 }
 
 func (d *Daemon) status() pipeline.Status {
+	if s := d.current(); s != nil {
+		return s.pipeline.Status()
+	}
+	return pipeline.Idle
+}
+
+// queued counts the sessions that stopped recording but have not injected
+// yet, behind the current one
+func (d *Daemon) queued() int {
+	cur := d.current()
+
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	if d.pipeline == nil {
-		return pipeline.Idle
+	n := 0
+	for _, s := range d.sessions {
+		if s == cur {
+			continue
+		}
+		switch s.pipeline.Status() {
+		case pipeline.Transcribing, pipeline.Processing, pipeline.Queued, pipeline.Injecting:
+			n++
+		}
 	}
-	return d.pipeline.Status()
+	return n
+}
+
+// isRecording reports whether status belongs to a session still capturing audio
+func isRecording(status pipeline.Status) bool {
+	return status == pipeline.Recording || status == pipeline.Transcribing
 }
 
-func (d *Daemon) stopPipeline() {
+// statusInfo builds the structured status reported over the JSON protocol
+func (d *Daemon) statusInfo() bus.StatusInfo {
+	conf := d.configMgr.GetConfig()
+	if effective, err := d.effectiveConfig(nil); err == nil {
+		conf = effective
+	}
+
+	d.mu.RLock()
+	lastError := d.lastError
+	overrides := d.overrides
+	sessions := make([]bus.SessionInfo, 0, len(d.sessions))
+	for _, s := range d.sessions {
+		if status := s.pipeline.Status(); status != pipeline.Idle {
+			sessions = append(sessions, bus.SessionInfo{
+				ID:      s.id,
+				Status:  string(status),
+				Seconds: time.Since(s.started).Seconds(),
+			})
+		}
+	}
+	d.mu.RUnlock()
+
+	info := bus.StatusInfo{
+		Status:        string(d.status()),
+		Provider:      conf.Transcription.Provider,
+		Model:         conf.Transcription.Model,
+		LastError:     lastError,
+		UptimeSeconds: time.Since(d.startedAt).Seconds(),
+		Version:       Version,
+		Overrides:     overrides,
+		Queue:         d.queued(),
+	}
+	if cur := d.current(); cur != nil {
+		info.SessionSeconds = time.Since(cur.started).Seconds()
+		if isRecording(cur.pipeline.Status()) {
+			d.mu.RLock()
+			info.Level, info.Peak = cur.levels.RMS, cur.levels.Peak
+			d.mu.RUnlock()
+		}
+	}
+	if len(sessions) > 1 {
+		info.Sessions = sessions
+	}
+	return info
+}
+
+func (d *Daemon) setLastError(message string) {
 	d.mu.Lock()
-	p := d.pipeline
-	d.pipeline = nil
+	defer d.mu.Unlock()
+	d.lastError = message
+}
+
+// stopSession aborts s and forgets it
+func (d *Daemon) stopSession(s *session) {
+	d.forget(s)
+	s.pipeline.Stop()
+}
+
+// stopSessions aborts every running session
+func (d *Daemon) stopSessions() {
+	d.mu.Lock()
+	sessions := d.sessions
+	d.sessions = nil
 	d.mu.Unlock()
 
-	if p != nil {
-		p.Stop()
+	for _, s := range sessions {
+		s.pipeline.Stop()
+	}
+	for _, s := range sessions {
+		d.publishStatus(s, pipeline.Idle)
+	}
+}
+
+// forget removes s from the running sessions
+func (d *Daemon) forget(s *session) {
+	found := false
+	d.mu.Lock()
+	for i, other := range d.sessions {
+		if other == s {
+			d.sessions = append(d.sessions[:i:i], d.sessions[i+1:]...)
+			found = true
+			break
+		}
+	}
+	d.mu.Unlock()
+	if found {
+		d.publishStatus(s, pipeline.Idle)
+	}
+}
+
+// reap forgets s once its pipeline has ended
+func (d *Daemon) reap(s *session) {
+	s.pipeline.Wait()
+	close(s.done)
+	d.forget(s)
+}
+
+// waitPipeline lets the dictations in flight at shutdown finish according
+// to recording.on_shutdown before the daemon exits
+func (d *Daemon) waitPipeline() {
+	d.mu.RLock()
+	sessions := append([]*session(nil), d.sessions...)
+	d.mu.RUnlock()
+
+	for _, s := range sessions {
+		if s.pipeline.Status() != pipeline.Idle {
+			logger.Info("Waiting for dictation to finish", "session", s.id)
+		}
+		s.pipeline.Wait()
 	}
 }
 
@@ -94,6 +340,8 @@ func (d *Daemon) Run() error {
 	}
 
 	d.configMgr.SetOnConfigReload(d.onConfigReload)
+	d.setupLogging(d.configMgr.GetConfig())
+	defer logging.Close()
 
 	ln, err := bus.Listen()
 	if err != nil {
@@ -106,39 +354,55 @@ func (d *Daemon) Run() error {
 	}
 	defer bus.RemovePidFile()
 
+	d.setupWarm(d.configMgr.GetConfig())
+	defer func() {
+		if warm := d.getWarm(); warm != nil {
+			warm.Close()
+		}
+	}()
+
 	if err := d.configMgr.StartWatching(d.ctx); err != nil {
-		log.Printf("Warning: failed to start config file watching: %v", err)
+		logger.Warn("Failed to start config file watching", "err", err)
 	}
 	defer d.configMgr.Stop()
 
+	go d.serveDBus()
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
 	defer signal.Stop(sigCh)
 
 	go func() {
 		sig := <-sigCh
-		log.Printf("Received signal %v, shutting down gracefully", sig)
+		logger.Info("Received signal, shutting down gracefully", "signal", sig)
 		d.cancel()
+
+		// a second signal skips finalizing the current dictation
+		sig = <-sigCh
+		logger.Warn("Received signal again, exiting immediately", "signal", sig)
+		bus.RemovePidFile()
+		os.Exit(1)
 	}()
 
 	go func() {
 		<-d.ctx.Done()
 		if err := ln.Close(); err != nil {
-			log.Printf("Error closing listener: %v", err)
+			logger.Warn("Failed to close listener", "err", err)
 		}
 	}()
 
-	log.Printf("Daemon started, listening on socket")
+	logger.Info("Daemon started, listening on socket", "version", Version)
 
 	for {
 		c, err := ln.Accept()
 		if err != nil {
 			if d.ctx.Err() != nil {
-				log.Printf("Shutdown requested, waiting for connections to finish")
+				logger.Info("Shutdown requested, waiting for connections to finish")
 				d.wg.Wait()
+				d.waitPipeline()
 				return nil
 			}
-			log.Printf("Accept error: %v", err)
+			logger.Error("Accept error", "err", err)
 			return fmt.Errorf("accept failed: %w", err)
 		}
 		d.wg.Add(1)
@@ -152,7 +416,7 @@ func (d *Daemon) handle(c net.Conn) {
 
 	line, err := bufio.NewReader(c).ReadString('\n')
 	if err != nil {
-		log.Printf("Client read error: %v", err)
+		logger.Warn("Client read error", "err", err)
 		fmt.Fprintf(c, "ERR read_error: %v\n", err)
 		return
 	}
@@ -160,14 +424,21 @@ func (d *Daemon) handle(c net.Conn) {
 		fmt.Fprint(c, "ERR empty\n")
 		return
 	}
+	if bus.IsJSONRequest(line) {
+		d.handleRequest(c, line)
+		return
+	}
 	cmd := line[0]
 
 	switch cmd {
 	case 't':
-		d.toggle()
+		if err := d.toggle(nil); err != nil {
+			fmt.Fprintf(c, "ERR toggle_failed: %v\n", err)
+			return
+		}
 		fmt.Fprint(c, "OK toggled\n")
 	case 'c':
-		d.cancelPipeline()
+		d.cancelSession("")
 		fmt.Fprint(c, "OK cancelled\n")
 	case 's':
 		status := d.status()
@@ -178,88 +449,626 @@ func (d *Daemon) handle(c net.Conn) {
 		fmt.Fprint(c, "OK quitting\n")
 		d.cancel()
 	default:
-		log.Printf("Unknown command: %c", cmd)
+		logger.Warn("Unknown command", "cmd", string(cmd))
 		fmt.Fprintf(c, "ERR unknown=%q\n", cmd)
 	}
 }
 
-func (d *Daemon) toggle() {
-	if d.configMgr.IsLegacy() {
-		d.notifier.Error("Legacy config detected. Run: hyprvoice onboarding")
+// handleRequest serves a single JSON protocol request
+func (d *Daemon) handleRequest(c net.Conn, line string) {
+	req, err := bus.ParseRequest(line)
+	if err != nil {
+		logger.Warn("Client request error", "err", err)
+		if err := bus.WriteResponse(c, bus.Response{Error: err.Error()}); err != nil {
+			logger.Warn("Client write error", "err", err)
+		}
 		return
 	}
-	conf := d.configMgr.GetConfig()
-	switch d.status() {
-	case pipeline.Idle:
-		p := pipeline.New(conf)
-		p.Run(d.ctx)
 
-		d.mu.Lock()
-		d.pipeline = p
-		d.mu.Unlock()
+	resp := bus.Response{ID: req.ID, OK: true}
+	quit := false
+
+	switch req.Cmd {
+	case bus.CmdToggle:
+		args, wait := waitArg(req.Args)
+		overrides, err := config.ParseOverrides(args)
+		var s *session
+		if err == nil {
+			s, err = d.toggleSession(overrides)
+		}
+		switch {
+		case err != nil:
+			resp.OK = false
+			resp.Error = err.Error()
+		case wait && s == nil:
+			resp.OK = false
+			resp.Error = "no dictation to wait for"
+		case wait:
+			d.wait(s, &resp)
+		default:
+			resp.Result = "toggled"
+		}
+	case bus.CmdStart:
+		overrides, err := config.ParseOverrides(req.Args)
+		started := false
+		if err == nil {
+			started, err = d.start(overrides)
+		}
+		switch {
+		case err != nil:
+			resp.OK = false
+			resp.Error = err.Error()
+		case started:
+			resp.Result = "started"
+		default:
+			resp.Result = "already_recording"
+		}
+	case bus.CmdStop:
+		_, wait := waitArg(req.Args)
+		s, stopping := d.finishSession()
+		resp.Result = "not_recording"
+		if stopping {
+			resp.Result = "stopping"
+		}
+		switch {
+		case wait && s == nil:
+			resp.OK = false
+			resp.Error = "not recording"
+		case wait:
+			d.wait(s, &resp)
+		}
+	case bus.CmdCancel:
+		result, err := d.cancelSession(req.Args["session"])
+		if err != nil {
+			resp.OK = false
+			resp.Error = err.Error()
+		}
+		resp.Result = result
+	case bus.CmdStatus:
+		info := d.statusInfo()
+		resp.Status = &info
+	case bus.CmdVersion:
+		resp.Version = Version
+	case bus.CmdQuit:
+		resp.Result = "quitting"
+		quit = true
+	case bus.CmdLast:
+		result, err := d.last(req.Args["action"])
+		if err != nil {
+			resp.OK = false
+			resp.Error = err.Error()
+		}
+		resp.Result = result
+	case bus.CmdSet:
+		overrides, err := d.set(req.Args)
+		if err != nil {
+			resp.OK = false
+			resp.Error = err.Error()
+		}
+		resp.Result = "set"
+		resp.Overrides = overrides
+	case bus.CmdSubscribe:
+		d.subscribe(c, req.ID)
+		return
+	default:
+		logger.Warn("Unknown command", "cmd", req.Cmd)
+		resp.OK = false
+		resp.Error = fmt.Sprintf("unknown command: %s", req.Cmd)
+	}
+
+	if err := bus.WriteResponse(c, resp); err != nil {
+		logger.Warn("Client write error", "err", err)
+	}
+	if quit {
+		d.cancel()
+	}
+}
+
+// waitArg splits ArgWait off request args, so the rest can be parsed as
+// overrides
+func waitArg(args map[string]string) (map[string]string, bool) {
+	value, ok := args[bus.ArgWait]
+	if !ok {
+		return args, false
+	}
+	rest := make(map[string]string, len(args))
+	for key, v := range args {
+		if key != bus.ArgWait {
+			rest[key] = v
+		}
+	}
+	wait, _ := strconv.ParseBool(value)
+	return rest, wait
+}
 
-		go d.notifier.Send(notify.MsgRecordingStarted)
-		go d.monitorPipelineErrors(p)
-		go d.monitorPipelineNotifications(p)
+// subscribe streams events to c until the client hangs up or the daemon stops
+func (d *Daemon) subscribe(c net.Conn, id string) {
+	subID, events := d.events.subscribe()
+	defer d.events.unsubscribe(subID)
 
+	if err := bus.WriteResponse(c, bus.Response{ID: id, OK: true, Result: "subscribed"}); err != nil {
+		logger.Warn("Client write error", "err", err)
+		return
+	}
+
+	// send the current status first so clients can render without waiting for a transition
+	current := bus.Event{Type: bus.EventStatus, Time: time.Now(), Status: string(d.status()), Queue: d.queued()}
+	if err := bus.WriteResponse(c, bus.Response{ID: id, OK: true, Event: &current}); err != nil {
+		logger.Warn("Client write error", "err", err)
+		return
+	}
+
+	// subscribers never send anything else, so a read returning means they hung up
+	closed := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, c)
+		close(closed)
+	}()
+
+	for {
+		select {
+		case ev := <-events:
+			if err := bus.WriteResponse(c, bus.Response{ID: id, OK: true, Event: &ev}); err != nil {
+				logger.Debug("Subscriber write error", "err", err)
+				return
+			}
+		case <-closed:
+			return
+		case <-d.ctx.Done():
+			return
+		}
+	}
+}
+
+// toggle starts a dictation unless one is recording, applying overrides on
+// top of the sticky ones, and otherwise injects or aborts the recording one.
+// Earlier dictations still processing keep going in the background.
+func (d *Daemon) toggle(overrides config.Overrides) error {
+	_, err := d.toggleSession(overrides)
+	return err
+}
+
+// toggleSession is toggle returning the session it started, stopped or
+// aborted
+func (d *Daemon) toggleSession(overrides config.Overrides) (*session, error) {
+	if d.configMgr.IsLegacy() {
+		d.notifier.Error("Legacy config detected. Run: hyprvoice onboarding")
+		return nil, fmt.Errorf("legacy config detected, run: hyprvoice onboarding")
+	}
+	cur := d.current()
+	if cur == nil {
+		return d.startPipeline(overrides)
+	}
+
+	switch cur.pipeline.Status() {
 	case pipeline.Recording:
-		d.stopPipeline()
-		go d.notifier.Send(notify.MsgRecordingAborted)
+		d.stopSession(cur)
+		go d.sendNotification(notify.MsgRecordingAborted)
 
 	case pipeline.Transcribing:
-		d.mu.RLock()
-		if d.pipeline != nil {
-			actionChan := d.pipeline.GetActionCh()
-			log.Printf("Daemon: Sending inject action to pipeline")
-			d.mu.RUnlock()
-			actionChan <- pipeline.Inject
-		} else {
-			d.mu.RUnlock()
+		select {
+		case cur.pipeline.GetActionCh() <- pipeline.Inject:
+			logger.Debug("Sending inject action to pipeline", "session", cur.id)
+		default:
+			logger.Info("Inject action already pending, ignoring", "session", cur.id)
+			return cur, nil
+		}
+		go d.sendNotification(notify.MsgTranscribing)
+
+	default:
+		return d.startPipeline(overrides)
+	}
+	return cur, nil
+}
+
+// start begins recording for push-to-talk. It is a no-op unless idle.
+func (d *Daemon) start(overrides config.Overrides) (bool, error) {
+	if d.configMgr.IsLegacy() {
+		d.notifier.Error("Legacy config detected. Run: hyprvoice onboarding")
+		return false, fmt.Errorf("legacy config detected, run: hyprvoice onboarding")
+	}
+	if status := d.status(); isRecording(status) {
+		logger.Info("Start requested while recording, ignoring", "status", status)
+		return false, nil
+	}
+	if _, err := d.startPipeline(overrides); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// finish stops recording and injects the transcription for push-to-talk.
+// Unlike toggle it never aborts: a stop sent before the transcriber is up is
+// queued and handled as soon as the pipeline starts listening for actions.
+func (d *Daemon) finish() bool {
+	_, stopping := d.finishSession()
+	return stopping
+}
+
+// finishSession is finish returning the recording session (nil when none
+// is) and whether this call stopped it rather than an earlier pending stop
+func (d *Daemon) finishSession() (*session, bool) {
+	cur := d.current()
+	if cur == nil || !isRecording(cur.pipeline.Status()) {
+		logger.Info("Stop requested while not recording, ignoring", "status", d.status())
+		return nil, false
+	}
+
+	select {
+	case cur.pipeline.GetActionCh() <- pipeline.Inject:
+		logger.Debug("Sending inject action to pipeline", "session", cur.id)
+	default:
+		logger.Info("Inject action already pending, ignoring", "session", cur.id)
+		return cur, false
+	}
+	go d.sendNotification(notify.MsgTranscribing)
+	return cur, true
+}
+
+// wait blocks until s has ended and fills resp with its outcome. A
+// dictation that was cancelled, failed or fell back to the raw transcript
+// after an LLM error is reported as an error.
+func (d *Daemon) wait(s *session, resp *bus.Response) {
+	s.pipeline.Wait()
+	r := s.pipeline.Result()
+
+	resp.Dictation = &bus.DictationResult{
+		Session:    s.id,
+		Transcript: r.Transcript,
+		Processed:  r.Processed,
+		Text:       r.Text,
+		Backend:    r.Backend,
+		Injected:   r.Injected,
+		Cancelled:  r.Cancelled,
+		Errors:     r.Errors,
+	}
+
+	switch {
+	case r.Cancelled:
+		resp.OK = false
+		resp.Result = "cancelled"
+		resp.Error = "dictation cancelled"
+	case len(r.Errors) > 0:
+		resp.OK = false
+		resp.Result = "failed"
+		resp.Error = strings.Join(r.Errors, "; ")
+	case !r.Injected:
+		resp.OK = false
+		resp.Result = "failed"
+		resp.Error = "dictation ended without injecting text"
+	default:
+		resp.Result = "injected"
+	}
+}
+
+func (d *Daemon) startPipeline(overrides config.Overrides) (*session, error) {
+	overrides = d.withWindowProfile(overrides)
+	conf, err := d.effectiveConfig(overrides)
+	if err != nil {
+		logger.Error("Not starting pipeline", "err", err)
+		d.notifier.Error(err.Error())
+		return nil, err
+	}
+	if len(overrides) > 0 {
+		logger.Info("Starting pipeline with overrides", "overrides", overrides)
+	}
+	if running := len(d.runningSessions()); running > 0 {
+		logger.Info("Starting pipeline while earlier dictations finish", "running", running)
+	}
+
+	opts := []pipeline.Option{pipeline.WithTurn(d.queue.Next())}
+	if warm := d.getWarm(); warm != nil {
+		opts = append(opts, pipeline.WithRecorderFactory(warm.Recorder))
+	}
+	p := d.newPipeline(conf, opts...)
+	p.Run(d.ctx)
+
+	d.mu.Lock()
+	d.nextSessionID++
+	s := &session{id: d.nextSessionID, pipeline: p, started: time.Now(), done: make(chan struct{})}
+	d.sessions = append(d.sessions, s)
+	d.mu.Unlock()
+
+	go d.reap(s)
+	go d.sendNotification(notify.MsgRecordingStarted)
+	go d.monitorPipelineErrors(p, s.done)
+	go d.monitorPipelineNotifications(p, s.done)
+	go d.monitorPipelineEvents(s)
+	return s, nil
+}
+
+// effectiveConfig returns the current config with the sticky overrides and
+// then overrides applied
+func (d *Daemon) effectiveConfig(overrides config.Overrides) (*config.Config, error) {
+	d.mu.RLock()
+	merged := d.overrides.Merge(overrides)
+	d.mu.RUnlock()
+
+	return d.configMgr.GetConfig().WithOverrides(merged)
+}
+
+// withWindowProfile adds the profile picked by the window rules for the
+// focused window to overrides, unless a profile was selected explicitly
+func (d *Daemon) withWindowProfile(overrides config.Overrides) config.Overrides {
+	conf := d.configMgr.GetConfig()
+	if len(conf.WindowRules) == 0 || overrides[config.OverrideProfile] != "" {
+		return overrides
+	}
+	d.mu.RLock()
+	sticky := d.overrides[config.OverrideProfile]
+	d.mu.RUnlock()
+	if sticky != "" {
+		return overrides
+	}
+
+	ctx, cancel := context.WithTimeout(d.ctx, windowTimeout)
+	defer cancel()
+	window, err := d.activeWindow(ctx)
+	if err != nil {
+		logger.Info("Skipping window rules", "err", err)
+		return overrides
+	}
+
+	name := conf.WindowProfile(window.Class, window.Title)
+	if name == "" {
+		return overrides
+	}
+	logger.Debug("Window rule selected profile", "class", window.Class, logging.Text("title", window.Title), "profile", name)
+	return overrides.Merge(config.Overrides{config.OverrideProfile: name})
+}
+
+// set updates the sticky overrides from args (an empty value clears a key)
+// and returns the resulting set. Changes that would make the config invalid
+// are rejected as a whole.
+func (d *Daemon) set(args map[string]string) (config.Overrides, error) {
+	changes, err := config.ParseOverrides(args)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err != nil {
+		return d.overrides, err
+	}
+
+	next := d.overrides.Merge(changes)
+	for key, value := range changes {
+		if value == "" {
+			delete(next, key)
 		}
-		go d.notifier.Send(notify.MsgTranscribing)
+	}
+	if _, err := d.configMgr.GetConfig().WithOverrides(next); err != nil {
+		return d.overrides, err
+	}
 
-	case pipeline.Injecting:
-		d.stopPipeline()
-		go d.notifier.Send(notify.MsgInjectionAborted)
+	if len(changes) > 0 {
+		logger.Info("Runtime overrides set", "overrides", next)
 	}
+	d.overrides = next
+	return next, nil
 }
 
-func (d *Daemon) cancelPipeline() {
-	switch d.status() {
-	case pipeline.Idle:
-		log.Printf("Daemon: Cancel requested but pipeline is idle, ignoring")
+// cancelSession aborts the dictation named by target: "" for the current
+// one, "all" for every running one, or a session ID from status
+func (d *Daemon) cancelSession(target string) (string, error) {
+	var sess *session
+	switch target {
+	case "":
+		sess = d.current()
+		if sess == nil {
+			logger.Info("Cancel requested but pipeline is idle, ignoring")
+			return "cancelled", nil
+		}
+
+	case "all":
+		if len(d.runningSessions()) == 0 {
+			logger.Info("Cancel requested but pipeline is idle, ignoring")
+			return "cancelled", nil
+		}
+		d.stopSessions()
+		go d.sendNotification(notify.MsgOperationCancelled)
+		return "cancelled", nil
+
 	default:
-		d.stopPipeline()
-		go d.notifier.Send(notify.MsgOperationCancelled)
+		id, err := strconv.Atoi(target)
+		if err != nil {
+			return "", fmt.Errorf("invalid session: %q (use a session ID or all)", target)
+		}
+		for _, s := range d.runningSessions() {
+			if s.id == id {
+				sess = s
+			}
+		}
+		if sess == nil {
+			return "", fmt.Errorf("no running session %d", id)
+		}
+	}
+
+	// a dictation that already stopped recording only had its text left to deliver
+	mt := notify.MsgOperationCancelled
+	if !isRecording(sess.pipeline.Status()) {
+		mt = notify.MsgInjectionAborted
 	}
+	d.stopSession(sess)
+	go d.sendNotification(mt)
+	return "cancelled", nil
 }
 
-func (d *Daemon) monitorPipelineErrors(p pipeline.Pipeline) {
+// runningSessions returns the sessions that have not ended, oldest first
+func (d *Daemon) runningSessions() []*session {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	var running []*session
+	for _, s := range d.sessions {
+		if s.pipeline.Status() != pipeline.Idle {
+			running = append(running, s)
+		}
+	}
+	return running
+}
+
+// The pipeline monitors run until done is closed, which happens when the
+// pipeline has ended rather than when the daemon shuts down, so messages sent
+// while finalizing a dictation at shutdown are still delivered.
+
+func (d *Daemon) monitorPipelineErrors(p pipeline.Pipeline, done <-chan struct{}) {
 	errorCh := p.GetErrorCh()
 	for {
 		select {
 		case pipelineErr := <-errorCh:
-			message := pipelineErr.Message
-
-			if pipelineErr.Err != nil {
-				message = fmt.Sprintf("%s: %v", message, pipelineErr.Err)
+			d.reportPipelineError(pipelineErr)
+		case <-done:
+			for len(errorCh) > 0 {
+				d.reportPipelineError(<-errorCh)
 			}
-
-			d.notifier.Error(message)
-		case <-d.ctx.Done():
 			return
 		}
 	}
 }
 
-func (d *Daemon) monitorPipelineNotifications(p pipeline.Pipeline) {
+func (d *Daemon) reportPipelineError(pipelineErr pipeline.PipelineError) {
+	message := pipelineErr.Message
+
+	if pipelineErr.Err != nil {
+		message = fmt.Sprintf("%s: %v", message, pipelineErr.Err)
+	}
+
+	d.setLastError(message)
+	d.events.publish(bus.Event{
+		Type:    bus.EventError,
+		Title:   pipelineErr.Title,
+		Message: message,
+	})
+	d.notifier.Error(message)
+}
+
+func (d *Daemon) monitorPipelineNotifications(p pipeline.Pipeline, done <-chan struct{}) {
 	notifyCh := p.GetNotifyCh()
 	for {
 		select {
 		case mt := <-notifyCh:
-			d.notifier.Send(mt)
-		case <-d.ctx.Done():
+			d.sendNotification(mt)
+		case <-done:
+			for len(notifyCh) > 0 {
+				d.sendNotification(<-notifyCh)
+			}
+			return
+		}
+	}
+}
+
+// monitorPipelineEvents publishes the status transitions, streaming
+// transcripts and input levels of s
+func (d *Daemon) monitorPipelineEvents(s *session) {
+	p := s.pipeline
+	statusCh := p.GetStatusCh()
+	transcriptCh := p.GetTranscriptCh()
+	injectedCh := p.GetInjectedCh()
+	levelsCh := p.GetLevelsCh()
+	for {
+		select {
+		case injected := <-injectedCh:
+			d.setLastInjection(&injected)
+		case status := <-statusCh:
+			d.publishStatus(s, status)
+		case result := <-transcriptCh:
+			d.events.publish(bus.Event{Type: bus.EventTranscript, Text: result.Text, Final: result.IsFinal})
+		case levels := <-levelsCh:
+			d.setLevels(s, levels)
+			d.events.publish(bus.Event{Type: bus.EventLevel, Level: levels.RMS, Peak: levels.Peak})
+		case <-s.done:
+			for len(statusCh) > 0 {
+				d.publishStatus(s, <-statusCh)
+			}
+			// keep the last injection for "hyprvoice last" even when it
+			// arrived just before the pipeline ended
+			for len(injectedCh) > 0 {
+				injected := <-injectedCh
+				d.setLastInjection(&injected)
+			}
 			return
 		}
 	}
 }
+
+// publishStatus tells subscribers that s changed to status, along with the
+// daemon status and queue depth. With overlapping sessions the daemon status
+// is the one of the current session, not necessarily of s.
+func (d *Daemon) publishStatus(s *session, status pipeline.Status) {
+	d.publishMu.Lock()
+	defer d.publishMu.Unlock()
+
+	d.events.publish(bus.Event{
+		Type:          bus.EventStatus,
+		Status:        string(d.status()),
+		Queue:         d.queued(),
+		Session:       s.id,
+		SessionStatus: string(status),
+	})
+}
+
+// setLevels remembers the latest input levels of s
+func (d *Daemon) setLevels(s *session, levels recording.Levels) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s.levels = levels
+}
+
+func (d *Daemon) setLastInjection(injected *injection.Injection) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastInjection = injected
+}
+
+func (d *Daemon) getLastInjection() *injection.Injection {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.lastInjection
+}
+
+// last re-injects, copies or undoes the most recent dictation
+func (d *Daemon) last(action string) (string, error) {
+	switch action {
+	case "reinject", "copy", "undo":
+	default:
+		return "", fmt.Errorf("unknown last action: %q (use reinject, copy or undo)", action)
+	}
+
+	last := d.getLastInjection()
+	if last == nil || last.Text == "" {
+		return "", fmt.Errorf("no dictation to %s yet", action)
+	}
+
+	conf := d.configMgr.GetConfig().ToInjectionConfig()
+
+	switch action {
+	case "reinject":
+		if status := d.status(); status != pipeline.Idle {
+			return "", fmt.Errorf("cannot reinject while %s", status)
+		}
+		injector := injection.NewInjector(conf)
+		if err := injector.Inject(d.ctx, last.Text); err != nil {
+			return "", fmt.Errorf("failed to reinject: %w", err)
+		}
+		injected := injection.Injection{Text: last.Text}
+		if reporter, ok := injector.(injection.Reporter); ok {
+			injected = reporter.LastInjection()
+		}
+		d.setLastInjection(&injected)
+		return "reinjected", nil
+
+	case "copy":
+		if err := injection.NewClipboardBackend().Inject(d.ctx, last.Text, conf.ClipboardTimeout); err != nil {
+			return "", fmt.Errorf("failed to copy: %w", err)
+		}
+		return "copied", nil
+	}
+
+	if last.Backend == "" {
+		return "", fmt.Errorf("last dictation was not injected, nothing to undo")
+	}
+	if err := injection.Undo(d.ctx, conf, *last); err != nil {
+		return "", fmt.Errorf("failed to undo: %w", err)
+	}
+	// keep the text for reinject/copy but make a second undo a no-op
+	d.setLastInjection(&injection.Injection{Text: last.Text})
+	return "undone", nil
+}
@@ -1,16 +1,27 @@
 package daemon
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/leonardotrapani/hyprvoice/internal/bus"
+	"github.com/leonardotrapani/hyprvoice/internal/config"
+	"github.com/leonardotrapani/hyprvoice/internal/hyprland"
+	"github.com/leonardotrapani/hyprvoice/internal/injection"
 	"github.com/leonardotrapani/hyprvoice/internal/notify"
 	"github.com/leonardotrapani/hyprvoice/internal/pipeline"
+	"github.com/leonardotrapani/hyprvoice/internal/recording"
+	"github.com/leonardotrapani/hyprvoice/internal/transcriber"
 )
 
 const testConfigContent = `[recording]
@@ -134,12 +145,12 @@ func TestDaemon_Toggle(t *testing.T) {
 	}
 
 	// Test toggle from idle to recording
-	daemon.toggle()
+	daemon.toggle(nil)
 	status := daemon.status()
 	t.Logf("Status after first toggle = %s", status)
 
 	// Test toggle from recording to idle (abort)
-	daemon.toggle()
+	daemon.toggle(nil)
 	status = daemon.status()
 	t.Logf("Status after second toggle = %s", status)
 }
@@ -272,21 +283,22 @@ func TestDaemon_StopPipeline(t *testing.T) {
 		t.Fatalf("Failed to create daemon: %v", err)
 	}
 
-	// Test stopPipeline with nil pipeline
-	daemon.stopPipeline()
+	// Test stopSessions with no sessions
+	daemon.stopSessions()
 
-	// Test stopPipeline with a mock pipeline
+	// Test stopSessions with mock pipelines
 	// (This is simplified since we can't easily mock the pipeline interface)
+	setSession(daemon, &MockPipeline{})
 	daemon.mu.Lock()
-	daemon.pipeline = &MockPipeline{}
+	daemon.sessions = append(daemon.sessions, &session{id: 2, pipeline: &MockPipeline{status: pipeline.Processing}})
 	daemon.mu.Unlock()
 
-	daemon.stopPipeline()
+	daemon.stopSessions()
 
-	// Verify pipeline is set to nil
+	// Verify every session is gone
 	daemon.mu.RLock()
-	if daemon.pipeline != nil {
-		t.Errorf("Pipeline should be nil after stopPipeline")
+	if len(daemon.sessions) != 0 {
+		t.Errorf("Sessions should be empty after stopSessions")
 	}
 	daemon.mu.RUnlock()
 }
@@ -357,15 +369,907 @@ func TestDaemon_Handle_Commands(t *testing.T) {
 }
 
 // MockPipeline implements pipeline.Pipeline for testing
-type MockPipeline struct{}
+// setSession makes p the daemon's only session
+func setSession(d *Daemon, p pipeline.Pipeline) *session {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s := &session{id: 1, pipeline: p, started: time.Now(), done: make(chan struct{})}
+	d.sessions = []*session{s}
+	return s
+}
+
+type MockPipeline struct {
+	status     pipeline.Status
+	actionCh   chan pipeline.Action
+	injectedCh chan injection.Injection
+	levelsCh   chan recording.Levels
+	statusCh   chan pipeline.Status
+	result     pipeline.Result
+}
 
 func (m *MockPipeline) Run(ctx context.Context) {}
 func (m *MockPipeline) Stop()                   {}
-func (m *MockPipeline) Status() pipeline.Status { return pipeline.Idle }
+func (m *MockPipeline) Wait()                   {}
+func (m *MockPipeline) Status() pipeline.Status {
+	if m.status == "" {
+		return pipeline.Idle
+	}
+	return m.status
+}
 func (m *MockPipeline) GetErrorCh() <-chan pipeline.PipelineError {
 	return make(chan pipeline.PipelineError)
 }
-func (m *MockPipeline) GetActionCh() chan<- pipeline.Action { return make(chan pipeline.Action) }
+func (m *MockPipeline) GetActionCh() chan<- pipeline.Action {
+	if m.actionCh == nil {
+		return make(chan pipeline.Action)
+	}
+	return m.actionCh
+}
 func (m *MockPipeline) GetNotifyCh() <-chan notify.MessageType {
 	return make(chan notify.MessageType)
 }
+func (m *MockPipeline) GetStatusCh() <-chan pipeline.Status {
+	if m.statusCh == nil {
+		return make(chan pipeline.Status)
+	}
+	return m.statusCh
+}
+func (m *MockPipeline) GetTranscriptCh() <-chan transcriber.TranscriptionResult {
+	return make(chan transcriber.TranscriptionResult)
+}
+func (m *MockPipeline) GetInjectedCh() <-chan injection.Injection {
+	if m.injectedCh == nil {
+		return make(chan injection.Injection)
+	}
+	return m.injectedCh
+}
+
+func (m *MockPipeline) GetLevelsCh() <-chan recording.Levels {
+	if m.levelsCh == nil {
+		return make(chan recording.Levels)
+	}
+	return m.levelsCh
+}
+
+func (m *MockPipeline) Result() pipeline.Result { return m.result }
+
+// sendRequest handles one protocol line on a mock connection and decodes the reply
+func sendRequest(t *testing.T, d *Daemon, line string) bus.Response {
+	t.Helper()
+	mockConn := &MockConn{readData: []byte(line)}
+	d.wg.Add(1)
+	d.handle(mockConn)
+
+	var resp bus.Response
+	if err := json.Unmarshal(mockConn.writeData, &resp); err != nil {
+		t.Fatalf("response %q is not JSON: %v", mockConn.writeData, err)
+	}
+	return resp
+}
+
+func TestDaemon_HandleRequest(t *testing.T) {
+	// Set up a temporary config directory
+	tempDir := t.TempDir()
+	originalConfigDir := os.Getenv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", tempDir)
+	defer func() {
+		if originalConfigDir == "" {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		} else {
+			os.Setenv("XDG_CONFIG_HOME", originalConfigDir)
+		}
+	}()
+
+	// Create a basic config file
+	configPath := filepath.Join(tempDir, "hyprvoice", "config.toml")
+	os.MkdirAll(filepath.Dir(configPath), 0755)
+	os.WriteFile(configPath, []byte(testConfigContent), 0644)
+
+	daemon, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	daemon.setLastError("Recording Error: boom")
+
+	t.Run("status", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"id":"7","cmd":"status"}`+"\n")
+		if !resp.OK || resp.ID != "7" || resp.Proto != bus.ProtoV2 {
+			t.Fatalf("response = %+v", resp)
+		}
+		if resp.Status == nil {
+			t.Fatalf("response missing status")
+		}
+		if resp.Status.Status != "idle" {
+			t.Errorf("status = %q, want idle", resp.Status.Status)
+		}
+		if resp.Status.Provider != "openai" || resp.Status.Model != "whisper-1" {
+			t.Errorf("provider/model = %q/%q", resp.Status.Provider, resp.Status.Model)
+		}
+		if resp.Status.LastError != "Recording Error: boom" {
+			t.Errorf("last_error = %q", resp.Status.LastError)
+		}
+		if resp.Status.Version != Version {
+			t.Errorf("version = %q, want %q", resp.Status.Version, Version)
+		}
+	})
+
+	t.Run("version", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"cmd":"version"}`+"\n")
+		if !resp.OK || resp.Version != Version {
+			t.Errorf("response = %+v", resp)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"id":"x","cmd":"dance"}`+"\n")
+		if resp.OK || resp.Error == "" || resp.ID != "x" {
+			t.Errorf("response = %+v", resp)
+		}
+	})
+
+	t.Run("failed_toggle", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"cmd":"toggle","args":{"model":"no-such-model"}}`+"\n")
+		if resp.OK || resp.Error == "" || resp.Result != "" {
+			t.Errorf("response = %+v", resp)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"id":`+"\n")
+		if resp.OK || resp.Error == "" {
+			t.Errorf("response = %+v", resp)
+		}
+	})
+
+	t.Run("legacy_status_unchanged", func(t *testing.T) {
+		mockConn := &MockConn{readData: []byte("s\n")}
+		daemon.wg.Add(1)
+		daemon.handle(mockConn)
+		if got := string(mockConn.writeData); got != "STATUS status=idle\n" {
+			t.Errorf("legacy response = %q", got)
+		}
+	})
+}
+
+func TestDaemon_LegacyConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	configPath := filepath.Join(tempDir, "hyprvoice", "config.toml")
+	os.MkdirAll(filepath.Dir(configPath), 0755)
+	os.WriteFile(configPath, []byte("[transcription]\napi_key = \"old\"\n"), 0644)
+
+	daemon, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	daemon.notifier = notify.NewNotifier("log", nil)
+
+	for _, cmd := range []string{"toggle", "start"} {
+		resp := sendRequest(t, daemon, `{"cmd":"`+cmd+`"}`+"\n")
+		if resp.OK || !strings.Contains(resp.Error, "onboarding") {
+			t.Errorf("%s response = %+v, want the legacy config error", cmd, resp)
+		}
+	}
+	if n := len(daemon.runningSessions()); n != 0 {
+		t.Errorf("running sessions = %d, want none", n)
+	}
+}
+
+func TestDaemon_Subscribe(t *testing.T) {
+	// Set up a temporary config directory
+	tempDir := t.TempDir()
+	originalConfigDir := os.Getenv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", tempDir)
+	defer func() {
+		if originalConfigDir == "" {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		} else {
+			os.Setenv("XDG_CONFIG_HOME", originalConfigDir)
+		}
+	}()
+
+	// Create a basic config file
+	configPath := filepath.Join(tempDir, "hyprvoice", "config.toml")
+	os.MkdirAll(filepath.Dir(configPath), 0755)
+	os.WriteFile(configPath, []byte(testConfigContent), 0644)
+
+	daemon, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+
+	server, client := net.Pipe()
+	defer client.Close()
+
+	daemon.wg.Add(1)
+	go daemon.handle(server)
+
+	if _, err := client.Write([]byte(`{"id":"w","cmd":"subscribe"}` + "\n")); err != nil {
+		t.Fatalf("write subscribe: %v", err)
+	}
+
+	reader := bufio.NewReader(client)
+	next := func() bus.Response {
+		t.Helper()
+		client.SetReadDeadline(time.Now().Add(2 * time.Second))
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		var resp bus.Response
+		if err := json.Unmarshal(line, &resp); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		return resp
+	}
+
+	if resp := next(); !resp.OK || resp.Result != "subscribed" {
+		t.Fatalf("subscribe response = %+v", resp)
+	}
+
+	if resp := next(); resp.Event == nil || resp.Event.Type != bus.EventStatus || resp.Event.Status != "idle" {
+		t.Fatalf("initial event = %+v", resp.Event)
+	}
+
+	// the hub registers the subscriber before replying, so publishes are delivered from now on
+	daemon.sendNotification(notify.MsgConfigReloaded)
+	if resp := next(); resp.Event == nil || resp.Event.Type != bus.EventNotification || resp.Event.Notification != "config_reloaded" {
+		t.Fatalf("notification event = %+v", resp.Event)
+	}
+
+	daemon.events.publish(bus.Event{Type: bus.EventTranscript, Text: "hello", Final: true})
+	if resp := next(); resp.Event == nil || resp.Event.Text != "hello" || !resp.Event.Final {
+		t.Fatalf("transcript event = %+v", resp.Event)
+	}
+
+	// levels of the recording session are published and kept for status
+	mock := &MockPipeline{status: pipeline.Recording, levelsCh: make(chan recording.Levels, 1)}
+	go daemon.monitorPipelineEvents(setSession(daemon, mock))
+	mock.levelsCh <- recording.Levels{RMS: 0.1, Peak: 0.5}
+	if resp := next(); resp.Event == nil || resp.Event.Type != bus.EventLevel || resp.Event.Level != 0.1 || resp.Event.Peak != 0.5 {
+		t.Fatalf("level event = %+v", resp.Event)
+	}
+	if info := daemon.statusInfo(); info.Level != 0.1 || info.Peak != 0.5 {
+		t.Errorf("status level = %v, peak = %v", info.Level, info.Peak)
+	}
+
+	// a background session moving on is published with its id even
+	// though the daemon status stays with the newer recording
+	background := &MockPipeline{status: pipeline.Processing, statusCh: make(chan pipeline.Status, 1)}
+	daemon.mu.Lock()
+	bg := &session{id: 2, pipeline: background, started: time.Now(), done: make(chan struct{})}
+	daemon.sessions = append([]*session{bg}, daemon.sessions...)
+	daemon.mu.Unlock()
+	go daemon.monitorPipelineEvents(bg)
+	background.statusCh <- pipeline.Injecting
+	resp := next()
+	if ev := resp.Event; ev == nil || ev.Type != bus.EventStatus || ev.Status != "recording" || ev.Session != 2 || ev.SessionStatus != "injecting" {
+		t.Fatalf("background status event = %+v", resp.Event)
+	}
+
+	// hanging up must release the handler
+	client.Close()
+	done := make(chan struct{})
+	go func() {
+		daemon.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("subscription handler did not exit after client hang-up")
+	}
+}
+
+func TestDaemon_PushToTalk(t *testing.T) {
+	// Set up a temporary config directory
+	tempDir := t.TempDir()
+	originalConfigDir := os.Getenv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", tempDir)
+	defer func() {
+		if originalConfigDir == "" {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		} else {
+			os.Setenv("XDG_CONFIG_HOME", originalConfigDir)
+		}
+	}()
+
+	// Create a basic config file
+	configPath := filepath.Join(tempDir, "hyprvoice", "config.toml")
+	os.MkdirAll(filepath.Dir(configPath), 0755)
+	os.WriteFile(configPath, []byte(testConfigContent), 0644)
+
+	daemon, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+
+	t.Run("stop_when_idle", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"cmd":"stop"}`+"\n")
+		if !resp.OK || resp.Result != "not_recording" {
+			t.Errorf("response = %+v", resp)
+		}
+	})
+
+	for _, status := range []pipeline.Status{pipeline.Recording, pipeline.Transcribing} {
+		t.Run("start_while_"+string(status), func(t *testing.T) {
+			mock := &MockPipeline{status: status, actionCh: make(chan pipeline.Action, 1)}
+			setSession(daemon, mock)
+
+			resp := sendRequest(t, daemon, `{"cmd":"start"}`+"\n")
+			if !resp.OK || resp.Result != "already_recording" {
+				t.Errorf("response = %+v", resp)
+			}
+			if cur := daemon.current(); cur == nil || cur.pipeline != mock {
+				t.Errorf("start replaced the running pipeline")
+			}
+			if len(mock.actionCh) != 0 {
+				t.Errorf("start sent an action to the running pipeline")
+			}
+		})
+
+		t.Run("stop_while_"+string(status), func(t *testing.T) {
+			mock := &MockPipeline{status: status, actionCh: make(chan pipeline.Action, 1)}
+			setSession(daemon, mock)
+
+			resp := sendRequest(t, daemon, `{"cmd":"stop"}`+"\n")
+			if !resp.OK || resp.Result != "stopping" {
+				t.Errorf("response = %+v", resp)
+			}
+			select {
+			case action := <-mock.actionCh:
+				if action != pipeline.Inject {
+					t.Errorf("action = %s, want %s", action, pipeline.Inject)
+				}
+			default:
+				t.Errorf("stop did not send the inject action")
+			}
+			if cur := daemon.current(); cur == nil || cur.pipeline != mock {
+				t.Errorf("stop aborted the running pipeline")
+			}
+		})
+	}
+
+	t.Run("repeated_stop", func(t *testing.T) {
+		mock := &MockPipeline{status: pipeline.Transcribing, actionCh: make(chan pipeline.Action, 1)}
+		setSession(daemon, mock)
+
+		sendRequest(t, daemon, `{"cmd":"stop"}`+"\n")
+		resp := sendRequest(t, daemon, `{"cmd":"stop"}`+"\n")
+		if !resp.OK || resp.Result != "not_recording" {
+			t.Errorf("second stop response = %+v", resp)
+		}
+	})
+
+	t.Run("repeated_toggle_while_transcribing", func(t *testing.T) {
+		mock := &MockPipeline{status: pipeline.Transcribing, actionCh: make(chan pipeline.Action, 1)}
+		setSession(daemon, mock)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			daemon.toggle(nil)
+			daemon.toggle(nil)
+		}()
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("second toggle blocked on the pending inject action")
+		}
+		if len(mock.actionCh) != 1 {
+			t.Errorf("pending actions = %d, want 1", len(mock.actionCh))
+		}
+	})
+
+	t.Run("stop_while_injecting", func(t *testing.T) {
+		mock := &MockPipeline{status: pipeline.Injecting, actionCh: make(chan pipeline.Action, 1)}
+		setSession(daemon, mock)
+
+		resp := sendRequest(t, daemon, `{"cmd":"stop"}`+"\n")
+		if !resp.OK || resp.Result != "not_recording" || len(mock.actionCh) != 0 {
+			t.Errorf("response = %+v", resp)
+		}
+	})
+}
+
+func TestDaemon_Last(t *testing.T) {
+	// Set up a temporary config directory
+	tempDir := t.TempDir()
+	originalConfigDir := os.Getenv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", tempDir)
+	defer func() {
+		if originalConfigDir == "" {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		} else {
+			os.Setenv("XDG_CONFIG_HOME", originalConfigDir)
+		}
+	}()
+
+	// Create a basic config file
+	configPath := filepath.Join(tempDir, "hyprvoice", "config.toml")
+	os.MkdirAll(filepath.Dir(configPath), 0755)
+	os.WriteFile(configPath, []byte(testConfigContent), 0644)
+
+	daemon, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	defer daemon.cancel()
+
+	t.Run("nothing_dictated", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"cmd":"last","args":{"action":"reinject"}}`+"\n")
+		if resp.OK || resp.Error == "" {
+			t.Errorf("response = %+v", resp)
+		}
+	})
+
+	t.Run("unknown_action", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"cmd":"last","args":{"action":"redo"}}`+"\n")
+		if resp.OK || resp.Error == "" {
+			t.Errorf("response = %+v", resp)
+		}
+	})
+
+	t.Run("remembers_pipeline_injection", func(t *testing.T) {
+		mock := &MockPipeline{injectedCh: make(chan injection.Injection, 1)}
+		go daemon.monitorPipelineEvents(&session{pipeline: mock, done: make(chan struct{})})
+
+		mock.injectedCh <- injection.Injection{Text: "hello", Backend: "wtype"}
+		deadline := time.Now().Add(2 * time.Second)
+		for daemon.getLastInjection() == nil && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		last := daemon.getLastInjection()
+		if last == nil || last.Text != "hello" || last.Backend != "wtype" {
+			t.Fatalf("last injection = %+v", last)
+		}
+	})
+
+	t.Run("keeps_injection_sent_as_pipeline_ends", func(t *testing.T) {
+		mock := &MockPipeline{injectedCh: make(chan injection.Injection, 1)}
+		mock.injectedCh <- injection.Injection{Text: "at shutdown", Backend: "wtype"}
+		s := &session{pipeline: mock, done: make(chan struct{})}
+		close(s.done)
+
+		// returns once done is closed, after delivering what is pending
+		daemon.monitorPipelineEvents(s)
+
+		if last := daemon.getLastInjection(); last == nil || last.Text != "at shutdown" {
+			t.Fatalf("last injection = %+v", last)
+		}
+	})
+
+	t.Run("undo_without_backend", func(t *testing.T) {
+		daemon.setLastInjection(&injection.Injection{Text: "lost text"})
+		resp := sendRequest(t, daemon, `{"cmd":"last","args":{"action":"undo"}}`+"\n")
+		if resp.OK || resp.Error == "" {
+			t.Errorf("response = %+v", resp)
+		}
+	})
+}
+
+func TestDaemon_Overrides(t *testing.T) {
+	// Set up a temporary config directory
+	tempDir := t.TempDir()
+	originalConfigDir := os.Getenv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", tempDir)
+	defer func() {
+		if originalConfigDir == "" {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		} else {
+			os.Setenv("XDG_CONFIG_HOME", originalConfigDir)
+		}
+	}()
+
+	// Create a basic config file
+	configPath := filepath.Join(tempDir, "hyprvoice", "config.toml")
+	os.MkdirAll(filepath.Dir(configPath), 0755)
+	os.WriteFile(configPath, []byte(testConfigContent), 0644)
+
+	daemon, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+
+	t.Run("set", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"cmd":"set","args":{"language":"fr","backend":"clipboard"}}`+"\n")
+		if !resp.OK || resp.Overrides["language"] != "fr" || resp.Overrides["backend"] != "clipboard" {
+			t.Fatalf("response = %+v", resp)
+		}
+
+		conf, err := daemon.effectiveConfig(nil)
+		if err != nil {
+			t.Fatalf("effectiveConfig() error = %v", err)
+		}
+		if conf.Transcription.Language != "fr" || len(conf.Injection.Backends) != 1 {
+			t.Errorf("effective config = %+v / %v", conf.Transcription, conf.Injection.Backends)
+		}
+		// config.toml is untouched
+		if daemon.configMgr.GetConfig().Transcription.Language != "" {
+			t.Errorf("set changed the loaded config")
+		}
+
+		status := sendRequest(t, daemon, `{"cmd":"status"}`+"\n")
+		if status.Status == nil || status.Status.Overrides["language"] != "fr" {
+			t.Errorf("status = %+v", status.Status)
+		}
+	})
+
+	t.Run("per_invocation_wins", func(t *testing.T) {
+		conf, err := daemon.effectiveConfig(config.Overrides{"language": "de"})
+		if err != nil {
+			t.Fatalf("effectiveConfig() error = %v", err)
+		}
+		if conf.Transcription.Language != "de" {
+			t.Errorf("language = %q, want de", conf.Transcription.Language)
+		}
+	})
+
+	t.Run("invalid_set_rejected", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"cmd":"set","args":{"model":"no-such-model"}}`+"\n")
+		if resp.OK || resp.Error == "" || resp.Overrides["language"] != "fr" || resp.Overrides["model"] != "" {
+			t.Errorf("response = %+v", resp)
+		}
+	})
+
+	t.Run("unknown_profile_rejected", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"cmd":"set","args":{"profile":"email"}}`+"\n")
+		if resp.OK || !strings.Contains(resp.Error, "unknown profile") || resp.Overrides["profile"] != "" {
+			t.Errorf("response = %+v", resp)
+		}
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"cmd":"set","args":{"language":"","backend":""}}`+"\n")
+		if !resp.OK || len(resp.Overrides) != 0 {
+			t.Errorf("response = %+v", resp)
+		}
+	})
+
+	t.Run("toggle_with_invalid_override", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"cmd":"toggle","args":{"llm":"maybe"}}`+"\n")
+		if resp.OK || resp.Error == "" {
+			t.Errorf("response = %+v", resp)
+		}
+		resp = sendRequest(t, daemon, `{"cmd":"start","args":{"model":"no-such-model"}}`+"\n")
+		if resp.OK || resp.Error == "" {
+			t.Errorf("response = %+v", resp)
+		}
+		if status := daemon.status(); status != pipeline.Idle {
+			t.Errorf("status = %s, want idle", status)
+		}
+	})
+}
+
+func TestDaemon_WindowRules(t *testing.T) {
+	// Set up a temporary config directory
+	tempDir := t.TempDir()
+	originalConfigDir := os.Getenv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", tempDir)
+	defer func() {
+		if originalConfigDir == "" {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		} else {
+			os.Setenv("XDG_CONFIG_HOME", originalConfigDir)
+		}
+	}()
+
+	configContent := testConfigContent + `
+
+[profiles.terminal.llm]
+enabled = false
+[profiles.terminal.injection]
+backends = ["wtype"]
+
+[profiles.chat.transcription]
+language = "en"
+
+[[window_rules]]
+class = "^(kitty|foot)$"
+profile = "terminal"
+
+[[window_rules]]
+class = "^Slack$"
+profile = "chat"
+`
+	configPath := filepath.Join(tempDir, "hyprvoice", "config.toml")
+	os.MkdirAll(filepath.Dir(configPath), 0755)
+	os.WriteFile(configPath, []byte(configContent), 0644)
+
+	daemon, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+
+	window := hyprland.Window{Class: "kitty", Title: "~"}
+	var windowErr error
+	queries := 0
+	daemon.activeWindow = func(ctx context.Context) (hyprland.Window, error) {
+		queries++
+		return window, windowErr
+	}
+
+	t.Run("matching_window", func(t *testing.T) {
+		overrides := daemon.withWindowProfile(config.Overrides{config.OverrideLanguage: "de"})
+		if overrides[config.OverrideProfile] != "terminal" || overrides[config.OverrideLanguage] != "de" {
+			t.Fatalf("overrides = %v", overrides)
+		}
+		conf, err := daemon.effectiveConfig(overrides)
+		if err != nil {
+			t.Fatalf("effectiveConfig() error = %v", err)
+		}
+		if len(conf.Injection.Backends) != 1 || conf.Injection.Backends[0] != "wtype" {
+			t.Errorf("backends = %v, want [wtype]", conf.Injection.Backends)
+		}
+	})
+
+	t.Run("no_match", func(t *testing.T) {
+		window = hyprland.Window{Class: "firefox"}
+		if overrides := daemon.withWindowProfile(nil); len(overrides) != 0 {
+			t.Errorf("overrides = %v, want none", overrides)
+		}
+	})
+
+	t.Run("query_failure", func(t *testing.T) {
+		windowErr = fmt.Errorf("not running under Hyprland")
+		defer func() { windowErr = nil }()
+		if overrides := daemon.withWindowProfile(nil); len(overrides) != 0 {
+			t.Errorf("overrides = %v, want none", overrides)
+		}
+	})
+
+	t.Run("explicit_profile_wins", func(t *testing.T) {
+		window = hyprland.Window{Class: "Slack"}
+		queries = 0
+		overrides := daemon.withWindowProfile(config.Overrides{config.OverrideProfile: "terminal"})
+		if overrides[config.OverrideProfile] != "terminal" || queries != 0 {
+			t.Errorf("overrides = %v after %d queries", overrides, queries)
+		}
+
+		if _, err := daemon.set(map[string]string{config.OverrideProfile: "terminal"}); err != nil {
+			t.Fatalf("set() error = %v", err)
+		}
+		if overrides := daemon.withWindowProfile(nil); overrides[config.OverrideProfile] != "" || queries != 0 {
+			t.Errorf("overrides = %v after %d queries, want the sticky profile kept", overrides, queries)
+		}
+	})
+}
+
+// runningPipeline is a MockPipeline whose run lasts until Stop
+type runningPipeline struct {
+	MockPipeline
+	done chan struct{}
+	once sync.Once
+
+	mu sync.Mutex
+}
+
+func (p *runningPipeline) Stop() { p.once.Do(func() { close(p.done) }) }
+func (p *runningPipeline) Wait() { <-p.done }
+
+func (p *runningPipeline) Status() pipeline.Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.MockPipeline.Status()
+}
+
+func (p *runningPipeline) setStatus(status pipeline.Status) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status = status
+}
+
+func TestDaemon_OverlappingSessions(t *testing.T) {
+	// Set up a temporary config directory
+	tempDir := t.TempDir()
+	originalConfigDir := os.Getenv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", tempDir)
+	defer func() {
+		if originalConfigDir == "" {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		} else {
+			os.Setenv("XDG_CONFIG_HOME", originalConfigDir)
+		}
+	}()
+
+	// Create a basic config file
+	configPath := filepath.Join(tempDir, "hyprvoice", "config.toml")
+	os.MkdirAll(filepath.Dir(configPath), 0755)
+	os.WriteFile(configPath, []byte(testConfigContent), 0644)
+
+	daemon, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	defer daemon.cancel()
+
+	var created []*runningPipeline
+	daemon.newPipeline = func(cfg *config.Config, opts ...pipeline.Option) pipeline.Pipeline {
+		p := &runningPipeline{
+			MockPipeline: MockPipeline{status: pipeline.Recording, actionCh: make(chan pipeline.Action, 1)},
+			done:         make(chan struct{}),
+		}
+		created = append(created, p)
+		return p
+	}
+
+	// the first dictation is waiting for the LLM
+	if err := daemon.toggle(nil); err != nil {
+		t.Fatalf("toggle() error = %v", err)
+	}
+	created[0].setStatus(pipeline.Processing)
+
+	t.Run("toggle_starts_while_processing", func(t *testing.T) {
+		if err := daemon.toggle(nil); err != nil {
+			t.Fatalf("toggle() error = %v", err)
+		}
+		if len(created) != 2 {
+			t.Fatalf("created %d pipelines, want 2", len(created))
+		}
+		if cur := daemon.current(); cur == nil || cur.pipeline != created[1] {
+			t.Errorf("current session is not the new dictation")
+		}
+		if len(created[0].actionCh) != 0 {
+			t.Errorf("toggle sent an action to the processing dictation")
+		}
+
+		info := sendRequest(t, daemon, `{"cmd":"status"}`+"\n").Status
+		if info == nil || info.Status != "recording" || info.Queue != 1 || len(info.Sessions) != 2 {
+			t.Fatalf("status = %+v, want recording with 1 queued", info)
+		}
+		if info.Sessions[0].ID != 1 || info.Sessions[0].Status != "processing" || info.Sessions[1].ID != 2 {
+			t.Errorf("sessions = %+v", info.Sessions)
+		}
+	})
+
+	t.Run("start_ignored_while_recording", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"cmd":"start"}`+"\n")
+		if !resp.OK || resp.Result != "already_recording" || len(created) != 2 {
+			t.Errorf("response = %+v", resp)
+		}
+	})
+
+	t.Run("toggle_aborts_only_the_recording_one", func(t *testing.T) {
+		if err := daemon.toggle(nil); err != nil {
+			t.Fatalf("toggle() error = %v", err)
+		}
+		if running := daemon.runningSessions(); len(running) != 1 || running[0].pipeline != created[0] {
+			t.Fatalf("running sessions = %d, want only the processing one", len(running))
+		}
+	})
+
+	t.Run("start_while_processing", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"cmd":"start"}`+"\n")
+		if !resp.OK || resp.Result != "started" || len(created) != 3 {
+			t.Errorf("response = %+v", resp)
+		}
+	})
+
+	t.Run("cancel_by_id", func(t *testing.T) {
+		if resp := sendRequest(t, daemon, `{"cmd":"cancel","args":{"session":"9"}}`+"\n"); resp.OK {
+			t.Errorf("cancel of an unknown session = %+v", resp)
+		}
+		if resp := sendRequest(t, daemon, `{"cmd":"cancel","args":{"session":"x"}}`+"\n"); resp.OK {
+			t.Errorf("cancel of an invalid session = %+v", resp)
+		}
+
+		resp := sendRequest(t, daemon, `{"cmd":"cancel","args":{"session":"1"}}`+"\n")
+		if !resp.OK || resp.Result != "cancelled" {
+			t.Fatalf("response = %+v", resp)
+		}
+		if running := daemon.runningSessions(); len(running) != 1 || running[0].id != 3 {
+			t.Errorf("running sessions = %d, want only session 3", len(running))
+		}
+		select {
+		case <-created[0].done:
+		default:
+			t.Errorf("cancel did not stop session 1")
+		}
+	})
+
+	t.Run("cancel_all", func(t *testing.T) {
+		created[2].setStatus(pipeline.Injecting)
+		daemon.toggle(nil)
+		if n := len(daemon.runningSessions()); n != 2 {
+			t.Fatalf("running sessions = %d, want 2", n)
+		}
+
+		resp := sendRequest(t, daemon, `{"cmd":"cancel","args":{"session":"all"}}`+"\n")
+		if !resp.OK || len(daemon.runningSessions()) != 0 || daemon.status() != pipeline.Idle {
+			t.Errorf("response = %+v, %d sessions left", resp, len(daemon.runningSessions()))
+		}
+	})
+}
+
+func (p *runningPipeline) Result() pipeline.Result {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.MockPipeline.Result()
+}
+
+// end ends the run with r as its outcome
+func (p *runningPipeline) end(r pipeline.Result) {
+	p.mu.Lock()
+	p.result = r
+	p.status = pipeline.Idle
+	p.mu.Unlock()
+	p.Stop()
+}
+
+func TestDaemon_Wait(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	configPath := filepath.Join(tempDir, "hyprvoice", "config.toml")
+	os.MkdirAll(filepath.Dir(configPath), 0755)
+	os.WriteFile(configPath, []byte(testConfigContent), 0644)
+
+	daemon, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create daemon: %v", err)
+	}
+	defer daemon.cancel()
+
+	// every dictation ends with the next result once it is told to inject
+	var results []pipeline.Result
+	daemon.newPipeline = func(cfg *config.Config, opts ...pipeline.Option) pipeline.Pipeline {
+		p := &runningPipeline{
+			MockPipeline: MockPipeline{status: pipeline.Transcribing, actionCh: make(chan pipeline.Action, 1)},
+			done:         make(chan struct{}),
+		}
+		r := results[0]
+		results = results[1:]
+		go func() {
+			select {
+			case <-p.actionCh:
+				p.end(r)
+			case <-p.done:
+			}
+		}()
+		return p
+	}
+
+	t.Run("stop_without_recording", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"cmd":"stop","args":{"wait":"true"}}`+"\n")
+		if resp.OK || resp.Error != "not recording" || resp.Dictation != nil {
+			t.Errorf("response = %+v", resp)
+		}
+	})
+
+	t.Run("toggle_injected", func(t *testing.T) {
+		results = append(results, pipeline.Result{Transcript: "um hello", Processed: "Hello.", Text: "Hello.", Backend: "wtype", Injected: true})
+		if err := daemon.toggle(config.Overrides{config.OverrideLanguage: "de"}); err != nil {
+			t.Fatalf("toggle() error = %v", err)
+		}
+
+		resp := sendRequest(t, daemon, `{"cmd":"toggle","args":{"wait":"true"}}`+"\n")
+		if !resp.OK || resp.Result != "injected" || resp.Dictation == nil {
+			t.Fatalf("response = %+v", resp)
+		}
+		if d := resp.Dictation; d.Session != 1 || d.Transcript != "um hello" || d.Text != "Hello." || d.Backend != "wtype" {
+			t.Errorf("dictation = %+v", d)
+		}
+	})
+
+	t.Run("stop_llm_error", func(t *testing.T) {
+		results = append(results, pipeline.Result{Transcript: "hello", Text: "hello", Injected: true, Errors: []string{"LLM processing failed: timeout"}})
+		if resp := sendRequest(t, daemon, `{"cmd":"start","args":{"language":"de"}}`+"\n"); !resp.OK {
+			t.Fatalf("start response = %+v", resp)
+		}
+
+		resp := sendRequest(t, daemon, `{"cmd":"stop","args":{"wait":"true"}}`+"\n")
+		if resp.OK || resp.Result != "failed" || !strings.Contains(resp.Error, "timeout") {
+			t.Fatalf("response = %+v", resp)
+		}
+		if d := resp.Dictation; d == nil || d.Session != 2 || d.Text != "hello" || !d.Injected {
+			t.Errorf("dictation = %+v", d)
+		}
+	})
+
+	t.Run("invalid_wait_overrides", func(t *testing.T) {
+		resp := sendRequest(t, daemon, `{"cmd":"toggle","args":{"wait":"true","bogus":"1"}}`+"\n")
+		if resp.OK || resp.Dictation != nil {
+			t.Errorf("response = %+v", resp)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package daemon
+
+import (
+	"github.com/leonardotrapani/hyprvoice/internal/bus"
+	"github.com/leonardotrapani/hyprvoice/internal/dbus"
+)
+
+// dbusHandler runs D-Bus method calls through the same paths as the socket
+type dbusHandler struct {
+	d *Daemon
+}
+
+func (h dbusHandler) Toggle() error          { return h.d.toggle(nil) }
+func (h dbusHandler) Start() (bool, error)   { return h.d.start(nil) }
+func (h dbusHandler) Stop() bool             { return h.d.finish() }
+func (h dbusHandler) Status() bus.StatusInfo { return h.d.statusInfo() }
+
+func (h dbusHandler) Cancel() error {
+	_, err := h.d.cancelSession("")
+	return err
+}
+
+// serveDBus exports the daemon on the session bus and forwards events as
+// signals until the daemon stops. Without a session bus only the socket is served.
+func (d *Daemon) serveDBus() {
+	if name := bus.Instance(); name != "" {
+		logger.Info("D-Bus interface disabled, only the default instance exports it", "instance", name, "service", dbus.ServiceName)
+		return
+	}
+
+	address, err := dbus.SessionBusAddress()
+	if err != nil {
+		logger.Info("D-Bus interface disabled", "err", err)
+		return
+	}
+
+	svc, err := dbus.Export(address, dbusHandler{d: d})
+	if err != nil {
+		logger.Warn("Failed to export D-Bus interface", "err", err)
+		return
+	}
+	defer svc.Close()
+
+	subID, events := d.events.subscribe()
+	defer d.events.unsubscribe(subID)
+
+	logger.Info("D-Bus interface exported", "service", dbus.ServiceName)
+
+	// StateChanged follows the daemon status, which stays the same while
+	// background sessions move on
+	lastStatus := string(d.status())
+	for {
+		select {
+		case ev := <-events:
+			if ev.Type == bus.EventStatus {
+				if ev.Status == lastStatus {
+					continue
+				}
+				lastStatus = ev.Status
+			}
+			if err := svc.Emit(ev); err != nil {
+				logger.Warn("Failed to emit D-Bus signal", "event", ev.Type, "err", err)
+			}
+		case <-d.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package daemon
+
+import (
+	"sync"
+	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/bus"
+)
+
+// eventHub fans out daemon events to subscribed clients
+type eventHub struct {
+	mu     sync.Mutex
+	nextID int
+	subs   map[int]chan bus.Event
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[int]chan bus.Event)}
+}
+
+func (h *eventHub) subscribe() (int, <-chan bus.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	id := h.nextID
+	h.nextID++
+	ch := make(chan bus.Event, 64)
+	h.subs[id] = ch
+	return id, ch
+}
+
+func (h *eventHub) unsubscribe(id int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subs, id)
+}
+
+// publish delivers ev to every subscriber without blocking; slow
+// subscribers miss events rather than stalling the daemon.
+func (h *eventHub) publish(ev bus.Event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for id, ch := range h.subs {
+		select {
+		case ch <- ev:
+		default:
+			logger.Warn("Subscriber is not keeping up, dropping event", "subscriber", id, "event", ev.Type)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package dbus
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/leonardotrapani/hyprvoice/internal/bus"
+)
+
+const testBusConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-BUS Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:path=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// startBus runs a private dbus-daemon for the test and returns its address
+func startBus(t *testing.T) string {
+	t.Helper()
+
+	daemonPath, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not available")
+	}
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "bus.conf")
+	config := fmt.Sprintf(testBusConfig, filepath.Join(dir, "bus"))
+	if err := os.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(daemonPath, "--config-file="+configPath, "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read bus address: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
+
+// fakeHandler is called from the godbus worker goroutine, so every field
+// is guarded by mu
+type fakeHandler struct {
+	mu     sync.Mutex
+	calls  []string
+	status string
+	err    error // returned by Toggle, Start and Cancel
+}
+
+// record notes call and returns the current status and error
+func (h *fakeHandler) record(call string) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls = append(h.calls, call)
+	return h.status, h.err
+}
+
+func (h *fakeHandler) setErr(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.err = err
+}
+
+func (h *fakeHandler) Toggle() error {
+	_, err := h.record("toggle")
+	return err
+}
+
+func (h *fakeHandler) Start() (bool, error) {
+	status, err := h.record("start")
+	if err != nil {
+		return false, err
+	}
+	return status == "idle", nil
+}
+
+func (h *fakeHandler) Stop() bool {
+	status, _ := h.record("stop")
+	return status == "recording"
+}
+
+func (h *fakeHandler) Cancel() error {
+	_, err := h.record("cancel")
+	return err
+}
+
+func (h *fakeHandler) Status() bus.StatusInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return bus.StatusInfo{Status: h.status, Provider: "openai", Model: "whisper-1", Version: "test"}
+}
+
+func TestService(t *testing.T) {
+	address := startBus(t)
+
+	h := &fakeHandler{status: "idle"}
+	svc, err := Export(address, h)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	defer svc.Close()
+
+	client, err := godbus.Connect(address)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	obj := client.Object(ServiceName, ObjectPath)
+	call := func(member string, args ...any) *godbus.Call {
+		return obj.CallWithContext(ctx, member, 0, args...)
+	}
+
+	t.Run("methods", func(t *testing.T) {
+		if err := call(Interface + ".Toggle").Err; err != nil {
+			t.Errorf("Toggle error = %v", err)
+		}
+		var result string
+		if err := call(Interface + ".Start").Store(&result); err != nil || result != "started" {
+			t.Errorf("Start = %v, %v", result, err)
+		}
+		if err := call(Interface + ".Stop").Store(&result); err != nil || result != "not_recording" {
+			t.Errorf("Stop = %v, %v", result, err)
+		}
+		if err := call(Interface + ".Cancel").Err; err != nil {
+			t.Errorf("Cancel error = %v", err)
+		}
+
+		h.mu.Lock()
+		calls := strings.Join(h.calls, ",")
+		h.mu.Unlock()
+		if calls != "toggle,start,stop,cancel" {
+			t.Errorf("handler calls = %s", calls)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		h.setErr(errors.New("legacy config"))
+		defer h.setErr(nil)
+
+		var dbusErr godbus.Error
+		for _, method := range []string{"Toggle", "Start", "Cancel"} {
+			if err := call(Interface + "." + method).Err; !errors.As(err, &dbusErr) || dbusErr.Name != ErrorFailed || dbusErr.Error() != "legacy config" {
+				t.Errorf("%s error = %v", method, err)
+			}
+		}
+		if err := call(Interface + ".Explode").Err; !errors.As(err, &dbusErr) || dbusErr.Name != "org.freedesktop.DBus.Error.UnknownMethod" {
+			t.Errorf("unknown method error = %v", err)
+		}
+	})
+
+	t.Run("properties", func(t *testing.T) {
+		status, err := obj.GetProperty(Interface + ".Status")
+		if err != nil || status.Value() != "idle" {
+			t.Errorf("Get(Status) = %v, %v", status, err)
+		}
+
+		var props map[string]godbus.Variant
+		if err := call(propertiesInterface+".GetAll", Interface).Store(&props); err != nil {
+			t.Fatalf("GetAll error = %v", err)
+		}
+		if props["Model"].Value() != "whisper-1" || props["Version"].Value() != "test" {
+			t.Errorf("GetAll = %v", props)
+		}
+
+		if err := obj.SetProperty(Interface+".Status", godbus.MakeVariant("x")); err == nil {
+			t.Errorf("Set succeeded on read-only property")
+		}
+	})
+
+	t.Run("introspect", func(t *testing.T) {
+		var xml string
+		if err := call(introspectableInterface + ".Introspect").Store(&xml); err != nil || !strings.Contains(xml, `<interface name="org.hyprvoice.Daemon1">`) {
+			t.Errorf("Introspect = %v, %v", xml, err)
+		}
+	})
+
+	t.Run("signals", func(t *testing.T) {
+		if err := client.AddMatchSignalContext(ctx, godbus.WithMatchObjectPath(ObjectPath)); err != nil {
+			t.Fatalf("AddMatchSignal error = %v", err)
+		}
+		signals := make(chan *godbus.Signal, 10)
+		client.Signal(signals)
+
+		if err := svc.Emit(bus.Event{Type: bus.EventStatus, Status: "recording"}); err != nil {
+			t.Fatalf("Emit(status) error = %v", err)
+		}
+		if err := svc.Emit(bus.Event{Type: bus.EventTranscript, Text: "hello", Final: true}); err != nil {
+			t.Fatalf("Emit(transcript) error = %v", err)
+		}
+
+		var got []string
+		for len(got) < 3 {
+			select {
+			case sig := <-signals:
+				if sig.Path != ObjectPath {
+					continue
+				}
+				got = append(got, fmt.Sprintf("%s%v", sig.Name, sig.Body))
+			case <-ctx.Done():
+				t.Fatalf("timed out waiting for signals, got %v", got)
+			}
+		}
+
+		want := []string{
+			"org.hyprvoice.Daemon1.StateChanged[recording]",
+			`org.freedesktop.DBus.Properties.PropertiesChanged[org.hyprvoice.Daemon1 map[Status:"recording"] []]`,
+			"org.hyprvoice.Daemon1.Transcript[hello true]",
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("signal %d = %s, want %s", i, got[i], want[i])
+			}
+		}
+	})
+
+	t.Run("name taken", func(t *testing.T) {
+		if _, err := Export(address, h); err == nil {
+			t.Errorf("second Export() succeeded while the name is owned")
+		}
+	})
+}
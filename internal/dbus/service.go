@@ -0,0 +1,278 @@
+package dbus
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+	"github.com/leonardotrapani/hyprvoice/internal/bus"
+)
+
+// Names under which the daemon is exported on the session bus
+const (
+	ServiceName = "org.hyprvoice.Daemon1"
+	ObjectPath  = "/org/hyprvoice/Daemon1"
+	Interface   = "org.hyprvoice.Daemon1"
+
+	// ErrorFailed is returned when a daemon command fails
+	ErrorFailed = "org.hyprvoice.Daemon1.Error.Failed"
+)
+
+const (
+	propertiesInterface     = "org.freedesktop.DBus.Properties"
+	introspectableInterface = "org.freedesktop.DBus.Introspectable"
+)
+
+// requestTimeout bounds calls made to the bus daemon itself
+const requestTimeout = 5 * time.Second
+
+// Handler runs daemon commands on behalf of D-Bus callers
+type Handler interface {
+	Toggle() error
+	Start() (bool, error)
+	Stop() bool
+	Cancel() error
+	Status() bus.StatusInfo
+}
+
+// SessionBusAddress returns the session bus address from
+// DBUS_SESSION_BUS_ADDRESS, falling back to $XDG_RUNTIME_DIR/bus
+func SessionBusAddress() (string, error) {
+	if addr := os.Getenv("DBUS_SESSION_BUS_ADDRESS"); addr != "" {
+		return addr, nil
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		path := filepath.Join(dir, "bus")
+		if _, err := os.Stat(path); err == nil {
+			return "unix:path=" + path, nil
+		}
+	}
+	return "", fmt.Errorf("no session bus: DBUS_SESSION_BUS_ADDRESS is not set")
+}
+
+const introspectXML = `<!DOCTYPE node PUBLIC "-//freedesktop//DTD D-BUS Object Introspection 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/introspect.dtd">
+<node>
+  <interface name="org.hyprvoice.Daemon1">
+    <method name="Toggle"/>
+    <method name="Start">
+      <arg name="result" type="s" direction="out"/>
+    </method>
+    <method name="Stop">
+      <arg name="result" type="s" direction="out"/>
+    </method>
+    <method name="Cancel"/>
+    <property name="Status" type="s" access="read">
+      <annotation name="org.freedesktop.DBus.Property.EmitsChangedSignal" value="true"/>
+    </property>
+    <property name="Provider" type="s" access="read"/>
+    <property name="Model" type="s" access="read"/>
+    <property name="LastError" type="s" access="read"/>
+    <property name="Version" type="s" access="read"/>
+    <signal name="StateChanged">
+      <arg name="status" type="s"/>
+    </signal>
+    <signal name="Transcript">
+      <arg name="text" type="s"/>
+      <arg name="final" type="b"/>
+    </signal>
+    <signal name="Error">
+      <arg name="title" type="s"/>
+      <arg name="message" type="s"/>
+    </signal>
+  </interface>
+  <interface name="org.freedesktop.DBus.Properties">
+    <method name="Get">
+      <arg name="interface" type="s" direction="in"/>
+      <arg name="name" type="s" direction="in"/>
+      <arg name="value" type="v" direction="out"/>
+    </method>
+    <method name="GetAll">
+      <arg name="interface" type="s" direction="in"/>
+      <arg name="properties" type="a{sv}" direction="out"/>
+    </method>
+    <method name="Set">
+      <arg name="interface" type="s" direction="in"/>
+      <arg name="name" type="s" direction="in"/>
+      <arg name="value" type="v" direction="in"/>
+    </method>
+    <signal name="PropertiesChanged">
+      <arg name="interface" type="s"/>
+      <arg name="changed" type="a{sv}"/>
+      <arg name="invalidated" type="as"/>
+    </signal>
+  </interface>
+  <interface name="org.freedesktop.DBus.Introspectable">
+    <method name="Introspect">
+      <arg name="xml" type="s" direction="out"/>
+    </method>
+  </interface>
+  <interface name="org.freedesktop.DBus.Peer">
+    <method name="Ping"/>
+  </interface>
+</node>
+`
+
+// Service exports a Handler as org.hyprvoice.Daemon1 on a message bus
+type Service struct {
+	conn *godbus.Conn
+}
+
+// Export connects to the bus at address, serves h at ObjectPath and
+// takes ownership of ServiceName
+func Export(address string, h Handler) (*Service, error) {
+	conn, err := godbus.Connect(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to session bus: %w", err)
+	}
+
+	exports := []struct {
+		v     any
+		iface string
+	}{
+		{daemonObject{h}, Interface},
+		{propertiesObject{h}, propertiesInterface},
+		{introspect.Introspectable(introspectXML), introspectableInterface},
+	}
+	for _, e := range exports {
+		if err := conn.Export(e.v, ObjectPath, e.iface); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to export %s: %w", e.iface, err)
+		}
+	}
+
+	if err := requestName(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &Service{conn: conn}, nil
+}
+
+func requestName(conn *godbus.Conn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	var reply godbus.RequestNameReply
+	call := conn.BusObject().CallWithContext(ctx, "org.freedesktop.DBus.RequestName", 0, ServiceName, uint32(godbus.NameFlagDoNotQueue))
+	if err := call.Store(&reply); err != nil {
+		return fmt.Errorf("failed to request name %s: %w", ServiceName, err)
+	}
+	if reply != godbus.RequestNameReplyPrimaryOwner {
+		return fmt.Errorf("name %s is already owned on the bus", ServiceName)
+	}
+	return nil
+}
+
+// Close releases the bus name and disconnects
+func (s *Service) Close() error {
+	return s.conn.Close()
+}
+
+// Emit publishes a daemon event as D-Bus signals. Status changes also emit
+// PropertiesChanged so property bindings update without polling.
+func (s *Service) Emit(ev bus.Event) error {
+	switch ev.Type {
+	case bus.EventStatus:
+		if err := s.conn.Emit(ObjectPath, Interface+".StateChanged", ev.Status); err != nil {
+			return err
+		}
+		return s.propertyChanged("Status", ev.Status)
+	case bus.EventTranscript:
+		return s.conn.Emit(ObjectPath, Interface+".Transcript", ev.Text, ev.Final)
+	case bus.EventError:
+		if err := s.conn.Emit(ObjectPath, Interface+".Error", ev.Title, ev.Message); err != nil {
+			return err
+		}
+		return s.propertyChanged("LastError", ev.Message)
+	}
+	return nil
+}
+
+func (s *Service) propertyChanged(name, value string) error {
+	changed := map[string]godbus.Variant{name: godbus.MakeVariant(value)}
+	return s.conn.Emit(ObjectPath, propertiesInterface+".PropertiesChanged", Interface, changed, []string{})
+}
+
+// daemonObject serves org.hyprvoice.Daemon1 methods. Results match the
+// JSON protocol so scripts can switch transports without changes.
+type daemonObject struct {
+	h Handler
+}
+
+func (o daemonObject) Toggle() *godbus.Error {
+	if err := o.h.Toggle(); err != nil {
+		return godbus.NewError(ErrorFailed, []any{err.Error()})
+	}
+	return nil
+}
+
+func (o daemonObject) Start() (string, *godbus.Error) {
+	started, err := o.h.Start()
+	switch {
+	case err != nil:
+		return "", godbus.NewError(ErrorFailed, []any{err.Error()})
+	case started:
+		return "started", nil
+	default:
+		return "already_recording", nil
+	}
+}
+
+func (o daemonObject) Stop() (string, *godbus.Error) {
+	if o.h.Stop() {
+		return "stopping", nil
+	}
+	return "not_recording", nil
+}
+
+func (o daemonObject) Cancel() *godbus.Error {
+	if err := o.h.Cancel(); err != nil {
+		return godbus.NewError(ErrorFailed, []any{err.Error()})
+	}
+	return nil
+}
+
+// propertiesObject serves org.freedesktop.DBus.Properties from the live
+// daemon status, so properties never go stale between signals
+type propertiesObject struct {
+	h Handler
+}
+
+func (o propertiesObject) Get(iface, name string) (godbus.Variant, *godbus.Error) {
+	props, err := o.GetAll(iface)
+	if err != nil {
+		return godbus.Variant{}, err
+	}
+	value, ok := props[name]
+	if !ok {
+		return godbus.Variant{}, prop.ErrPropNotFound
+	}
+	return value, nil
+}
+
+func (o propertiesObject) GetAll(iface string) (map[string]godbus.Variant, *godbus.Error) {
+	if iface != Interface {
+		return nil, prop.ErrIfaceNotFound
+	}
+	info := o.h.Status()
+	return map[string]godbus.Variant{
+		"Status":    godbus.MakeVariant(info.Status),
+		"Provider":  godbus.MakeVariant(info.Provider),
+		"Model":     godbus.MakeVariant(info.Model),
+		"LastError": godbus.MakeVariant(info.LastError),
+		"Version":   godbus.MakeVariant(info.Version),
+	}, nil
+}
+
+func (o propertiesObject) Set(iface, name string, value godbus.Variant) *godbus.Error {
+	if iface != Interface {
+		return prop.ErrIfaceNotFound
+	}
+	return prop.ErrReadOnly
+}
@@ -0,0 +1,419 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/leonardotrapani/hyprvoice/internal/bus"
+	"github.com/leonardotrapani/hyprvoice/internal/config"
+	"github.com/leonardotrapani/hyprvoice/internal/deps"
+	"github.com/leonardotrapani/hyprvoice/internal/injection"
+	"github.com/leonardotrapani/hyprvoice/internal/models/whisper"
+	"github.com/leonardotrapani/hyprvoice/internal/provider"
+	"github.com/leonardotrapani/hyprvoice/internal/recording"
+)
+
+// Status is the outcome of a single check
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusInfo Status = "info" // not needed with the current config
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Check is one line of the doctor report
+type Check struct {
+	Group  string `json:"group"`
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// Report is the full list of checks in display order
+type Report struct {
+	Checks []Check `json:"checks"`
+}
+
+// Failed returns the number of failed checks
+func (r Report) Failed() int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == StatusFail {
+			n++
+		}
+	}
+	return n
+}
+
+// Print writes a human readable report grouped by section
+func (r Report) Print(w io.Writer) {
+	group := ""
+	for _, c := range r.Checks {
+		if c.Group != group {
+			if group != "" {
+				fmt.Fprintln(w)
+			}
+			group = c.Group
+			fmt.Fprintf(w, "%s\n", group)
+		}
+
+		line := fmt.Sprintf("  %s %s", statusLabel(c.Status), c.Name)
+		if c.Detail != "" {
+			line += ": " + c.Detail
+		}
+		fmt.Fprintln(w, line)
+		if c.Fix != "" && c.Status != StatusOK {
+			fmt.Fprintf(w, "         fix: %s\n", c.Fix)
+		}
+	}
+
+	fmt.Fprintln(w)
+	if failed := r.Failed(); failed > 0 {
+		fmt.Fprintf(w, "%d check(s) failed\n", failed)
+	} else {
+		fmt.Fprintln(w, "All required checks passed")
+	}
+}
+
+func statusLabel(s Status) string {
+	switch s {
+	case StatusOK:
+		return "[ok]  "
+	case StatusWarn:
+		return "[warn]"
+	case StatusFail:
+		return "[FAIL]"
+	default:
+		return "[--]  "
+	}
+}
+
+// Run executes every check. Checks that depend on the config use defaults
+// when the config cannot be loaded.
+func Run(ctx context.Context) Report {
+	var r Report
+
+	cfg, checks := CheckConfig()
+	r.Checks = append(r.Checks, checks...)
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+
+	r.Checks = append(r.Checks, CheckDaemon())
+	r.Checks = append(r.Checks, CheckRecording(ctx, cfg))
+	r.Checks = append(r.Checks, CheckInjection(cfg)...)
+	r.Checks = append(r.Checks, CheckLocalTranscription(cfg)...)
+	r.Checks = append(r.Checks, CheckAPIKeys(cfg)...)
+
+	return r
+}
+
+// CheckConfig loads and validates the config file
+func CheckConfig() (*config.Config, []Check) {
+	path, _ := config.GetConfigPath()
+	check := Check{Group: "Config", Name: "config file", Detail: path}
+
+	cfg, legacy, err := config.LoadOrLegacy()
+	switch {
+	case errors.Is(err, config.ErrConfigNotFound):
+		check.Status = StatusFail
+		check.Detail = "not found"
+		check.Fix = "run: hyprvoice onboarding"
+		return nil, []Check{check}
+	case err != nil:
+		check.Status = StatusFail
+		check.Detail = err.Error()
+		check.Fix = "fix the TOML syntax or rerun: hyprvoice configure"
+		return nil, []Check{check}
+	case legacy:
+		check.Status = StatusFail
+		check.Detail = "legacy config format"
+		check.Fix = "run: hyprvoice onboarding"
+		return nil, []Check{check}
+	}
+	check.Status = StatusOK
+
+	validation := Check{Group: "Config", Name: "validation", Status: StatusOK}
+	if err := cfg.Validate(); err != nil {
+		validation.Status = StatusFail
+		validation.Detail = err.Error()
+		validation.Fix = "edit the config or run: hyprvoice configure"
+	}
+
+	return cfg, []Check{check, validation}
+}
+
+// CheckDaemon reports PID file and control socket health
+func CheckDaemon() Check {
+	check := Check{Group: "Daemon", Name: "daemon"}
+
+	pid, alive, pidErr := bus.PidFileStatus()
+	resp, callErr := bus.Call(bus.Request{Cmd: bus.CmdStatus})
+	sockPath, _ := bus.SockPath()
+
+	switch {
+	case callErr == nil && resp.Status != nil:
+		check.Status = StatusOK
+		check.Detail = fmt.Sprintf("running (pid %d, version %s, %s)", pid, resp.Status.Version, resp.Status.Status)
+	case callErr == nil:
+		check.Status = StatusWarn
+		check.Detail = fmt.Sprintf("socket answered without status: %s", resp.Error)
+		check.Fix = "restart the daemon: systemctl --user restart hyprvoice.service"
+	case alive:
+		check.Status = StatusFail
+		check.Detail = fmt.Sprintf("process %d is running but %s does not answer", pid, sockPath)
+		check.Fix = "restart the daemon: systemctl --user restart hyprvoice.service"
+	case pidErr != nil:
+		check.Status = StatusWarn
+		check.Detail = pidErr.Error()
+		check.Fix = "start the daemon, it replaces invalid PID files: systemctl --user start hyprvoice.service"
+	case pid != 0:
+		check.Status = StatusWarn
+		pidPath, _ := bus.PidPath()
+		check.Detail = fmt.Sprintf("not running (stale PID file %s for %d)", pidPath, pid)
+		check.Fix = "start the daemon, it removes the stale PID file: systemctl --user start hyprvoice.service"
+	default:
+		check.Status = StatusWarn
+		check.Detail = "not running"
+		check.Fix = "systemctl --user enable --now hyprvoice.service (or run: hyprvoice serve)"
+	}
+
+	if check.Status != StatusOK && sockPath != "" {
+		if _, err := os.Stat(sockPath); err == nil && !alive {
+			check.Detail += fmt.Sprintf("; leftover socket %s", sockPath)
+		}
+	}
+
+	return check
+}
+
+// CheckRecording verifies the configured capture backend is available
+func CheckRecording(ctx context.Context, cfg *config.Config) Check {
+	recCfg := cfg.ToRecordingConfig()
+	check := Check{Group: "Recording", Name: cfg.Recording.Backend, Status: StatusOK}
+	backend := recording.NewBackend(recCfg)
+	if backend == nil {
+		check.Status = StatusFail
+		check.Detail = "unknown backend"
+		check.Fix = "use " + strings.Join(recording.Backends, ", ") + " in recording.backend"
+		return check
+	}
+
+	check.Name = backend.Name()
+	if err := backend.Available(ctx); err != nil {
+		check.Status = StatusFail
+		check.Detail = err.Error()
+		check.Fix = recordingFix(backend.Name())
+		return check
+	}
+	check.Detail = recordingDetail(backend.Name(), recCfg)
+	return check
+}
+
+func recordingDetail(name string, cfg recording.Config) string {
+	switch name {
+	case recording.BackendPipeWire:
+		return "pw-record available, PipeWire running"
+	case recording.BackendFile:
+		if cfg.File == recording.Stdin {
+			return "raw PCM from stdin"
+		}
+		return "playing " + cfg.File
+	default:
+		return name + " available"
+	}
+}
+
+func recordingFix(name string) string {
+	switch name {
+	case recording.BackendPipeWire:
+		return "install pipewire and pipewire-tools (pw-record, pw-cli) and make sure PipeWire is running"
+	case recording.BackendPulse:
+		return "install pulseaudio-utils and make sure PulseAudio (or pipewire-pulse) is running"
+	case recording.BackendALSA:
+		return "install alsa-utils"
+	case recording.BackendFFmpeg:
+		return "install ffmpeg built with PulseAudio input (-f pulse)"
+	case recording.BackendFile:
+		return "set recording.file to a readable WAV file, or \"-\" for raw PCM on stdin"
+	default:
+		return ""
+	}
+}
+
+// CheckInjection checks every configured injection backend. Unavailable
+// backends only fail the report when none of them work.
+func CheckInjection(cfg *config.Config) []Check {
+	var checks []Check
+	working := 0
+
+	for _, name := range cfg.Injection.Backends {
+		check := Check{Group: "Injection", Name: name}
+		backend := injection.NewBackend(name)
+		if backend == nil {
+			check.Status = StatusFail
+			check.Detail = "unknown backend"
+			check.Fix = "use ydotool, wtype or clipboard in injection.backends"
+			checks = append(checks, check)
+			continue
+		}
+
+		if err := backend.Available(); err != nil {
+			check.Status = StatusWarn
+			check.Detail = err.Error()
+			check.Fix = injectionFix(name)
+		} else {
+			check.Status = StatusOK
+			check.Detail = "available"
+			working++
+		}
+		checks = append(checks, check)
+	}
+
+	if working == 0 {
+		for i := range checks {
+			checks[i].Status = StatusFail
+		}
+		if len(checks) == 0 {
+			checks = append(checks, Check{
+				Group:  "Injection",
+				Name:   "backends",
+				Status: StatusFail,
+				Detail: "no backends configured",
+				Fix:    `set injection.backends = ["ydotool", "wtype", "clipboard"]`,
+			})
+		}
+	}
+
+	return checks
+}
+
+func injectionFix(name string) string {
+	switch name {
+	case "ydotool":
+		return "install ydotool and enable ydotoold: systemctl --user enable --now ydotool"
+	case "wtype":
+		return "install wtype and run inside a Wayland session"
+	case "clipboard":
+		return "install wl-clipboard and run inside a Wayland session"
+	default:
+		return ""
+	}
+}
+
+// CheckLocalTranscription checks whisper-cli, ffmpeg and installed whisper models
+func CheckLocalTranscription(cfg *config.Config) []Check {
+	usesLocal := cfg.Transcription.Provider == provider.ProviderWhisperCpp
+
+	whisperCli := Check{Group: "Local transcription", Name: "whisper-cli"}
+	if status := deps.CheckWhisperCli(); status.Installed {
+		whisperCli.Status = StatusOK
+		whisperCli.Detail = versionDetail(status)
+	} else if usesLocal {
+		whisperCli.Status = StatusFail
+		whisperCli.Detail = "not found in PATH"
+		whisperCli.Fix = "install whisper.cpp (provides whisper-cli)"
+	} else {
+		whisperCli.Status = StatusInfo
+		whisperCli.Detail = "not installed (only needed for whisper-cpp)"
+	}
+
+	ffmpeg := Check{Group: "Local transcription", Name: "ffmpeg"}
+	if status := deps.CheckFFmpeg(); status.Installed {
+		ffmpeg.Status = StatusOK
+		ffmpeg.Detail = versionDetail(status)
+	} else {
+		ffmpeg.Status = StatusInfo
+		ffmpeg.Detail = "not installed (optional)"
+	}
+
+	installed := whisper.ListInstalled()
+	models := Check{Group: "Local transcription", Name: "whisper models", Status: StatusInfo, Detail: "none installed"}
+	if len(installed) > 0 {
+		models.Status = StatusOK
+		models.Detail = strings.Join(installed, ", ")
+	}
+	if usesLocal && !whisper.IsInstalled(cfg.Transcription.Model) {
+		models.Status = StatusFail
+		models.Detail = fmt.Sprintf("configured model %s is not installed", cfg.Transcription.Model)
+		models.Fix = fmt.Sprintf("hyprvoice model download %s", cfg.Transcription.Model)
+	}
+
+	return []Check{whisperCli, ffmpeg, models}
+}
+
+func versionDetail(status deps.Status) string {
+	if status.Version == "" {
+		return status.Path
+	}
+	return fmt.Sprintf("%s (%s)", status.Path, status.Version)
+}
+
+// CheckAPIKeys reports API key presence for every provider that needs one.
+// Missing keys only fail for providers the config actually uses.
+func CheckAPIKeys(cfg *config.Config) []Check {
+	used := map[string]string{}
+	if cfg.Transcription.Provider != "" {
+		used[provider.BaseProviderName(cfg.Transcription.Provider)] = "transcription"
+	}
+	if cfg.LLM.Enabled && cfg.LLM.Provider != "" {
+		if role, ok := used[cfg.LLM.Provider]; ok {
+			used[cfg.LLM.Provider] = role + " + llm"
+		} else {
+			used[cfg.LLM.Provider] = "llm"
+		}
+	}
+
+	names := provider.ListProviders()
+	sort.Strings(names)
+
+	var checks []Check
+	for _, name := range names {
+		p := provider.GetProvider(name)
+		if p == nil || !p.RequiresAPIKey() {
+			continue
+		}
+
+		envVar := provider.EnvVarForProvider(name)
+		check := Check{Group: "API keys", Name: name}
+
+		key, source := "", ""
+		if pc, ok := cfg.Providers[name]; ok && pc.APIKey != "" {
+			key, source = pc.APIKey, fmt.Sprintf("providers.%s.api_key", name)
+		} else if envVar != "" && os.Getenv(envVar) != "" {
+			key, source = os.Getenv(envVar), envVar
+		}
+
+		role, isUsed := used[name]
+		switch {
+		case key != "" && !p.ValidateAPIKey(key):
+			check.Status = StatusWarn
+			check.Detail = fmt.Sprintf("set in %s but does not look like a %s key", source, name)
+			check.Fix = fmt.Sprintf("get a key at %s", p.APIKeyURL())
+		case key != "":
+			check.Status = StatusOK
+			check.Detail = fmt.Sprintf("set in %s", source)
+		case isUsed:
+			check.Status = StatusFail
+			check.Detail = fmt.Sprintf("missing (used for %s)", role)
+			check.Fix = fmt.Sprintf("set providers.%s.api_key or %s (get a key at %s)", name, envVar, p.APIKeyURL())
+		default:
+			check.Status = StatusInfo
+			check.Detail = "not configured"
+		}
+		if isUsed && check.Status == StatusOK {
+			check.Detail += fmt.Sprintf(", used for %s", role)
+		}
+
+		checks = append(checks, check)
+	}
+	return checks
+}
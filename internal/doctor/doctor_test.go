@@ -0,0 +1,189 @@
+package doctor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leonardotrapani/hyprvoice/internal/config"
+)
+
+func findCheck(checks []Check, name string) (Check, bool) {
+	for _, c := range checks {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Check{}, false
+}
+
+func TestReport_Print(t *testing.T) {
+	report := Report{Checks: []Check{
+		{Group: "Config", Name: "config file", Status: StatusOK, Detail: "/tmp/config.toml"},
+		{Group: "Injection", Name: "wtype", Status: StatusWarn, Detail: "wtype not found", Fix: "install wtype"},
+		{Group: "Injection", Name: "ydotool", Status: StatusFail, Detail: "ydotoold not running", Fix: "start ydotoold"},
+		{Group: "API keys", Name: "groq", Status: StatusInfo, Detail: "not configured"},
+	}}
+
+	if got := report.Failed(); got != 1 {
+		t.Errorf("Failed() = %d, want 1", got)
+	}
+
+	var buf bytes.Buffer
+	report.Print(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		"Config\n  [ok]   config file: /tmp/config.toml",
+		"[warn] wtype: wtype not found\n         fix: install wtype",
+		"[FAIL] ydotool: ydotoold not running",
+		"[--]   groq: not configured",
+		"1 check(s) failed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestCheckAPIKeys(t *testing.T) {
+	for _, env := range []string{"OPENAI_API_KEY", "GROQ_API_KEY", "MISTRAL_API_KEY", "ELEVENLABS_API_KEY", "DEEPGRAM_API_KEY"} {
+		original, ok := os.LookupEnv(env)
+		os.Unsetenv(env)
+		defer func(env, original string, ok bool) {
+			if ok {
+				os.Setenv(env, original)
+			}
+		}(env, original, ok)
+	}
+	os.Setenv("GROQ_API_KEY", "gsk_test")
+	defer os.Unsetenv("GROQ_API_KEY")
+
+	cfg := config.DefaultConfig()
+	cfg.Transcription.Provider = "openai"
+	cfg.LLM.Enabled = true
+	cfg.LLM.Provider = "groq"
+	cfg.Providers = map[string]config.ProviderConfig{
+		"mistral": {APIKey: "mistral-key"},
+	}
+
+	checks := CheckAPIKeys(cfg)
+
+	tests := []struct {
+		name   string
+		status Status
+		detail string
+	}{
+		{"openai", StatusFail, "used for transcription"},
+		{"groq", StatusOK, "GROQ_API_KEY"},
+		{"mistral", StatusOK, "providers.mistral.api_key"},
+		{"deepgram", StatusInfo, "not configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := findCheck(checks, tt.name)
+			if !ok {
+				t.Fatalf("no check for %s", tt.name)
+			}
+			if c.Status != tt.status || !strings.Contains(c.Detail, tt.detail) {
+				t.Errorf("check = %+v, want status %s with detail containing %q", c, tt.status, tt.detail)
+			}
+		})
+	}
+
+	if _, ok := findCheck(checks, "whisper-cpp"); ok {
+		t.Errorf("whisper-cpp does not need an API key")
+	}
+}
+
+func TestCheckAPIKeys_InvalidFormat(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Transcription.Provider = "openai"
+	cfg.Providers = map[string]config.ProviderConfig{
+		"openai": {APIKey: "not-an-openai-key"},
+	}
+
+	c, ok := findCheck(CheckAPIKeys(cfg), "openai")
+	if !ok || c.Status != StatusWarn {
+		t.Errorf("openai check = %+v, want warn", c)
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	originalConfigDir := os.Getenv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", tempDir)
+	defer func() {
+		if originalConfigDir == "" {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		} else {
+			os.Setenv("XDG_CONFIG_HOME", originalConfigDir)
+		}
+	}()
+
+	cfg, checks := CheckConfig()
+	if cfg != nil || len(checks) != 1 || checks[0].Status != StatusFail {
+		t.Fatalf("missing config: cfg = %v, checks = %+v", cfg, checks)
+	}
+	if !strings.Contains(checks[0].Fix, "onboarding") {
+		t.Errorf("missing config fix = %q", checks[0].Fix)
+	}
+
+	configDir := filepath.Join(tempDir, "hyprvoice")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte("[transcription\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, checks := CheckConfig(); checks[0].Status != StatusFail {
+		t.Errorf("broken config check = %+v, want fail", checks[0])
+	}
+
+	if err := config.SaveDefaultConfig(); err != nil {
+		t.Fatalf("SaveDefaultConfig() error = %v", err)
+	}
+	cfg, checks = CheckConfig()
+	if cfg == nil {
+		t.Fatalf("default config not loaded: %+v", checks)
+	}
+	if c, _ := findCheck(checks, "config file"); c.Status != StatusOK {
+		t.Errorf("config file check = %+v, want ok", c)
+	}
+}
+
+func TestCheckDaemon_NotRunning(t *testing.T) {
+	tempDir := t.TempDir()
+	originalCacheDir := os.Getenv("XDG_CACHE_HOME")
+	os.Setenv("XDG_CACHE_HOME", tempDir)
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	defer func() {
+		if originalCacheDir == "" {
+			os.Unsetenv("XDG_CACHE_HOME")
+		} else {
+			os.Setenv("XDG_CACHE_HOME", originalCacheDir)
+		}
+	}()
+
+	c := CheckDaemon()
+	if c.Status != StatusWarn || c.Detail != "not running" {
+		t.Errorf("check = %+v, want warn not running", c)
+	}
+
+	// a PID that cannot belong to a live process
+	pidDir := filepath.Join(tempDir, "hyprvoice")
+	if err := os.MkdirAll(pidDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pidDir, "hyprvoice.pid"), []byte("999999999"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c = CheckDaemon()
+	if c.Status != StatusWarn || !strings.Contains(c.Detail, "stale PID file") {
+		t.Errorf("check = %+v, want warn stale PID file", c)
+	}
+}
@@ -0,0 +1,98 @@
+package history
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// encryptedPrefix marks a history line sealed with AES-256-GCM
+const encryptedPrefix = "enc:v1:"
+
+// sealer encrypts and decrypts individual history lines
+type sealer struct {
+	aead cipher.AEAD
+}
+
+// loadSealer reads the key from keyFile. A missing key is generated when
+// create is set, otherwise nil is returned and only plain lines are readable.
+func loadSealer(keyFile string, create bool) (*sealer, error) {
+	data, err := os.ReadFile(keyFile)
+	if os.IsNotExist(err) {
+		if !create {
+			return nil, nil
+		}
+		data, err = createKey(keyFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history key: %w", err)
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("invalid history key in %s: want 64 hex characters", keyFile)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create history cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create history cipher: %w", err)
+	}
+	return &sealer{aead: aead}, nil
+}
+
+func createKey(keyFile string) ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(keyFile), 0700); err != nil {
+		return nil, err
+	}
+
+	data := []byte(hex.EncodeToString(key) + "\n")
+	f, err := os.OpenFile(keyFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if os.IsExist(err) {
+		// another process created it first
+		return os.ReadFile(keyFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (s *sealer) seal(plaintext []byte) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := s.aead.Seal(nonce, nonce, plaintext, nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *sealer) open(line string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(line, encryptedPrefix))
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := s.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	return s.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+}
@@ -0,0 +1,126 @@
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats supported by Export
+const (
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "md"
+)
+
+// Export writes entries to w in the given format (json, csv or md)
+func Export(w io.Writer, entries []Entry, format string) error {
+	switch format {
+	case FormatJSON:
+		return exportJSON(w, entries)
+	case FormatCSV:
+		return exportCSV(w, entries)
+	case FormatMarkdown, "markdown":
+		return exportMarkdown(w, entries)
+	default:
+		return fmt.Errorf("invalid export format: %s (use json, csv or md)", format)
+	}
+}
+
+func exportJSON(w io.Writer, entries []Entry) error {
+	if entries == nil {
+		entries = []Entry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+func exportCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	header := []string{
+		"id", "time", "transcript", "llm_output", "provider", "model",
+		"llm_provider", "llm_model", "language", "backend",
+		"recording_ms", "transcription_ms", "llm_ms", "injection_ms", "errors",
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		record := []string{
+			e.ID,
+			e.Time.Format(time.RFC3339),
+			e.Transcript,
+			e.LLMOutput,
+			e.Provider,
+			e.Model,
+			e.LLMProvider,
+			e.LLMModel,
+			e.Language,
+			e.Backend,
+			strconv.FormatInt(e.Durations.RecordingMs, 10),
+			strconv.FormatInt(e.Durations.TranscriptionMs, 10),
+			strconv.FormatInt(e.Durations.LLMMs, 10),
+			strconv.FormatInt(e.Durations.InjectionMs, 10),
+			strings.Join(e.Errors, "; "),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func exportMarkdown(w io.Writer, entries []Entry) error {
+	var sb strings.Builder
+	sb.WriteString("# Hyprvoice history\n")
+
+	for _, e := range entries {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", e.Time.Format("2006-01-02 15:04:05")))
+		sb.WriteString(fmt.Sprintf("- ID: `%s`\n", e.ID))
+		sb.WriteString(fmt.Sprintf("- Model: %s / %s\n", e.Provider, e.Model))
+		if e.LLMModel != "" {
+			sb.WriteString(fmt.Sprintf("- LLM: %s / %s\n", e.LLMProvider, e.LLMModel))
+		}
+		if e.Language != "" {
+			sb.WriteString(fmt.Sprintf("- Language: %s\n", e.Language))
+		}
+		if e.Backend != "" {
+			sb.WriteString(fmt.Sprintf("- Backend: %s\n", e.Backend))
+		}
+		for _, errMsg := range e.Errors {
+			sb.WriteString(fmt.Sprintf("- Error: %s\n", errMsg))
+		}
+
+		sb.WriteString("\n")
+		sb.WriteString(quote(e.Text()))
+		if e.LLMOutput != "" && e.LLMOutput != e.Transcript {
+			sb.WriteString("\n<details><summary>Raw transcript</summary>\n\n")
+			sb.WriteString(quote(e.Transcript))
+			sb.WriteString("\n</details>\n")
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func quote(text string) string {
+	if text == "" {
+		return "> _(empty)_\n"
+	}
+	var sb strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		sb.WriteString("> ")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
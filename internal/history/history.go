@@ -0,0 +1,511 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
+)
+
+var logger = logging.For("history")
+
+// FileName is the history file inside the hyprvoice data directory
+const FileName = "history.jsonl"
+
+// compactAgeSlack is how far past max_age the oldest entry may be before the
+// file is rewritten, so steady use does not rewrite it on every dictation
+const compactAgeSlack = time.Hour
+
+var ErrNotFound = errors.New("history entry not found")
+
+// ErrUnreadable is returned by List, together with the readable entries,
+// when some entries are encrypted with a key that is missing or was replaced
+var ErrUnreadable = errors.New("history entries cannot be decrypted")
+
+// Entry is a single dictation as processed by the pipeline
+type Entry struct {
+	ID          string    `json:"id"`
+	Time        time.Time `json:"time"`
+	Transcript  string    `json:"transcript"`
+	LLMOutput   string    `json:"llm_output,omitempty"`
+	Provider    string    `json:"provider"`
+	Model       string    `json:"model"`
+	LLMProvider string    `json:"llm_provider,omitempty"`
+	LLMModel    string    `json:"llm_model,omitempty"`
+	Language    string    `json:"language,omitempty"`
+	Backend     string    `json:"backend,omitempty"` // injection backend that delivered the text
+	Durations   Durations `json:"durations"`
+	Errors      []string  `json:"errors,omitempty"`
+}
+
+// Durations records how long each pipeline stage took, in milliseconds
+type Durations struct {
+	RecordingMs     int64 `json:"recording_ms"`
+	RecorderStartMs int64 `json:"recorder_start_ms,omitempty"`
+	AudioMs         int64 `json:"audio_ms,omitempty"`
+	TranscriptionMs int64 `json:"transcription_ms"`
+	LLMMs           int64 `json:"llm_ms,omitempty"`
+	InjectionMs     int64 `json:"injection_ms"`
+}
+
+// Text returns the text that was (or would have been) injected
+func (e Entry) Text() string {
+	if e.LLMOutput != "" {
+		return e.LLMOutput
+	}
+	return e.Transcript
+}
+
+// Config controls where history is stored and how long it is kept
+type Config struct {
+	Enabled    bool
+	MaxEntries int           // 0 = unlimited
+	MaxAge     time.Duration // 0 = keep forever
+	Encrypt    bool
+	Path       string // empty = DefaultPath()
+	KeyFile    string // empty = DefaultKeyFile()
+}
+
+// Store persists dictation history
+type Store interface {
+	Append(e Entry) error
+	// List returns the entries within the retention limits, oldest first.
+	// When some cannot be decrypted it returns the rest and ErrUnreadable.
+	List() ([]Entry, error)
+}
+
+type fileStore struct {
+	mu     sync.Mutex
+	config Config
+	path   string
+	sealer *sealer
+}
+
+// NewStore opens the history file described by cfg. The encryption key is
+// loaded when present so encrypted entries stay readable after encrypt is
+// turned off, and created on first use when encrypt is on.
+func NewStore(cfg Config) (Store, error) {
+	path := cfg.Path
+	if path == "" {
+		p, err := DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+		path = p
+	}
+
+	keyFile := cfg.KeyFile
+	if keyFile == "" {
+		k, err := DefaultKeyFile()
+		if err != nil {
+			return nil, err
+		}
+		keyFile = k
+	}
+
+	_, keyErr := os.Stat(keyFile)
+	sl, err := loadSealer(keyFile, cfg.Encrypt)
+	if err != nil {
+		return nil, err
+	}
+	if os.IsNotExist(keyErr) && hasEncryptedLines(path) {
+		// the lines are kept, so restoring the old key makes them readable again
+		logger.Warn("History key file is missing, entries encrypted with the old key cannot be read until it is restored", "key_file", keyFile)
+	}
+
+	return &fileStore{config: cfg, path: path, sealer: sl}, nil
+}
+
+// hasEncryptedLines reports whether the history file at path has encrypted
+// lines, stopping at the first one
+func hasEncryptedLines(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if bytes.HasPrefix(scanner.Bytes(), []byte(encryptedPrefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultPath returns $XDG_DATA_HOME/hyprvoice/history.jsonl
+func DefaultPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// DefaultKeyFile returns the encryption key path, kept in the config
+// directory so backups of the data directory do not carry the key
+func DefaultKeyFile() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "hyprvoice", "history.key"), nil
+}
+
+func dataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "hyprvoice"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "hyprvoice"), nil
+}
+
+// NewID returns a sortable entry ID for t
+func NewID(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 36)
+}
+
+func (s *fileStore) Append(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.ID == "" {
+		e.ID = NewID(e.Time)
+	}
+
+	if err := s.appendLine(e); err != nil {
+		return err
+	}
+	if s.needsCompaction() {
+		s.compact()
+	}
+	return nil
+}
+
+func (s *fileStore) List() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, unreadable, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	entries = Prune(entries, s.config.MaxEntries, s.config.MaxAge, time.Now())
+	if unreadable > 0 {
+		return entries, fmt.Errorf("%w: %d entries are encrypted with a key that is missing or was replaced", ErrUnreadable, unreadable)
+	}
+	return entries, nil
+}
+
+// needsCompaction reports whether the file holds clearly more than the
+// retention limits allow. The line count comes from lineCounts and only the
+// first line is read, so appending does not read the whole history.
+func (s *fileStore) needsCompaction() bool {
+	if s.config.MaxEntries > 0 {
+		slack := max(s.config.MaxEntries/10, 1)
+		if lines, err := countLines(s.path); err == nil && lines > s.config.MaxEntries+slack {
+			return true
+		}
+	}
+	if s.config.MaxAge > 0 {
+		oldest, err := s.first()
+		if err == nil && time.Since(oldest.Time) > s.config.MaxAge+compactAgeSlack {
+			return true
+		}
+	}
+	return false
+}
+
+// first returns the oldest entry in the file
+func (s *fileStore) first() (Entry, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if line == "" && err != nil {
+		return Entry{}, err
+	}
+	return s.decode(strings.TrimSpace(line))
+}
+
+// lineCount is the number of lines in a history file of the given size
+type lineCount struct {
+	size  int64
+	lines int
+}
+
+// lineCounts caches the line count of each history file by path. A store is
+// opened for every dictation, so the count has to outlive it. A count whose
+// size no longer matches the file, after a write by another process, is
+// recounted.
+var (
+	lineCountsMu sync.Mutex
+	lineCounts   = map[string]lineCount{}
+)
+
+// countLines returns the number of lines in the file at path
+func countLines(path string) (int, error) {
+	lineCountsMu.Lock()
+	defer lineCountsMu.Unlock()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if c, ok := lineCounts[path]; ok && c.size == info.Size() {
+		return c.lines, nil
+	}
+
+	lines := 0
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := f.Read(buf)
+		lines += bytes.Count(buf[:n], []byte("\n"))
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	lineCounts[path] = lineCount{size: info.Size(), lines: lines}
+	return lines, nil
+}
+
+// addLines updates the cached count after n lines of written bytes were
+// appended to a file that now has size bytes. When the cached count does not
+// add up, another write happened in between and the next countLines recounts.
+func addLines(path string, size, written int64, n int) {
+	lineCountsMu.Lock()
+	defer lineCountsMu.Unlock()
+	if c, ok := lineCounts[path]; ok && c.size+written == size {
+		lineCounts[path] = lineCount{size: size, lines: c.lines + n}
+	}
+}
+
+// setLines records the count of a file that was just rewritten
+func setLines(path string, size int64, n int) {
+	lineCountsMu.Lock()
+	defer lineCountsMu.Unlock()
+	lineCounts[path] = lineCount{size: size, lines: n}
+}
+
+// compact rewrites the file without the entries outside the retention
+// limits. Entries that cannot be decrypted block it, so they are not lost.
+func (s *fileStore) compact() {
+	entries, unreadable, err := s.read()
+	if err != nil {
+		logger.Warn("Failed to read history for trimming", "err", err)
+		return
+	}
+	if unreadable > 0 {
+		logger.Warn("History not trimmed, some entries cannot be decrypted", "entries", unreadable)
+		return
+	}
+	if err := s.write(Prune(entries, s.config.MaxEntries, s.config.MaxAge, time.Now())); err != nil {
+		logger.Warn("Failed to trim history", "err", err)
+	}
+}
+
+// Prune drops entries older than maxAge and keeps at most maxEntries of the
+// newest ones. Zero disables either limit. entries must be oldest first.
+func Prune(entries []Entry, maxEntries int, maxAge time.Duration, now time.Time) []Entry {
+	if maxAge > 0 {
+		cutoff := now.Add(-maxAge)
+		start := 0
+		for start < len(entries) && entries[start].Time.Before(cutoff) {
+			start++
+		}
+		entries = entries[start:]
+	}
+	if maxEntries > 0 && len(entries) > maxEntries {
+		entries = entries[len(entries)-maxEntries:]
+	}
+	return entries
+}
+
+// read returns every entry in the file and how many encrypted lines could
+// not be decrypted with the current key
+func (s *fileStore) read() (entries []Entry, unreadable int, err error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		e, err := s.decode(line)
+		if errors.Is(err, errUndecryptable) {
+			unreadable++
+			continue
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("history line %d: %w", lineNo, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read history: %w", err)
+	}
+	return entries, unreadable, nil
+}
+
+// errUndecryptable marks a line encrypted with a key other than the loaded one
+var errUndecryptable = errors.New("encrypted with another key")
+
+// decode parses one line of the history file
+func (s *fileStore) decode(line string) (Entry, error) {
+	data := []byte(line)
+	if strings.HasPrefix(line, encryptedPrefix) {
+		if s.sealer == nil {
+			return Entry{}, errUndecryptable
+		}
+		var err error
+		if data, err = s.sealer.open(line); err != nil {
+			return Entry{}, errUndecryptable
+		}
+	}
+
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Entry{}, fmt.Errorf("failed to parse history entry: %w", err)
+	}
+	return e, nil
+}
+
+func (s *fileStore) encode(e Entry) (string, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode history entry: %w", err)
+	}
+	if s.config.Encrypt {
+		return s.sealer.seal(data)
+	}
+	return string(data), nil
+}
+
+func (s *fileStore) appendLine(e Entry) error {
+	line, err := s.encode(e)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	n, err := f.WriteString(line + "\n")
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if info, err := f.Stat(); err == nil {
+		addLines(s.path, info.Size(), int64(n), 1)
+	}
+	return nil
+}
+
+// write replaces the history file atomically
+func (s *fileStore) write(entries []Entry) error {
+	var sb strings.Builder
+	for _, e := range entries {
+		line, err := s.encode(e)
+		if err != nil {
+			return err
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(sb.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace history: %w", err)
+	}
+	setLines(s.path, int64(sb.Len()), len(entries))
+	return nil
+}
+
+// Find returns the entry whose ID starts with prefix. "last" selects the newest entry.
+func Find(entries []Entry, prefix string) (Entry, error) {
+	if prefix == "last" && len(entries) > 0 {
+		return entries[len(entries)-1], nil
+	}
+
+	var match *Entry
+	for i := range entries {
+		if !strings.HasPrefix(entries[i].ID, prefix) {
+			continue
+		}
+		if entries[i].ID == prefix {
+			return entries[i], nil
+		}
+		if match != nil {
+			return Entry{}, fmt.Errorf("ambiguous history id %q", prefix)
+		}
+		match = &entries[i]
+	}
+	if match == nil {
+		return Entry{}, fmt.Errorf("%w: %s", ErrNotFound, prefix)
+	}
+	return *match, nil
+}
+
+// Search returns entries whose transcript or LLM output contains query, case-insensitively
+func Search(entries []Entry, query string) []Entry {
+	query = strings.ToLower(query)
+	var result []Entry
+	for _, e := range entries {
+		if strings.Contains(strings.ToLower(e.Transcript), query) ||
+			strings.Contains(strings.ToLower(e.LLMOutput), query) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
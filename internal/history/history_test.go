@@ -0,0 +1,344 @@
+package history
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testStore(t *testing.T, cfg Config) (Store, string) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg.Path = filepath.Join(dir, FileName)
+	cfg.KeyFile = filepath.Join(dir, "history.key")
+	store, err := NewStore(cfg)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	return store, cfg.Path
+}
+
+func TestStore_AppendList(t *testing.T) {
+	store, path := testStore(t, Config{Enabled: true})
+
+	entries, err := store.List()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("List() on missing file = %v, %v", entries, err)
+	}
+
+	if err := store.Append(Entry{Transcript: "first", Provider: "openai", Model: "whisper-1"}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := store.Append(Entry{Transcript: "second", LLMOutput: "Second."}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	entries, err = store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("List() returned %d entries, want 2", len(entries))
+	}
+	if entries[0].Transcript != "first" || entries[1].Text() != "Second." {
+		t.Errorf("entries = %+v", entries)
+	}
+	if entries[0].ID == "" || entries[0].Time.IsZero() {
+		t.Errorf("Append() did not fill ID/time: %+v", entries[0])
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat history: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("history file mode = %o, want 600", perm)
+	}
+}
+
+func TestStore_Retention(t *testing.T) {
+	store, _ := testStore(t, Config{Enabled: true, MaxEntries: 2})
+
+	for _, text := range []string{"one", "two", "three"} {
+		if err := store.Append(Entry{Transcript: text}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	entries, _ := store.List()
+	if len(entries) != 2 || entries[0].Transcript != "two" || entries[1].Transcript != "three" {
+		t.Errorf("entries after retention = %+v", entries)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Now()
+	entries := []Entry{
+		{ID: "a", Time: now.Add(-48 * time.Hour)},
+		{ID: "b", Time: now.Add(-2 * time.Hour)},
+		{ID: "c", Time: now.Add(-time.Hour)},
+		{ID: "d", Time: now},
+	}
+
+	tests := []struct {
+		name       string
+		maxEntries int
+		maxAge     time.Duration
+		want       string
+	}{
+		{"no limits", 0, 0, "abcd"},
+		{"max age", 0, 24 * time.Hour, "bcd"},
+		{"max entries", 2, 0, "cd"},
+		{"both", 3, 90 * time.Minute, "cd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			for _, e := range Prune(entries, tt.maxEntries, tt.maxAge, now) {
+				got += e.ID
+			}
+			if got != tt.want {
+				t.Errorf("Prune() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStore_Encryption(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{
+		Enabled: true,
+		Encrypt: true,
+		Path:    filepath.Join(dir, FileName),
+		KeyFile: filepath.Join(dir, "history.key"),
+	}
+
+	store, err := NewStore(cfg)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if err := store.Append(Entry{Transcript: "top secret"}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(cfg.Path)
+	if bytes.Contains(data, []byte("top secret")) || !bytes.HasPrefix(data, []byte(encryptedPrefix)) {
+		t.Errorf("history stored in plain text: %q", data)
+	}
+
+	// encrypted entries stay readable once encryption is switched off
+	cfg.Encrypt = false
+	reader, err := NewStore(cfg)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if err := reader.Append(Entry{Transcript: "plain"}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	entries, err := reader.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Transcript != "top secret" || entries[1].Transcript != "plain" {
+		t.Errorf("entries = %+v", entries)
+	}
+
+	// without the key the encrypted line cannot be read, but is kept and
+	// new entries are still saved
+	key, _ := os.ReadFile(cfg.KeyFile)
+	os.Remove(cfg.KeyFile)
+	cfg.MaxEntries = 1
+	noKey, err := NewStore(cfg)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	for _, text := range []string{"after", "loss"} {
+		if err := noKey.Append(Entry{Transcript: text}); err != nil {
+			t.Fatalf("Append() without key error = %v", err)
+		}
+	}
+	entries, err = noKey.List()
+	if !errors.Is(err, ErrUnreadable) {
+		t.Errorf("List() without key error = %v, want ErrUnreadable", err)
+	}
+	if len(entries) != 1 || entries[0].Transcript != "loss" {
+		t.Errorf("readable entries = %+v", entries)
+	}
+
+	// restoring the key brings the encrypted entry back
+	os.WriteFile(cfg.KeyFile, key, 0600)
+	restored, _ := NewStore(Config{Path: cfg.Path, KeyFile: cfg.KeyFile})
+	entries, err = restored.List()
+	if err != nil || len(entries) != 4 || entries[0].Transcript != "top secret" {
+		t.Errorf("List() with the key restored = %+v, %v", entries, err)
+	}
+}
+
+func TestStore_LazyCompaction(t *testing.T) {
+	store, path := testStore(t, Config{Enabled: true, MaxEntries: 10})
+
+	lines := func() int {
+		data, _ := os.ReadFile(path)
+		return bytes.Count(data, []byte("\n"))
+	}
+	for i := 0; i < 11; i++ {
+		store.Append(Entry{Transcript: "entry"})
+	}
+	if got := lines(); got != 11 {
+		t.Errorf("file has %d lines, want 11 before the slack is used up", got)
+	}
+	if entries, _ := store.List(); len(entries) != 10 {
+		t.Errorf("List() returned %d entries, want 10", len(entries))
+	}
+
+	store.Append(Entry{Transcript: "entry"})
+	if got := lines(); got != 10 {
+		t.Errorf("file has %d lines after trimming, want 10", got)
+	}
+}
+
+func TestStore_CompactionAcrossStores(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{Enabled: true, MaxEntries: 10, Path: filepath.Join(dir, FileName), KeyFile: filepath.Join(dir, "history.key")}
+	lines := func() int {
+		data, _ := os.ReadFile(cfg.Path)
+		return bytes.Count(data, []byte("\n"))
+	}
+
+	// the pipeline opens a store per dictation
+	for i := 0; i < 10; i++ {
+		store, err := NewStore(cfg)
+		if err != nil {
+			t.Fatalf("NewStore() error = %v", err)
+		}
+		store.Append(Entry{Transcript: "entry"})
+	}
+
+	// lines written by another process are counted as well
+	other, err := NewStore(Config{Enabled: true, Path: cfg.Path, KeyFile: cfg.KeyFile})
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	f, err := os.OpenFile(cfg.Path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, _ := other.(*fileStore).encode(Entry{ID: "external", Time: time.Now(), Transcript: "entry"})
+	f.WriteString(line + "\n")
+	f.Close()
+	if got := lines(); got != 11 {
+		t.Fatalf("file has %d lines, want 11", got)
+	}
+
+	store, err := NewStore(cfg)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	store.Append(Entry{Transcript: "entry"})
+	if got := lines(); got != 10 {
+		t.Errorf("file has %d lines after trimming, want 10", got)
+	}
+}
+
+func TestFind(t *testing.T) {
+	entries := []Entry{{ID: "abc1"}, {ID: "abd2"}, {ID: "xyz"}}
+
+	if e, err := Find(entries, "abc"); err != nil || e.ID != "abc1" {
+		t.Errorf("Find(abc) = %v, %v", e.ID, err)
+	}
+	if e, err := Find(entries, "last"); err != nil || e.ID != "xyz" {
+		t.Errorf("Find(last) = %v, %v", e.ID, err)
+	}
+	if _, err := Find(entries, "ab"); err == nil {
+		t.Errorf("Find(ab) should be ambiguous")
+	}
+	if _, err := Find(entries, "nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Find(nope) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	entries := []Entry{
+		{ID: "1", Transcript: "Meeting notes for Monday"},
+		{ID: "2", Transcript: "buy milk", LLMOutput: "Buy MILK."},
+		{ID: "3", Transcript: "nothing here"},
+	}
+
+	if got := Search(entries, "monday"); len(got) != 1 || got[0].ID != "1" {
+		t.Errorf("Search(monday) = %+v", got)
+	}
+	if got := Search(entries, "milk."); len(got) != 1 || got[0].ID != "2" {
+		t.Errorf("Search(milk.) = %+v", got)
+	}
+	if got := Search(entries, "absent"); len(got) != 0 {
+		t.Errorf("Search(absent) = %+v", got)
+	}
+}
+
+func TestExport(t *testing.T) {
+	entries := []Entry{{
+		ID:         "k1",
+		Time:       time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Transcript: "hello, world",
+		LLMOutput:  "Hello, world!",
+		Provider:   "openai",
+		Model:      "whisper-1",
+		Backend:    "wtype",
+		Durations:  Durations{RecordingMs: 1500, InjectionMs: 20},
+		Errors:     []string{"LLM slow"},
+	}}
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Export(&buf, entries, FormatJSON); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		var got []Entry
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if len(got) != 1 || got[0].LLMOutput != "Hello, world!" || got[0].Durations.RecordingMs != 1500 {
+			t.Errorf("exported = %+v", got)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Export(&buf, entries, FormatCSV); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("invalid CSV: %v", err)
+		}
+		if len(records) != 2 || records[0][0] != "id" || records[1][2] != "hello, world" || records[1][9] != "wtype" {
+			t.Errorf("records = %v", records)
+		}
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Export(&buf, entries, FormatMarkdown); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		out := buf.String()
+		for _, want := range []string{"## 2025-01-02 03:04:05", "> Hello, world!", "> hello, world", "- Error: LLM slow"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("markdown missing %q:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if err := Export(&bytes.Buffer{}, entries, "xml"); err == nil {
+			t.Errorf("Export(xml) succeeded")
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RecoveryFileName is the plain text file in the hyprvoice data directory
+// that dictations are saved to when they end without being injected
+const RecoveryFileName = "recovery.txt"
+
+// RecoveryPath returns $XDG_DATA_HOME/hyprvoice/recovery.txt
+func RecoveryPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, RecoveryFileName), nil
+}
+
+// SaveRecovery appends text to the recovery file at path under a header
+// with the time and the reason the dictation was saved instead of injected
+func SaveRecovery(path string, t time.Time, reason, text string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create recovery directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open recovery file: %w", err)
+	}
+	defer f.Close()
+
+	block := fmt.Sprintf("--- %s (%s)\n%s\n\n", t.Format("2006-01-02 15:04:05"), reason, strings.TrimSpace(text))
+	if _, err := f.WriteString(block); err != nil {
+		return fmt.Errorf("failed to write recovery file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Package hyprland queries the Hyprland compositor over its IPC socket
+package hyprland
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// Window is the subset of hyprctl activewindow output hyprvoice uses
+type Window struct {
+	Class        string `json:"class"`
+	Title        string `json:"title"`
+	InitialClass string `json:"initialClass"`
+	InitialTitle string `json:"initialTitle"`
+}
+
+// SocketPath returns the request socket of the running Hyprland instance,
+// $XDG_RUNTIME_DIR/hypr/$HYPRLAND_INSTANCE_SIGNATURE/.socket.sock
+func SocketPath() (string, error) {
+	signature := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
+	if signature == "" {
+		return "", fmt.Errorf("HYPRLAND_INSTANCE_SIGNATURE not set (not running under Hyprland?)")
+	}
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = filepath.Join("/run/user", fmt.Sprint(os.Getuid()))
+	}
+	return filepath.Join(runtimeDir, "hypr", signature, ".socket.sock"), nil
+}
+
+// ActiveWindow returns the focused window of the running Hyprland instance.
+// When no window is focused the returned Window is empty.
+func ActiveWindow(ctx context.Context) (Window, error) {
+	path, err := SocketPath()
+	if err != nil {
+		return Window{}, err
+	}
+	return ActiveWindowAt(ctx, path)
+}
+
+// ActiveWindowAt sends the activewindow request to the IPC socket at path
+func ActiveWindowAt(ctx context.Context, path string) (Window, error) {
+	data, err := request(ctx, path, "j/activewindow")
+	if err != nil {
+		return Window{}, err
+	}
+
+	var w Window
+	if err := json.Unmarshal(data, &w); err != nil {
+		return Window{}, fmt.Errorf("failed to parse activewindow reply: %w", err)
+	}
+	return w, nil
+}
+
+// request writes cmd to the socket and reads the reply until Hyprland
+// closes the connection
+func request(ctx context.Context, path, cmd string) ([]byte, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Hyprland: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write([]byte(cmd)); err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", cmd, err)
+	}
+	data, err := io.ReadAll(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s reply: %w", cmd, err)
+	}
+	return data, nil
+}
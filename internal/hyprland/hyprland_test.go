@@ -0,0 +1,117 @@
+package hyprland
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeHyprland serves a single canned reply per connection and records the
+// requests it received
+func fakeHyprland(t *testing.T, path, reply string) <-chan string {
+	t.Helper()
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	requests := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 256)
+			n, _ := conn.Read(buf)
+			requests <- string(buf[:n])
+			io.WriteString(conn, reply)
+			conn.Close()
+		}
+	}()
+	return requests
+}
+
+func TestSocketPath(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "abc_123")
+
+	path, err := SocketPath()
+	if err != nil {
+		t.Fatalf("SocketPath() error = %v", err)
+	}
+	if want := "/run/user/1000/hypr/abc_123/.socket.sock"; path != want {
+		t.Errorf("SocketPath() = %q, want %q", path, want)
+	}
+
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "")
+	if _, err := SocketPath(); err == nil {
+		t.Errorf("SocketPath() without a signature should fail")
+	}
+}
+
+func TestActiveWindow(t *testing.T) {
+	runtimeDir := t.TempDir()
+	socketDir := filepath.Join(runtimeDir, "hypr", "test")
+	if err := os.MkdirAll(socketDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "test")
+
+	requests := fakeHyprland(t, filepath.Join(socketDir, ".socket.sock"), `{
+	"address": "0x55d0c8a0b2c0",
+	"mapped": true,
+	"class": "kitty",
+	"title": "nvim main.go",
+	"initialClass": "kitty",
+	"initialTitle": "kitty",
+	"pid": 4242
+}`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	w, err := ActiveWindow(ctx)
+	if err != nil {
+		t.Fatalf("ActiveWindow() error = %v", err)
+	}
+	if w.Class != "kitty" || w.Title != "nvim main.go" || w.InitialClass != "kitty" {
+		t.Errorf("ActiveWindow() = %+v", w)
+	}
+	if req := <-requests; req != "j/activewindow" {
+		t.Errorf("request = %q, want j/activewindow", req)
+	}
+}
+
+func TestActiveWindow_NoWindow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".socket.sock")
+	fakeHyprland(t, path, "{}")
+
+	w, err := ActiveWindowAt(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ActiveWindowAt() error = %v", err)
+	}
+	if w != (Window{}) {
+		t.Errorf("ActiveWindowAt() = %+v, want an empty window", w)
+	}
+}
+
+func TestActiveWindow_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := ActiveWindowAt(context.Background(), filepath.Join(dir, "missing.sock")); err == nil {
+		t.Errorf("ActiveWindowAt() with no socket should fail")
+	}
+
+	path := filepath.Join(dir, ".socket.sock")
+	fakeHyprland(t, path, "unknown request")
+	if _, err := ActiveWindowAt(context.Background(), path); err == nil {
+		t.Errorf("ActiveWindowAt() with a non-JSON reply should fail")
+	}
+}
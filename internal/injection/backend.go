@@ -11,3 +11,8 @@ type Backend interface {
 	Available() error
 	Inject(ctx context.Context, text string, timeout time.Duration) error
 }
+
+// Eraser is implemented by typing backends that can delete typed text
+type Eraser interface {
+	Erase(ctx context.Context, count int, timeout time.Duration) error
+}
@@ -1,6 +1,7 @@
 package injection
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -9,7 +10,10 @@ import (
 	"time"
 )
 
-type clipboardBackend struct{}
+type clipboardBackend struct {
+	previous string
+	saved    bool
+}
 
 func NewClipboardBackend() Backend {
 	return &clipboardBackend{}
@@ -43,6 +47,9 @@ func (c *clipboardBackend) Inject(ctx context.Context, text string, timeout time
 		return err
 	}
 
+	// remember what we replace so the injection can be undone
+	c.previous, c.saved = readClipboard(ctx)
+
 	cmd := exec.CommandContext(ctx, "wl-copy")
 	cmd.Stdin = strings.NewReader(text)
 
@@ -52,3 +59,51 @@ func (c *clipboardBackend) Inject(ctx context.Context, text string, timeout time
 
 	return nil
 }
+
+// Previous returns the clipboard content replaced by the last Inject
+func (c *clipboardBackend) Previous() (string, bool) {
+	return c.previous, c.saved
+}
+
+// readClipboard returns the current text clipboard. An empty clipboard is
+// reported as saved so undo can clear it again.
+func readClipboard(ctx context.Context) (string, bool) {
+	if _, err := exec.LookPath("wl-paste"); err != nil {
+		return "", false
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "wl-paste", "--no-newline", "--type", "text")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(stderr.String(), "Nothing is copied") || strings.Contains(stderr.String(), "No selection") {
+			return "", true
+		}
+		return "", false
+	}
+	return string(out), true
+}
+
+// RestoreClipboard puts content back on the clipboard, clearing it when content is empty
+func RestoreClipboard(ctx context.Context, content string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := NewClipboardBackend().Available(); err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, "wl-copy")
+	if content == "" {
+		cmd = exec.CommandContext(ctx, "wl-copy", "--clear")
+	} else {
+		cmd.Stdin = strings.NewReader(content)
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("wl-copy failed: %w", err)
+	}
+
+	return nil
+}
@@ -3,14 +3,37 @@ package injection
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
+	"unicode/utf8"
+
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 )
 
+var logger = logging.For("injection")
+
+// eraseKeyDelay is the per-key time budget added to the timeout when erasing
+const eraseKeyDelay = 20 * time.Millisecond
+
 type Injector interface {
 	Inject(ctx context.Context, text string) error
 }
 
+// Injection describes a completed injection so it can be repeated or undone
+type Injection struct {
+	Text    string
+	Backend string // backend that delivered the text ("" when every backend failed)
+
+	// PreviousClipboard is the clipboard content replaced by the clipboard
+	// backend; ClipboardSaved reports whether it could be read
+	PreviousClipboard string
+	ClipboardSaved    bool
+}
+
+// Reporter is implemented by injectors that can describe their last successful injection
+type Reporter interface {
+	LastInjection() Injection
+}
+
 type Config struct {
 	Backends         []string      // Ordered list: "ydotool", "wtype", "clipboard"
 	YdotoolTimeout   time.Duration // Timeout for ydotool commands
@@ -21,27 +44,24 @@ type Config struct {
 type injector struct {
 	config   Config
 	backends []Backend
+	last     Injection
 }
 
 func NewInjector(config Config) Injector {
 	// Build backend chain from config
 	backends := make([]Backend, 0, len(config.Backends))
 	for _, name := range config.Backends {
-		switch name {
-		case "ydotool":
-			backends = append(backends, NewYdotoolBackend())
-		case "wtype":
-			backends = append(backends, NewWtypeBackend())
-		case "clipboard":
-			backends = append(backends, NewClipboardBackend())
-		default:
-			log.Printf("Injection: unknown backend %q, skipping", name)
+		backend := NewBackend(name)
+		if backend == nil {
+			logger.Warn("Unknown backend, skipping", "backend", name)
+			continue
 		}
+		backends = append(backends, backend)
 	}
 
 	// Default to clipboard if no valid backends
 	if len(backends) == 0 {
-		log.Printf("Injection: no valid backends configured, defaulting to clipboard")
+		logger.Warn("No valid backends configured, defaulting to clipboard")
 		backends = append(backends, NewClipboardBackend())
 	}
 
@@ -62,25 +82,75 @@ func (i *injector) Inject(ctx context.Context, text string) error {
 		timeout := i.getTimeout(backend.Name())
 		err := backend.Inject(ctx, text, timeout)
 		if err == nil {
-			log.Printf("Injection: success via %s", backend.Name())
+			logger.Info("Injected text", "backend", backend.Name(), "chars", utf8.RuneCountInString(text))
+			i.last = Injection{Text: text, Backend: backend.Name()}
+			if cb, ok := backend.(*clipboardBackend); ok {
+				i.last.PreviousClipboard, i.last.ClipboardSaved = cb.Previous()
+			}
 			return nil
 		}
-		log.Printf("Injection: %s failed: %v, trying next backend", backend.Name(), err)
+		logger.Warn("Backend failed, trying next one", "backend", backend.Name(), "err", err)
 		lastErr = err
 	}
 
 	return fmt.Errorf("all injection backends failed, last error: %w", lastErr)
 }
 
+func (i *injector) LastInjection() Injection {
+	return i.last
+}
+
 func (i *injector) getTimeout(backendName string) time.Duration {
+	return i.config.timeout(backendName)
+}
+
+func (c Config) timeout(backendName string) time.Duration {
 	switch backendName {
 	case "ydotool":
-		return i.config.YdotoolTimeout
+		return c.YdotoolTimeout
 	case "wtype":
-		return i.config.WtypeTimeout
+		return c.WtypeTimeout
 	case "clipboard":
-		return i.config.ClipboardTimeout
+		return c.ClipboardTimeout
 	default:
 		return 5 * time.Second
 	}
 }
+
+// NewBackend returns the backend with the given name, or nil if it is unknown
+func NewBackend(name string) Backend {
+	switch name {
+	case "ydotool":
+		return NewYdotoolBackend()
+	case "wtype":
+		return NewWtypeBackend()
+	case "clipboard":
+		return NewClipboardBackend()
+	default:
+		return nil
+	}
+}
+
+// Undo reverts inj: typing backends erase the text with BackSpace, the
+// clipboard backend restores the clipboard content it replaced
+func Undo(ctx context.Context, config Config, inj Injection) error {
+	if inj.Backend == "clipboard" {
+		if !inj.ClipboardSaved {
+			return fmt.Errorf("previous clipboard content was not saved")
+		}
+		return RestoreClipboard(ctx, inj.PreviousClipboard, config.ClipboardTimeout)
+	}
+
+	eraser, ok := NewBackend(inj.Backend).(Eraser)
+	if !ok {
+		return fmt.Errorf("cannot undo injection via %q", inj.Backend)
+	}
+
+	count := utf8.RuneCountInString(inj.Text)
+	if count == 0 {
+		return nil
+	}
+	// typing tools pace key events, so allow time proportional to the text
+	timeout := config.timeout(inj.Backend) + time.Duration(count)*eraseKeyDelay
+	return eraser.Erase(ctx, count, timeout)
+}
@@ -284,3 +284,48 @@ func TestInjector_EmptyText(t *testing.T) {
 		t.Errorf("Inject() error message = %q, want %q", err.Error(), "cannot inject empty text")
 	}
 }
+
+// TestBackspaceArgs tests the key sequences used to undo typed text
+func TestBackspaceArgs(t *testing.T) {
+	wtype := wtypeBackspaceArgs(2)
+	if want := []string{"-k", "BackSpace", "-k", "BackSpace"}; !equalArgs(wtype, want) {
+		t.Errorf("wtypeBackspaceArgs(2) = %v, want %v", wtype, want)
+	}
+
+	ydotool := ydotoolBackspaceArgs(2)
+	if want := []string{"key", "14:1", "14:0", "14:1", "14:0"}; !equalArgs(ydotool, want) {
+		t.Errorf("ydotoolBackspaceArgs(2) = %v, want %v", ydotool, want)
+	}
+}
+
+// TestUndo_Errors tests injections that cannot be undone
+func TestUndo_Errors(t *testing.T) {
+	config := Config{
+		YdotoolTimeout:   time.Second,
+		WtypeTimeout:     time.Second,
+		ClipboardTimeout: time.Second,
+	}
+	ctx := context.Background()
+
+	if err := Undo(ctx, config, Injection{Text: "hi", Backend: "clipboard"}); err == nil {
+		t.Errorf("Undo() without saved clipboard should fail")
+	}
+	if err := Undo(ctx, config, Injection{Text: "hi", Backend: "carrier-pigeon"}); err == nil {
+		t.Errorf("Undo() with unknown backend should fail")
+	}
+	if err := Undo(ctx, config, Injection{Backend: "wtype"}); err != nil {
+		t.Errorf("Undo() of empty text = %v, want nil", err)
+	}
+}
+
+func equalArgs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
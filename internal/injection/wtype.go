@@ -49,3 +49,28 @@ func (w *wtypeBackend) Inject(ctx context.Context, text string, timeout time.Dur
 
 	return nil
 }
+
+func (w *wtypeBackend) Erase(ctx context.Context, count int, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := w.Available(); err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, "wtype", wtypeBackspaceArgs(count)...)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("wtype failed: %w", err)
+	}
+
+	return nil
+}
+
+// wtypeBackspaceArgs returns wtype arguments pressing BackSpace count times
+func wtypeBackspaceArgs(count int) []string {
+	args := make([]string, 0, count*2)
+	for i := 0; i < count; i++ {
+		args = append(args, "-k", "BackSpace")
+	}
+	return args
+}
@@ -94,3 +94,32 @@ func (y *ydotoolBackend) Inject(ctx context.Context, text string, timeout time.D
 
 	return nil
 }
+
+// ydotoolBackspace is the Linux input event code for KEY_BACKSPACE
+const ydotoolBackspace = 14
+
+func (y *ydotoolBackend) Erase(ctx context.Context, count int, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := y.Available(); err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, "ydotool", ydotoolBackspaceArgs(count)...)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ydotool failed: %w", err)
+	}
+
+	return nil
+}
+
+// ydotoolBackspaceArgs returns ydotool arguments pressing and releasing BackSpace count times
+func ydotoolBackspaceArgs(count int) []string {
+	args := make([]string, 0, count*2+1)
+	args = append(args, "key")
+	for i := 0; i < count; i++ {
+		args = append(args, fmt.Sprintf("%d:1", ydotoolBackspace), fmt.Sprintf("%d:0", ydotoolBackspace))
+	}
+	return args
+}
@@ -3,9 +3,9 @@ package llm
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 	"github.com/sashabaranov/go-openai"
 )
 
@@ -59,7 +59,7 @@ func (a *GroqAdapter) Process(ctx context.Context, text string) (string, error)
 	duration := time.Since(start)
 
 	if err != nil {
-		log.Printf("groq-llm-adapter: API call failed after %v: %v", duration, err)
+		logger.Error("API call failed", "provider", "groq", "duration", duration, "err", err)
 		return "", fmt.Errorf("groq chat completion: %w", err)
 	}
 
@@ -68,6 +68,6 @@ func (a *GroqAdapter) Process(ctx context.Context, text string) (string, error)
 	}
 
 	result := resp.Choices[0].Message.Content
-	log.Printf("groq-llm-adapter: processed in %v: %q -> %q", duration, text, result)
+	logger.Info("Processed text", "provider", "groq", "duration", duration, logging.Text("input", text), logging.Text("output", result))
 	return result, nil
 }
@@ -3,9 +3,9 @@ package llm
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 	"github.com/sashabaranov/go-openai"
 )
 
@@ -57,7 +57,7 @@ func (a *OpenAIAdapter) Process(ctx context.Context, text string) (string, error
 	duration := time.Since(start)
 
 	if err != nil {
-		log.Printf("openai-llm-adapter: API call failed after %v: %v", duration, err)
+		logger.Error("API call failed", "provider", "openai", "duration", duration, "err", err)
 		return "", fmt.Errorf("openai chat completion: %w", err)
 	}
 
@@ -66,6 +66,6 @@ func (a *OpenAIAdapter) Process(ctx context.Context, text string) (string, error
 	}
 
 	result := resp.Choices[0].Message.Content
-	log.Printf("openai-llm-adapter: processed in %v: %q -> %q", duration, text, result)
+	logger.Info("Processed text", "provider", "openai", "duration", duration, logging.Text("input", text), logging.Text("output", result))
 	return result, nil
 }
@@ -3,8 +3,12 @@ package llm
 import (
 	"context"
 	"fmt"
+
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 )
 
+var logger = logging.For("llm")
+
 // Adapter interface for LLM text processing
 type Adapter interface {
 	Process(ctx context.Context, text string) (string, error)
@@ -0,0 +1,213 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FileName is the log file inside the hyprvoice state directory
+const FileName = "hyprvoice.log"
+
+// Level names accepted in [logging] level
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// Rotation defaults used when not configured
+const (
+	DefaultMaxSizeMB = 10
+	DefaultMaxFiles  = 3
+)
+
+// Config controls the level and destinations of the daemon log
+type Config struct {
+	Level     string // debug, info, warn or error ("" = info)
+	File      bool   // also write to Path
+	Path      string // empty = Path("")
+	MaxSizeMB int    // rotate the file at this size (0 = DefaultMaxSizeMB)
+	MaxFiles  int    // rotated files to keep (0 = DefaultMaxFiles)
+}
+
+var (
+	level slog.LevelVar
+
+	mu   sync.Mutex
+	file *rotatingFile
+)
+
+// ParseLevel converts a level name to a slog level
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "", LevelInfo:
+		return slog.LevelInfo, nil
+	case LevelDebug:
+		return slog.LevelDebug, nil
+	case LevelWarn, "warning":
+		return slog.LevelWarn, nil
+	case LevelError:
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("invalid log level: %q (use debug, info, warn or error)", name)
+}
+
+// Setup makes the default slog logger, and with it the standard log
+// package, write leveled records to stderr and, when cfg.File is set, to a
+// rotating log file. It can be called again after a config reload; the
+// previous file is closed. When the file cannot be opened, logging still
+// switches to cfg.Level on stderr and the error is returned.
+func Setup(cfg Config) error {
+	lvl, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+
+	var next *rotatingFile
+	if cfg.File {
+		next, err = openConfigured(cfg)
+	}
+
+	mu.Lock()
+	prev := file
+	file = next
+	mu.Unlock()
+
+	level.Set(lvl)
+	install(next)
+
+	if prev != nil {
+		prev.Close()
+	}
+	return err
+}
+
+// openConfigured opens the log file described by cfg
+func openConfigured(cfg Config) (*rotatingFile, error) {
+	path := cfg.Path
+	if path == "" {
+		var err error
+		if path, err = Path(""); err != nil {
+			return nil, err
+		}
+	}
+	maxSize := cfg.MaxSizeMB
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSizeMB
+	}
+	maxFiles := cfg.MaxFiles
+	if maxFiles <= 0 {
+		maxFiles = DefaultMaxFiles
+	}
+	return openRotatingFile(path, int64(maxSize)<<20, maxFiles)
+}
+
+// Close closes the log file opened by Setup, if any
+func Close() error {
+	mu.Lock()
+	prev := file
+	file = nil
+	mu.Unlock()
+
+	if prev == nil {
+		return nil
+	}
+	install(nil)
+	return prev.Close()
+}
+
+// install makes the default logger write to stderr and f, if not nil
+func install(f *rotatingFile) {
+	var out io.Writer = os.Stderr
+	if f != nil {
+		out = io.MultiWriter(os.Stderr, f)
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: &level})))
+}
+
+// DebugEnabled reports whether debug records are logged
+func DebugEnabled() bool {
+	return slog.Default().Enabled(context.Background(), slog.LevelDebug)
+}
+
+// Path returns the log file of a daemon instance,
+// $XDG_STATE_HOME/hyprvoice/hyprvoice.log for the default instance and
+// hyprvoice-<instance>.log for named ones
+func Path(instance string) (string, error) {
+	name := FileName
+	if instance != "" {
+		name = strings.TrimSuffix(FileName, ".log") + "-" + instance + ".log"
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "hyprvoice", name), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "hyprvoice", name), nil
+}
+
+// For returns a logger tagged with component. It always writes through
+// the current default logger, so it can be created at package init, before
+// Setup runs.
+func For(component string) *slog.Logger {
+	return slog.New(defaultHandler{}).With("component", component)
+}
+
+// defaultHandler forwards records to the handler of slog.Default() at the
+// time they are logged, replaying the attrs and groups added to it
+type defaultHandler struct {
+	wrap []func(slog.Handler) slog.Handler
+}
+
+func (h defaultHandler) handler() slog.Handler {
+	handler := slog.Default().Handler()
+	for _, wrap := range h.wrap {
+		handler = wrap(handler)
+	}
+	return handler
+}
+
+func (h defaultHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return slog.Default().Handler().Enabled(ctx, lvl)
+}
+
+func (h defaultHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler().Handle(ctx, r)
+}
+
+func (h defaultHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h defaultHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+func (h defaultHandler) with(wrap func(slog.Handler) slog.Handler) slog.Handler {
+	return defaultHandler{wrap: append(h.wrap[:len(h.wrap):len(h.wrap)], wrap)}
+}
+
+// Text returns an attribute for dictated text, such as a transcript or LLM
+// output. The text is only logged at debug level; otherwise just its
+// length is, so the log does not become a copy of everything dictated.
+func Text(key, text string) slog.Attr {
+	return slog.Any(key, redacted(text))
+}
+
+type redacted string
+
+func (r redacted) LogValue() slog.Value {
+	if DebugEnabled() {
+		return slog.StringValue(string(r))
+	}
+	return slog.StringValue(fmt.Sprintf("[%d chars redacted]", len([]rune(string(r)))))
+}
@@ -0,0 +1,228 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"", slog.LevelInfo, false},
+		{"info", slog.LevelInfo, false},
+		{"DEBUG", slog.LevelDebug, false},
+		{"warn", slog.LevelWarn, false},
+		{"warning", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSetup_FileAndRedaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", FileName)
+	t.Cleanup(func() { Close() })
+
+	if err := Setup(Config{Level: LevelInfo, File: true, Path: path}); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	logger := For("test")
+	logger.Debug("Hidden")
+	logger.Info("Transcribed", Text("text", "secret words"))
+
+	if err := Setup(Config{Level: LevelDebug, File: true, Path: path}); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	logger.Debug("Transcribed", Text("text", "visible words"))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	out := string(data)
+	if strings.Contains(out, "Hidden") {
+		t.Errorf("debug record logged at info level:\n%s", out)
+	}
+	if strings.Contains(out, "secret") || !strings.Contains(out, "[12 chars redacted]") {
+		t.Errorf("text not redacted at info level:\n%s", out)
+	}
+	if !strings.Contains(out, "visible words") {
+		t.Errorf("text redacted at debug level:\n%s", out)
+	}
+	if !strings.Contains(out, "component=test") {
+		t.Errorf("component attribute missing:\n%s", out)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("log file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestSetup_InvalidLevel(t *testing.T) {
+	if err := Setup(Config{Level: "loud"}); err == nil {
+		t.Error("Setup() with invalid level should fail")
+	}
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+
+	got, err := Path("")
+	if err != nil || got != "/state/hyprvoice/hyprvoice.log" {
+		t.Errorf(`Path("") = %q, %v`, got, err)
+	}
+	got, err = Path("work")
+	if err != nil || got != "/state/hyprvoice/hyprvoice-work.log" {
+		t.Errorf(`Path("work") = %q, %v`, got, err)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	f, err := openRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("openRotatingFile() error = %v", err)
+	}
+	defer f.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	want := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for p, content := range want {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", p, err)
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(p), data, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("%s.3 should not exist, stat error = %v", FileName, err)
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	data := []byte("a\nb\nc\n")
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "a\nb\nc\n"},
+		{1, "c\n"},
+		{2, "b\nc\n"},
+		{5, "a\nb\nc\n"},
+	}
+	for _, tt := range tests {
+		if got := string(lastLines(data, tt.n)); got != tt.want {
+			t.Errorf("lastLines(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	var buf bytes.Buffer
+	if err := Tail(context.Background(), path, &buf, 10, false); err == nil {
+		t.Error("Tail() on missing file should fail")
+	}
+
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Tail(context.Background(), path, &buf, 2, false); err != nil {
+		t.Fatalf("Tail() error = %v", err)
+	}
+	if buf.String() != "two\nthree\n" {
+		t.Errorf("Tail() = %q, want last two lines", buf.String())
+	}
+}
+
+func TestTail_Follow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	f, err := openRotatingFile(path, 8, 1)
+	if err != nil {
+		t.Fatalf("openRotatingFile() error = %v", err)
+	}
+	defer f.Close()
+	f.Write([]byte("old\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &syncBuffer{}
+	done := make(chan error, 1)
+	go func() { done <- Tail(ctx, path, out, 0, true) }()
+
+	waitFor(t, out, "old")
+	// the second write rotates the file
+	f.Write([]byte("new\n"))
+	f.Write([]byte("rotated\n"))
+	waitFor(t, out, "rotated")
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Tail() error = %v", err)
+	}
+	if got := out.String(); got != "old\nnew\nrotated\n" {
+		t.Errorf("Tail() followed %q", got)
+	}
+}
+
+func waitFor(t *testing.T, out *syncBuffer, text string) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !strings.Contains(out.String(), text) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %q, got %q", text, out.String())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for one writer and one reader
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// pollInterval is how often Tail checks a followed file for new lines
+const pollInterval = 250 * time.Millisecond
+
+// Tail writes the last n lines of the log file at path to w (all of them
+// when n <= 0). With follow it keeps writing lines as they are appended,
+// across rotations, until ctx ends.
+func Tail(ctx context.Context, path string, w io.Writer, n int, follow bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no log file at %s (set file = true in [logging])", path)
+		}
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer func() { f.Close() }()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("failed to read log file: %w", err)
+	}
+	if _, err := w.Write(lastLines(data, n)); err != nil {
+		return err
+	}
+	if !follow {
+		return nil
+	}
+
+	reader := bufio.NewReader(f)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := io.Copy(w, reader); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// after a rotation path is a new file; finish the old one first
+		if rotated(f, path) {
+			if _, err := io.Copy(w, reader); err != nil {
+				return err
+			}
+			next, err := os.Open(path)
+			if err != nil {
+				continue
+			}
+			f.Close()
+			f = next
+			reader.Reset(f)
+		}
+	}
+}
+
+// rotated reports whether path no longer names the open file f
+func rotated(f *os.File, path string) bool {
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	open, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return !os.SameFile(current, open)
+}
+
+// lastLines returns the last n lines of data
+func lastLines(data []byte, n int) []byte {
+	if n <= 0 {
+		return data
+	}
+	end := len(data)
+	if end > 0 && data[end-1] == '\n' {
+		end--
+	}
+	for i := 0; i < n; i++ {
+		idx := bytes.LastIndexByte(data[:end], '\n')
+		if idx < 0 {
+			return data
+		}
+		end = idx
+	}
+	return data[end+1:]
+}
@@ -11,6 +11,9 @@ const (
 	MsgOperationCancelled
 	MsgRecordingAborted
 	MsgInjectionAborted
+	MsgTimeoutWarning
+	MsgDictationSaved
+	MsgMicSilent
 )
 
 // MessageDef defines a message type with its config key and defaults
@@ -31,6 +34,19 @@ var MessageDefs = []MessageDef{
 	{MsgOperationCancelled, "operation_cancelled", "Hyprvoice", "Operation Cancelled", false},
 	{MsgRecordingAborted, "recording_aborted", "", "Recording Aborted", true},
 	{MsgInjectionAborted, "injection_aborted", "", "Injection Aborted", true},
+	{MsgTimeoutWarning, "timeout_warning", "Hyprvoice", "Recording stops soon", false},
+	{MsgDictationSaved, "dictation_saved", "Hyprvoice", "Dictation saved to recovery file", false},
+	{MsgMicSilent, "mic_silent", "Hyprvoice", "No sound from the microphone, is it muted?", false},
+}
+
+// String returns the config key of the message type (e.g. "recording_started")
+func (mt MessageType) String() string {
+	for _, def := range MessageDefs {
+		if def.Type == mt {
+			return def.ConfigKey
+		}
+	}
+	return "unknown"
 }
 
 // Message is a resolved message ready for display
@@ -1,10 +1,13 @@
 package notify
 
 import (
-	"log"
 	"os/exec"
+
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 )
 
+var logger = logging.For("notify")
+
 type Notifier interface {
 	Send(mt MessageType)
 	Error(msg string) // for dynamic errors (e.g., pipeline errors)
@@ -45,14 +48,14 @@ func (d *Desktop) Send(mt MessageType) {
 func (d *Desktop) Error(msg string) {
 	cmd := exec.Command("notify-send", "-a", "Hyprvoice", "-u", "critical", "Hyprvoice Error", msg)
 	if err := cmd.Run(); err != nil {
-		log.Printf("Failed to send error notification: %v", err)
+		logger.Warn("Failed to send error notification", "err", err)
 	}
 }
 
 func (d *Desktop) notify(title, body string) {
 	cmd := exec.Command("notify-send", "-a", "Hyprvoice", title, body)
 	if err := cmd.Run(); err != nil {
-		log.Printf("Failed to send notification: %v", err)
+		logger.Warn("Failed to send notification", "err", err)
 	}
 }
 
@@ -73,11 +76,11 @@ func (l *Log) Send(mt MessageType) {
 		l.Error(msg.Body)
 		return
 	}
-	log.Printf("%s: %s", msg.Title, msg.Body)
+	logger.Info("Notification", "title", msg.Title, "body", msg.Body)
 }
 
 func (l *Log) Error(msg string) {
-	log.Printf("Hyprvoice Error: %s", msg)
+	logger.Error("Notification", "title", "Hyprvoice Error", "body", msg)
 }
 
 type Nop struct{}
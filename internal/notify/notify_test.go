@@ -105,8 +105,8 @@ func TestNotifierInterface(t *testing.T) {
 
 func TestMessageDefs(t *testing.T) {
 	// Verify MessageDefs contains expected entries
-	if len(MessageDefs) != 7 {
-		t.Errorf("Expected 7 MessageDefs, got %d", len(MessageDefs))
+	if len(MessageDefs) != 10 {
+		t.Errorf("Expected 10 MessageDefs, got %d", len(MessageDefs))
 	}
 
 	// Verify each has required fields
@@ -127,3 +127,12 @@ func TestSend_UnknownMessageType(t *testing.T) {
 	// Should not panic with unknown message type
 	desktop.Send(MessageType(999))
 }
+
+func TestMessageType_String(t *testing.T) {
+	if got := MsgRecordingStarted.String(); got != "recording_started" {
+		t.Errorf("MsgRecordingStarted.String() = %q, want recording_started", got)
+	}
+	if got := MessageType(999).String(); got != "unknown" {
+		t.Errorf("MessageType(999).String() = %q, want unknown", got)
+	}
+}
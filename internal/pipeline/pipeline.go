@@ -2,18 +2,27 @@ package pipeline
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
+	"unicode/utf8"
 
 	"github.com/leonardotrapani/hyprvoice/internal/config"
+	"github.com/leonardotrapani/hyprvoice/internal/history"
 	"github.com/leonardotrapani/hyprvoice/internal/injection"
 	"github.com/leonardotrapani/hyprvoice/internal/llm"
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 	"github.com/leonardotrapani/hyprvoice/internal/notify"
 	"github.com/leonardotrapani/hyprvoice/internal/recording"
+	"github.com/leonardotrapani/hyprvoice/internal/stats"
 	"github.com/leonardotrapani/hyprvoice/internal/transcriber"
 )
 
+var logger = logging.For("pipeline")
+
 type Status string
 type Action string
 
@@ -28,6 +37,7 @@ const (
 	Recording    Status = "recording"
 	Transcribing Status = "transcribing"
 	Processing   Status = "processing" // LLM post-processing
+	Queued       Status = "queued"     // waiting for earlier dictations to inject
 	Injecting    Status = "injecting"
 )
 
@@ -36,13 +46,43 @@ const (
 	Cancel Action = "cancel"
 )
 
+// finalizeTimeout bounds finalizing a dictation, so a hanging provider can
+// neither keep the pipeline busy nor keep the daemon from exiting
+const finalizeTimeout = 60 * time.Second
+
+// queueTimeout bounds waiting for earlier dictations to inject. Each of them
+// finalizes within finalizeTimeout, so only a stuck one makes it expire.
+const queueTimeout = 2 * finalizeTimeout
+
 type Pipeline interface {
 	Run(ctx context.Context)
 	Stop()
+	// Wait blocks until the current run has ended, including finalizing a
+	// dictation interrupted by the end of the context passed to Run
+	Wait()
 	Status() Status
 	GetActionCh() chan<- Action
 	GetErrorCh() <-chan PipelineError
 	GetNotifyCh() <-chan notify.MessageType
+	GetStatusCh() <-chan Status
+	GetTranscriptCh() <-chan transcriber.TranscriptionResult
+	GetInjectedCh() <-chan injection.Injection
+	// GetLevelsCh delivers the input levels every recording.MeterInterval
+	// while recording
+	GetLevelsCh() <-chan recording.Levels
+	// Result returns the outcome of the current run, complete once Wait returns
+	Result() Result
+}
+
+// Result is the outcome of a run
+type Result struct {
+	Transcript string // raw transcription
+	Processed  string // LLM output, empty when the LLM was off or failed
+	Text       string // text injected, or meant to be
+	Backend    string // injection backend that delivered Text
+	Injected   bool
+	Cancelled  bool     // the run was aborted with Stop
+	Errors     []string // recording, transcription, LLM and injection failures
 }
 
 // Factory types for dependency injection
@@ -50,6 +90,8 @@ type RecorderFactory func(cfg recording.Config) recording.Recorder
 type TranscriberFactory func(cfg transcriber.Config) (transcriber.Transcriber, error)
 type InjectorFactory func(cfg injection.Config) injection.Injector
 type LLMAdapterFactory func(cfg llm.Config) (llm.Adapter, error)
+type HistoryFactory func(cfg history.Config) (history.Store, error)
+type StatsFactory func(cfg stats.Config) (stats.Store, error)
 
 // Option configures the pipeline
 type Option func(*pipeline)
@@ -82,12 +124,38 @@ func WithLLMAdapterFactory(f LLMAdapterFactory) Option {
 	}
 }
 
+// WithHistoryFactory sets a custom history store factory
+func WithHistoryFactory(f HistoryFactory) Option {
+	return func(p *pipeline) {
+		p.historyFactory = f
+	}
+}
+
+// WithStatsFactory sets a custom stats store factory
+func WithStatsFactory(f StatsFactory) Option {
+	return func(p *pipeline) {
+		p.statsFactory = f
+	}
+}
+
+// WithTurn makes the pipeline wait for t before injecting and end t when
+// its run ends, so overlapping sessions inject in order
+func WithTurn(t *Turn) Option {
+	return func(p *pipeline) {
+		p.turn = t
+	}
+}
+
 type pipeline struct {
-	status   Status
-	actionCh chan Action
-	errorCh  chan PipelineError
-	notifyCh chan notify.MessageType
-	config   *config.Config
+	status       Status
+	actionCh     chan Action
+	errorCh      chan PipelineError
+	notifyCh     chan notify.MessageType
+	statusCh     chan Status
+	transcriptCh chan transcriber.TranscriptionResult
+	injectedCh   chan injection.Injection
+	levelsCh     chan recording.Levels
+	config       *config.Config
 
 	mu       sync.RWMutex
 	wg       sync.WaitGroup
@@ -95,25 +163,37 @@ type pipeline struct {
 	stopOnce sync.Once
 
 	running atomic.Bool
+	result  Result
+
+	// turn orders injections across overlapping sessions (nil when alone)
+	turn *Turn
 
 	// dependency factories (for testing)
 	recorderFactory    RecorderFactory
 	transcriberFactory TranscriberFactory
 	injectorFactory    InjectorFactory
 	llmAdapterFactory  LLMAdapterFactory
+	historyFactory     HistoryFactory
+	statsFactory       StatsFactory
 }
 
 func New(cfg *config.Config, opts ...Option) Pipeline {
 	p := &pipeline{
-		actionCh: make(chan Action, 1),
-		errorCh:  make(chan PipelineError, 10),
-		notifyCh: make(chan notify.MessageType, 10),
-		config:   cfg,
+		actionCh:     make(chan Action, 1),
+		errorCh:      make(chan PipelineError, 10),
+		notifyCh:     make(chan notify.MessageType, 10),
+		statusCh:     make(chan Status, 10),
+		transcriptCh: make(chan transcriber.TranscriptionResult, 32),
+		injectedCh:   make(chan injection.Injection, 1),
+		levelsCh:     make(chan recording.Levels, 10),
+		config:       cfg,
 		// default factories
 		recorderFactory:    recording.NewRecorder,
 		transcriberFactory: transcriber.NewTranscriber,
 		injectorFactory:    injection.NewInjector,
 		llmAdapterFactory:  llm.NewAdapter,
+		historyFactory:     history.NewStore,
+		statsFactory:       stats.NewStore,
 	}
 
 	for _, opt := range opts {
@@ -124,58 +204,91 @@ func New(cfg *config.Config, opts ...Option) Pipeline {
 }
 func (p *pipeline) Run(ctx context.Context) {
 	if !p.running.CompareAndSwap(false, true) {
-		log.Printf("Pipeline: Already running, ignoring Run() call")
+		logger.Warn("Already running, ignoring Run() call")
 		return
 	}
 
-	runCtx, cancel := context.WithTimeout(ctx, p.config.Recording.Timeout)
+	// Components run on a session context that is not canceled with ctx, so
+	// a dictation still recording at daemon shutdown can be finalized.
+	// Stop cancels the session and discards it.
+	session, cancel := context.WithCancel(context.WithoutCancel(ctx))
 	p.setCancel(cancel)
+	p.mu.Lock()
+	p.result = Result{}
+	p.mu.Unlock()
+
+	// report Recording right away so callers never see a just started
+	// pipeline as idle
+	p.setStatus(Recording)
 
 	p.wg.Add(1)
-	go p.run(runCtx)
+	go p.run(ctx, session)
 }
 
-func (p *pipeline) run(ctx context.Context) {
+func (p *pipeline) run(ctx, session context.Context) {
 	defer func() {
+		if p.turn != nil {
+			p.turn.End()
+		}
 		p.running.Store(false)
 		p.setStatus(Idle)
 		p.wg.Done()
 	}()
 
-	log.Printf("Pipeline: Starting recording")
-	p.setStatus(Recording)
+	logger.Info("Starting recording")
+	recordingStart := time.Now()
 
-	recorder := p.recorderFactory(p.config.ToRecordingConfig())
-	frameCh, rErrCh, err := recorder.Start(ctx)
+	recCfg := p.config.ToRecordingConfig()
+	recorder := p.recorderFactory(recCfg)
+	frameCh, rErrCh, err := recorder.Start(session)
 
 	if err != nil {
-		log.Printf("Pipeline: Recording error: %v", err)
+		logger.Error("Failed to start recording", "err", err)
 		p.sendError("Recording Error", "Failed to start recording", err)
 		return
 	}
 
 	defer recorder.Stop()
+	captureStart := time.Now()
+
+	// meter the input for clients and to catch a muted microphone
+	var levelCh <-chan recording.Levels
+	var silentCh <-chan struct{}
+	if meter := recording.NewMeter(recCfg); meter != nil {
+		frameCh, levelCh, silentCh = recording.MeterFrames(frameCh, meter)
+	}
+
+	// with [recording.vad] silence after speech stops the recording like a
+	// second toggle
+	var silenceCh <-chan struct{}
+	if vad := recording.NewVAD(recCfg); vad != nil {
+		frameCh, silenceCh = recording.StopOnSilence(frameCh, vad)
+	}
+
+	// a capture that ends by itself, like a file backend at its end, stops
+	// the recording like a second toggle
+	frameCh, endedCh := recording.WatchEnd(frameCh)
 
 	t, err := p.transcriberFactory(p.config.ToTranscriberConfig())
 	if err != nil {
-		log.Printf("Pipeline: Failed to create transcriber: %v", err)
+		logger.Error("Failed to create transcriber", "err", err)
 		p.sendError("Transcription Error", "Failed to create transcriber", err)
 		return
 	}
 
-	log.Printf("Pipeline: Starting transcriber")
+	logger.Debug("Starting transcriber")
 	p.setStatus(Transcribing)
 
-	tErrCh, err := t.Start(ctx, frameCh)
+	tErrCh, err := t.Start(session, frameCh)
 	if err != nil {
-		log.Printf("Pipeline: Transcriber error: %v", err)
+		logger.Error("Failed to start transcriber", "err", err)
 		p.sendError("Transcription Error", "Failed to start transcriber", err)
 		return
 	}
 
 	defer func() {
-		if stopErr := t.Stop(ctx); stopErr != nil {
-			log.Printf("Pipeline: Error stopping transcriber: %v", stopErr)
+		if stopErr := t.Stop(session); stopErr != nil {
+			logger.Debug("Error stopping transcriber", "err", stopErr)
 			// Silently call an error now because on simple transcriber we just transcribe all audio when we stop, and might fail when force stop
 			//p.sendError("Transcription Error", "Failed to stop transcriber cleanly", stopErr)
 		}
@@ -194,16 +307,68 @@ func (p *pipeline) run(ctx context.Context) {
 		}
 	}()
 
+	// Forward live streaming results until this run ends
+	if reporter, ok := t.(transcriber.ResultReporter); ok {
+		done := make(chan struct{})
+		defer close(done)
+		go p.forwardResults(reporter.Results(), done)
+	}
+
+	timeout := time.NewTimer(p.config.Recording.Timeout)
+	defer timeout.Stop()
+
+	var warningCh <-chan time.Time
+	if warning := p.config.Recording.TimeoutWarning; warning > 0 && warning < p.config.Recording.Timeout {
+		warningTimer := time.NewTimer(p.config.Recording.Timeout - warning)
+		defer warningTimer.Stop()
+		warningCh = warningTimer.C
+	}
+
 	for {
 		select {
 		case action := <-p.actionCh:
 			switch action {
 			case Inject:
-				p.handleInjectAction(ctx, recorder, t)
+				p.handleInjectAction(session, recorder, t, recordingStart, captureStart)
 				return
 			}
 
+		case <-silenceCh:
+			logger.Info("Silence detected, stopping recording", "silence", recCfg.VAD.Silence)
+			p.handleInjectAction(session, recorder, t, recordingStart, captureStart)
+			return
+
+		case <-endedCh:
+			if session.Err() != nil {
+				return
+			}
+			logger.Info("Capture ended, stopping recording", "backend", recCfg.Backend)
+			p.handleInjectAction(session, recorder, t, recordingStart, captureStart)
+			return
+
+		case levels := <-levelCh:
+			p.sendLevels(levels)
+
+		case <-silentCh:
+			silentCh = nil
+			logger.Warn("Microphone is silent, check that it is not muted and recording.device", "after", recording.SilentMicAfter, "device", recCfg.Device)
+			p.sendNotify(notify.MsgMicSilent)
+
+		case <-warningCh:
+			logger.Info("Recording timeout approaching", "in", p.config.Recording.TimeoutWarning)
+			p.sendNotify(notify.MsgTimeoutWarning)
+
+		case <-timeout.C:
+			logger.Info("Recording timeout reached", "timeout", p.config.Recording.Timeout)
+			p.handleInterrupt(session, p.config.Recording.OnTimeout, "recording timeout", recorder, t, recordingStart, captureStart)
+			return
+
 		case <-ctx.Done():
+			logger.Info("Shutdown requested while recording")
+			p.handleInterrupt(session, p.config.Recording.OnShutdown, "daemon shutdown", recorder, t, recordingStart, captureStart)
+			return
+
+		case <-session.Done():
 			return
 		}
 	}
@@ -218,7 +383,16 @@ func (p *pipeline) Status() Status {
 func (p *pipeline) setStatus(status Status) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	if p.status == status {
+		return
+	}
 	p.status = status
+
+	select {
+	case p.statusCh <- status:
+	default:
+		logger.Warn("Status channel full, dropping transition", "status", status)
+	}
 }
 
 func (p *pipeline) setCancel(cancel context.CancelFunc) {
@@ -251,17 +425,102 @@ func (p *pipeline) GetNotifyCh() <-chan notify.MessageType {
 	return p.notifyCh
 }
 
+func (p *pipeline) GetStatusCh() <-chan Status {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.statusCh
+}
+
+func (p *pipeline) GetTranscriptCh() <-chan transcriber.TranscriptionResult {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.transcriptCh
+}
+
+func (p *pipeline) GetInjectedCh() <-chan injection.Injection {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.injectedCh
+}
+
+func (p *pipeline) GetLevelsCh() <-chan recording.Levels {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.levelsCh
+}
+
+func (p *pipeline) Result() Result {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	r := p.result
+	r.Errors = append([]string(nil), p.result.Errors...)
+	return r
+}
+
+// setResult records the texts of a finished dictation
+func (p *pipeline) setResult(entry history.Entry, injected bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.result.Transcript = entry.Transcript
+	p.result.Processed = entry.LLMOutput
+	p.result.Text = entry.Text()
+	p.result.Backend = entry.Backend
+	p.result.Injected = injected
+}
+
+// recordError adds a failure to the result of the current run
+func (p *pipeline) recordError(message string, err error) {
+	if err != nil {
+		message = fmt.Sprintf("%s: %v", message, err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.result.Errors = append(p.result.Errors, message)
+}
+
+func (p *pipeline) forwardResults(resultsCh <-chan transcriber.TranscriptionResult, done <-chan struct{}) {
+	for {
+		select {
+		case result := <-resultsCh:
+			select {
+			case p.transcriptCh <- result:
+			default:
+				logger.Warn("Transcript channel full, dropping result")
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
 func (p *pipeline) sendError(title, message string, err error) {
 	pipelineErr := PipelineError{
 		Title:   title,
 		Message: message,
 		Err:     err,
 	}
+	p.recordError(message, err)
 
 	select {
 	case p.errorCh <- pipelineErr:
 	default:
-		log.Printf("Pipeline: Error channel full, dropping error: %s", message)
+		logger.Warn("Error channel full, dropping error", "error", message)
+	}
+}
+
+func (p *pipeline) sendInjected(injected injection.Injection) {
+	select {
+	case p.injectedCh <- injected:
+	default:
+		logger.Warn("Injected channel full, dropping injection")
+	}
+}
+
+// sendLevels drops levels nobody reads; the next ones follow shortly
+func (p *pipeline) sendLevels(levels recording.Levels) {
+	select {
+	case p.levelsCh <- levels:
+	default:
 	}
 }
 
@@ -269,43 +528,92 @@ func (p *pipeline) sendNotify(mt notify.MessageType) {
 	select {
 	case p.notifyCh <- mt:
 	default:
-		log.Printf("Pipeline: Notify channel full, dropping notification")
+		logger.Warn("Notify channel full, dropping notification")
 	}
 }
 
-func (p *pipeline) handleInjectAction(ctx context.Context, recorder recording.Recorder, t transcriber.Transcriber) {
+func (p *pipeline) handleInjectAction(ctx context.Context, recorder recording.Recorder, t transcriber.Transcriber, recordingStart, captureStart time.Time) {
 	status := p.Status()
 
 	if status != Transcribing {
-		log.Printf("Pipeline: Inject action received, but not in transcribing state, ignoring")
+		logger.Info("Inject action received, but not in transcribing state, ignoring", "status", status)
+		return
+	}
+
+	logger.Info("Stopping recording and finalizing transcription")
+	p.finish(ctx, recorder, t, recordingStart, captureStart, config.PolicyInject, "")
+}
+
+// handleInterrupt ends a dictation the user did not stop (recording timeout
+// or daemon shutdown) according to policy
+func (p *pipeline) handleInterrupt(session context.Context, policy, reason string, recorder recording.Recorder, t transcriber.Transcriber, recordingStart, captureStart time.Time) {
+	if policy != config.PolicyInject && policy != config.PolicySave {
+		logger.Info("Discarding recording", "reason", reason)
+		p.sendNotify(notify.MsgRecordingAborted)
 		return
 	}
 
-	log.Printf("Pipeline: Inject action received, stopping recording and finalizing transcription")
+	logger.Info("Finalizing recording", "reason", reason, "policy", policy)
+	p.finish(session, recorder, t, recordingStart, captureStart, policy, reason)
+}
+
+// finish stops the recording, finalizes the transcription and injects the
+// text, or with config.PolicySave appends it to the recovery file. reason is
+// empty when the user stopped the dictation; otherwise a failed injection
+// also falls back to the recovery file since nobody is watching it.
+func (p *pipeline) finish(session context.Context, recorder recording.Recorder, t transcriber.Transcriber, recordingStart, captureStart time.Time, policy, reason string) {
+	ctx, cancel := context.WithTimeout(session, finalizeTimeout)
+	defer cancel()
+
 	p.setStatus(Injecting)
 
+	entry := p.newHistoryEntry()
+	entry.Durations.RecordingMs = time.Since(recordingStart).Milliseconds()
+	entry.Durations.RecorderStartMs = captureStart.Sub(recordingStart).Milliseconds()
+	injected := false
+	defer func() {
+		p.saveHistory(&entry)
+		p.saveStats(entry, injected)
+		p.setResult(entry, injected)
+	}()
+
 	recorder.Stop()
+	entry.Durations.AudioMs = time.Since(captureStart).Milliseconds()
 
+	stageStart := time.Now()
 	if err := t.Stop(ctx); err != nil {
 		p.sendError("Transcription Error", "Failed to stop transcriber during injection", err)
+		addHistoryError(&entry, "Failed to stop transcriber during injection", err)
 		return
 	}
 
 	transcriptionText, err := t.GetFinalTranscription()
+	entry.Durations.TranscriptionMs = time.Since(stageStart).Milliseconds()
 	if err != nil {
 		p.sendError("Transcription Error", "Failed to retrieve transcription", err)
+		addHistoryError(&entry, "Failed to retrieve transcription", err)
+		return
+	}
+	logger.Info("Transcription finished", logging.Text("text", transcriptionText), "ms", entry.Durations.TranscriptionMs)
+	entry.Transcript = transcriptionText
+
+	if policy == config.PolicySave {
+		p.saveRecovery(&entry, transcriptionText, reason)
+		p.setStatus(Idle)
 		return
 	}
-	log.Printf("Pipeline: Final transcription text: %s", transcriptionText)
 
 	// LLM post-processing phase
 	textToInject := transcriptionText
 	if p.config.IsLLMEnabled() {
 		p.setStatus(Processing)
 		p.sendNotify(notify.MsgLLMProcessing)
-		log.Printf("Pipeline: LLM post-processing enabled, processing text")
+		logger.Debug("Post-processing with LLM")
 
 		llmCfg := p.config.ToLLMConfig()
+		entry.LLMProvider = llmCfg.Provider
+		entry.LLMModel = llmCfg.Model
+
 		adapter, err := p.llmAdapterFactory(llm.Config{
 			Provider:          llmCfg.Provider,
 			APIKey:            llmCfg.APIKey,
@@ -318,32 +626,176 @@ func (p *pipeline) handleInjectAction(ctx context.Context, recorder recording.Re
 			Keywords:          llmCfg.Keywords,
 		})
 		if err != nil {
-			log.Printf("Pipeline: Failed to create LLM adapter: %v, using raw transcription", err)
+			logger.Warn("Failed to create LLM adapter, using raw transcription", "err", err)
+			addHistoryError(&entry, "Failed to create LLM adapter", err)
+			p.recordError("Failed to create LLM adapter", err)
 		} else {
+			stageStart = time.Now()
 			processed, err := adapter.Process(ctx, transcriptionText)
+			entry.Durations.LLMMs = time.Since(stageStart).Milliseconds()
 			if err != nil {
-				log.Printf("Pipeline: LLM processing failed: %v, using raw transcription", err)
+				logger.Warn("LLM processing failed, using raw transcription", "err", err)
+				addHistoryError(&entry, "LLM processing failed", err)
+				p.recordError("LLM processing failed", err)
 			} else {
 				textToInject = processed
-				log.Printf("Pipeline: LLM processed text: %s", textToInject)
+				entry.LLMOutput = processed
+				logger.Info("LLM processing finished", logging.Text("text", textToInject), "ms", entry.Durations.LLMMs)
 			}
 		}
 		p.setStatus(Injecting)
 	}
 
+	if p.turn != nil && !p.turn.Ready() {
+		p.setStatus(Queued)
+		if err := p.waitTurn(session); err != nil {
+			logger.Warn("Gave up waiting for earlier dictations to inject", "err", err)
+			addHistoryError(&entry, "Gave up waiting for earlier dictations to inject", err)
+			p.recordError("Gave up waiting for earlier dictations to inject", err)
+			// a stopped session is discarded, a stuck queue must not lose the text
+			if reason == "" && errors.Is(err, context.DeadlineExceeded) {
+				reason = "earlier dictations did not inject in time"
+			}
+			if reason != "" {
+				p.saveRecovery(&entry, textToInject, reason)
+			}
+			return
+		}
+		p.setStatus(Injecting)
+
+		// the wait does not count against the finalize deadline
+		var cancelInject context.CancelFunc
+		ctx, cancelInject = context.WithTimeout(session, finalizeTimeout)
+		defer cancelInject()
+	}
+
 	injector := p.injectorFactory(p.config.ToInjectionConfig())
 
-	if err := injector.Inject(ctx, textToInject); err != nil {
+	stageStart = time.Now()
+	err = injector.Inject(ctx, textToInject)
+	entry.Durations.InjectionMs = time.Since(stageStart).Milliseconds()
+
+	report := injection.Injection{Text: textToInject}
+	if err != nil {
 		p.sendError("Injection Error", "Failed to inject text", err)
+		addHistoryError(&entry, "Failed to inject text", err)
+		if reason != "" {
+			p.saveRecovery(&entry, textToInject, reason)
+		}
 	} else {
-		log.Printf("Pipeline: Text injection completed successfully")
+		logger.Info("Text injection completed")
+		injected = true
+		if reporter, ok := injector.(injection.Reporter); ok {
+			report = reporter.LastInjection()
+			entry.Backend = report.Backend
+		}
 	}
+	p.sendInjected(report)
 
 	p.setStatus(Idle)
 }
 
+// waitTurn waits until every earlier dictation has injected, for at most
+// queueTimeout
+func (p *pipeline) waitTurn(session context.Context) error {
+	ctx, cancel := context.WithTimeout(session, queueTimeout)
+	defer cancel()
+	return p.turn.Wait(ctx)
+}
+
+// saveRecovery appends text to the recovery file so a dictation that ended
+// without being injected is not lost
+func (p *pipeline) saveRecovery(entry *history.Entry, text, reason string) {
+	if strings.TrimSpace(text) == "" {
+		logger.Info("Nothing to save, transcription is empty", "reason", reason)
+		return
+	}
+
+	path, err := history.RecoveryPath()
+	if err == nil {
+		err = history.SaveRecovery(path, entry.Time, reason, text)
+	}
+	if err != nil {
+		logger.Error("Failed to save recovery file", "err", err)
+		p.sendError("Recovery Error", "Failed to save dictation to recovery file", err)
+		addHistoryError(entry, "Failed to save dictation to recovery file", err)
+		return
+	}
+
+	logger.Info("Saved dictation to recovery file", "path", path, "reason", reason)
+	p.sendNotify(notify.MsgDictationSaved)
+}
+
+func (p *pipeline) newHistoryEntry() history.Entry {
+	return history.Entry{
+		Time:     time.Now(),
+		Provider: p.config.Transcription.Provider,
+		Model:    p.config.Transcription.Model,
+		Language: p.config.Transcription.Language,
+	}
+}
+
+// saveHistory stores the entry so text survives failed or misdirected injections
+func (p *pipeline) saveHistory(entry *history.Entry) {
+	if !p.config.History.Enabled {
+		return
+	}
+
+	store, err := p.historyFactory(p.config.ToHistoryConfig())
+	if err != nil {
+		logger.Warn("Failed to open history", "err", err)
+		return
+	}
+	if err := store.Append(*entry); err != nil {
+		logger.Warn("Failed to save history entry", "err", err)
+	}
+}
+
+// saveStats records stage timings and word counts without the dictated text
+func (p *pipeline) saveStats(entry history.Entry, injected bool) {
+	if !p.config.Stats.Enabled {
+		return
+	}
+
+	store, err := p.statsFactory(p.config.ToStatsConfig())
+	if err != nil {
+		logger.Warn("Failed to open stats", "err", err)
+		return
+	}
+
+	text := entry.Text()
+	record := stats.Record{
+		Time:            entry.Time,
+		Provider:        entry.Provider,
+		Model:           entry.Model,
+		LLMProvider:     entry.LLMProvider,
+		LLMModel:        entry.LLMModel,
+		Language:        entry.Language,
+		RecorderStartMs: entry.Durations.RecorderStartMs,
+		AudioMs:         entry.Durations.AudioMs,
+		TranscriptionMs: entry.Durations.TranscriptionMs,
+		LLMMs:           entry.Durations.LLMMs,
+		InjectionMs:     entry.Durations.InjectionMs,
+		Words:           len(strings.Fields(text)),
+		Chars:           utf8.RuneCountInString(text),
+		Failed:          !injected,
+	}
+	if err := store.Append(record); err != nil {
+		logger.Warn("Failed to save stats", "err", err)
+	}
+}
+
+func addHistoryError(entry *history.Entry, message string, err error) {
+	entry.Errors = append(entry.Errors, fmt.Sprintf("%s: %v", message, err))
+}
+
 func (p *pipeline) Stop() {
 	p.stopOnce.Do(func() {
+		if p.running.Load() {
+			p.mu.Lock()
+			p.result.Cancelled = true
+			p.mu.Unlock()
+		}
 		cancel := p.getCancel()
 		if cancel != nil {
 			cancel()
@@ -351,3 +803,7 @@ func (p *pipeline) Stop() {
 	})
 	p.wg.Wait()
 }
+
+func (p *pipeline) Wait() {
+	p.wg.Wait()
+}
@@ -2,10 +2,19 @@ package pipeline
 
 import (
 	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/leonardotrapani/hyprvoice/internal/config"
+	"github.com/leonardotrapani/hyprvoice/internal/history"
+	"github.com/leonardotrapani/hyprvoice/internal/llm"
+	"github.com/leonardotrapani/hyprvoice/internal/notify"
+	"github.com/leonardotrapani/hyprvoice/internal/recording"
 	"github.com/leonardotrapani/hyprvoice/internal/testutil"
 )
 
@@ -528,3 +537,541 @@ func TestPipeline_WithMocks_LLMProcessing(t *testing.T) {
 
 	p.Stop()
 }
+
+func TestPipeline_StatusEvents(t *testing.T) {
+	cfg := testutil.TestConfig()
+	cfg.Injection.Backends = []string{"clipboard"}
+
+	mockInjector := testutil.NewMockInjector()
+
+	p := New(cfg,
+		WithRecorderFactory(testutil.MockRecorderFactory(testutil.NewMockRecorder())),
+		WithTranscriberFactory(testutil.MockTranscriberFactory(testutil.NewMockTranscriber("hello world"))),
+		WithInjectorFactory(testutil.MockInjectorFactory(mockInjector)),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	p.Run(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	p.GetActionCh() <- Inject
+	time.Sleep(100 * time.Millisecond)
+	p.Stop()
+
+	var got []Status
+	statusCh := p.GetStatusCh()
+	for len(statusCh) > 0 {
+		got = append(got, <-statusCh)
+	}
+
+	want := []Status{Recording, Transcribing, Injecting, Idle}
+	if len(got) != len(want) {
+		t.Fatalf("status transitions = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("transition %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestPipeline_SavesHistory(t *testing.T) {
+	cfg := testutil.TestConfig()
+	cfg.Transcription.Language = "en"
+	cfg.History.Enabled = true
+	cfg.LLM = config.LLMConfig{Enabled: true, Provider: "openai", Model: "gpt-4o-mini"}
+
+	mockInjector := testutil.NewMockInjector()
+	mockInjector.InjectError = errors.New("no focused window")
+	mockHistory := testutil.NewMockHistoryStore()
+
+	p := New(cfg,
+		WithRecorderFactory(testutil.MockRecorderFactory(testutil.NewMockRecorder())),
+		WithTranscriberFactory(testutil.MockTranscriberFactory(testutil.NewMockTranscriber("um hello"))),
+		WithInjectorFactory(testutil.MockInjectorFactory(mockInjector)),
+		WithLLMAdapterFactory(testutil.MockLLMAdapterFactory(testutil.NewMockLLMAdapter("Hello."))),
+		WithHistoryFactory(testutil.MockHistoryFactory(mockHistory)),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	p.Run(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	p.GetActionCh() <- Inject
+	time.Sleep(100 * time.Millisecond)
+	p.Stop()
+
+	entries, _ := mockHistory.List()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 history entry, got %d", len(entries))
+	}
+
+	e := entries[0]
+	if e.Transcript != "um hello" || e.LLMOutput != "Hello." {
+		t.Errorf("transcript/llm output = %q/%q", e.Transcript, e.LLMOutput)
+	}
+	if e.Provider != "openai" || e.Model != "whisper-1" || e.Language != "en" {
+		t.Errorf("provider/model/language = %q/%q/%q", e.Provider, e.Model, e.Language)
+	}
+	if e.LLMProvider != "openai" || e.LLMModel != "gpt-4o-mini" {
+		t.Errorf("llm provider/model = %q/%q", e.LLMProvider, e.LLMModel)
+	}
+	// failed injections are still saved so the text can be recovered
+	if len(e.Errors) != 1 || !strings.Contains(e.Errors[0], "no focused window") {
+		t.Errorf("errors = %v", e.Errors)
+	}
+}
+
+func TestPipeline_RecordsStats(t *testing.T) {
+	cfg := testutil.TestConfig()
+	cfg.Stats.Enabled = true
+	cfg.LLM = config.LLMConfig{Enabled: true, Provider: "openai", Model: "gpt-4o-mini"}
+
+	mockStats := testutil.NewMockStatsStore()
+
+	p := New(cfg,
+		WithRecorderFactory(testutil.MockRecorderFactory(testutil.NewMockRecorder())),
+		WithTranscriberFactory(testutil.MockTranscriberFactory(testutil.NewMockTranscriber("um hello there"))),
+		WithInjectorFactory(testutil.MockInjectorFactory(testutil.NewMockInjector())),
+		WithLLMAdapterFactory(testutil.MockLLMAdapterFactory(testutil.NewMockLLMAdapter("Hello there."))),
+		WithStatsFactory(testutil.MockStatsFactory(mockStats)),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	p.Run(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	p.GetActionCh() <- Inject
+	time.Sleep(100 * time.Millisecond)
+	p.Stop()
+
+	records, _ := mockStats.List()
+	if len(records) != 1 {
+		t.Fatalf("expected 1 stats record, got %d", len(records))
+	}
+
+	r := records[0]
+	if r.Provider != "openai" || r.Model != "whisper-1" || r.LLMProvider != "openai" || r.LLMModel != "gpt-4o-mini" {
+		t.Errorf("provider/model = %+v", r)
+	}
+	// counts describe the injected text
+	if r.Words != 2 || r.Chars != 12 || r.Failed {
+		t.Errorf("words/chars/failed = %d/%d/%v", r.Words, r.Chars, r.Failed)
+	}
+	if r.AudioMs < 40 {
+		t.Errorf("audio duration = %dms, want at least the 50ms recording", r.AudioMs)
+	}
+}
+
+func TestPipeline_HistoryDisabled(t *testing.T) {
+	cfg := testutil.TestConfig()
+	mockHistory := testutil.NewMockHistoryStore()
+
+	p := New(cfg,
+		WithRecorderFactory(testutil.MockRecorderFactory(testutil.NewMockRecorder())),
+		WithTranscriberFactory(testutil.MockTranscriberFactory(testutil.NewMockTranscriber("hello"))),
+		WithInjectorFactory(testutil.MockInjectorFactory(testutil.NewMockInjector())),
+		WithHistoryFactory(testutil.MockHistoryFactory(mockHistory)),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	p.Run(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	p.GetActionCh() <- Inject
+	time.Sleep(100 * time.Millisecond)
+	p.Stop()
+
+	if entries, _ := mockHistory.List(); len(entries) != 0 {
+		t.Errorf("expected no history entries when disabled, got %d", len(entries))
+	}
+}
+
+func TestPipeline_ReportsInjection(t *testing.T) {
+	cfg := testutil.TestConfig()
+
+	p := New(cfg,
+		WithRecorderFactory(testutil.MockRecorderFactory(testutil.NewMockRecorder())),
+		WithTranscriberFactory(testutil.MockTranscriberFactory(testutil.NewMockTranscriber("hello world"))),
+		WithInjectorFactory(testutil.MockInjectorFactory(testutil.NewMockInjector())),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	p.Run(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	p.GetActionCh() <- Inject
+
+	select {
+	case injected := <-p.GetInjectedCh():
+		if injected.Text != "hello world" {
+			t.Errorf("injected text = %q, want %q", injected.Text, "hello world")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no injection reported")
+	}
+
+	p.Stop()
+}
+
+func TestPipeline_Result(t *testing.T) {
+	t.Run("llm_error", func(t *testing.T) {
+		cfg := testutil.TestConfig()
+		cfg.LLM.Enabled = true
+		cfg.LLM.Provider = "openai"
+		cfg.LLM.Model = "gpt-4o-mini"
+
+		mockLLM := testutil.NewMockLLMAdapter("")
+		mockLLM.ProcessError = errors.New("rate limited")
+
+		p := New(cfg,
+			WithRecorderFactory(testutil.MockRecorderFactory(testutil.NewMockRecorder())),
+			WithTranscriberFactory(testutil.MockTranscriberFactory(testutil.NewMockTranscriber("hello world"))),
+			WithInjectorFactory(testutil.MockInjectorFactory(testutil.NewMockInjector())),
+			WithLLMAdapterFactory(testutil.MockLLMAdapterFactory(mockLLM)),
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		p.Run(ctx)
+		time.Sleep(50 * time.Millisecond)
+		p.GetActionCh() <- Inject
+		p.Wait()
+
+		r := p.Result()
+		if r.Transcript != "hello world" || r.Text != "hello world" || r.Processed != "" || !r.Injected || r.Cancelled {
+			t.Errorf("result = %+v", r)
+		}
+		if len(r.Errors) != 1 || !strings.Contains(r.Errors[0], "rate limited") {
+			t.Errorf("errors = %v, want the LLM failure", r.Errors)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		p := New(testutil.TestConfig(),
+			WithRecorderFactory(testutil.MockRecorderFactory(testutil.NewMockRecorder())),
+			WithTranscriberFactory(testutil.MockTranscriberFactory(testutil.NewMockTranscriber("hello world"))),
+			WithInjectorFactory(testutil.MockInjectorFactory(testutil.NewMockInjector())),
+		)
+
+		p.Run(context.Background())
+		time.Sleep(50 * time.Millisecond)
+		p.Stop()
+
+		if r := p.Result(); !r.Cancelled || r.Injected || r.Text != "" {
+			t.Errorf("result = %+v", r)
+		}
+	})
+}
+
+func TestPipeline_TimeoutPolicy(t *testing.T) {
+	cfg := testutil.TestConfig()
+	cfg.Recording.Timeout = 100 * time.Millisecond
+	cfg.Recording.TimeoutWarning = 50 * time.Millisecond
+	cfg.Recording.OnTimeout = config.PolicyInject
+
+	mockInjector := testutil.NewMockInjector()
+
+	p := New(cfg,
+		WithRecorderFactory(testutil.MockRecorderFactory(testutil.NewMockRecorder())),
+		WithTranscriberFactory(testutil.MockTranscriberFactory(testutil.NewMockTranscriber("long dictation"))),
+		WithInjectorFactory(testutil.MockInjectorFactory(mockInjector)),
+	)
+
+	p.Run(context.Background())
+	p.Wait()
+
+	injected := mockInjector.GetInjectedTexts()
+	if len(injected) != 1 || injected[0] != "long dictation" {
+		t.Errorf("injected = %v, want the dictation injected at the timeout", injected)
+	}
+
+	notifyCh := p.GetNotifyCh()
+	if len(notifyCh) == 0 || <-notifyCh != notify.MsgTimeoutWarning {
+		t.Errorf("expected a timeout warning notification")
+	}
+}
+
+func TestPipeline_VADStopsRecording(t *testing.T) {
+	cfg := testutil.TestConfig()
+	cfg.Recording.VAD = config.VADConfig{
+		Enabled:   true,
+		Threshold: recording.DefaultSilenceLevel,
+		Silence:   200 * time.Millisecond,
+		MinSpeech: 100 * time.Millisecond,
+	}
+
+	// 16 kHz mono s16le: 300ms of speech, then 300ms of silence
+	speech := make([]byte, 9600)
+	for i := 0; i < len(speech); i += 2 {
+		binary.LittleEndian.PutUint16(speech[i:], 5000)
+	}
+	recorder := testutil.NewMockRecorder()
+	recorder.Frames = []recording.AudioFrame{
+		testutil.MockAudioFrame(speech),
+		testutil.MockAudioFrame(make([]byte, 9600)),
+	}
+	mockInjector := testutil.NewMockInjector()
+
+	p := New(cfg,
+		WithRecorderFactory(testutil.MockRecorderFactory(recorder)),
+		WithTranscriberFactory(testutil.MockTranscriberFactory(testutil.NewMockTranscriber("hands free"))),
+		WithInjectorFactory(testutil.MockInjectorFactory(mockInjector)),
+	)
+
+	p.Run(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		p.Stop()
+		t.Fatalf("recording did not stop on silence")
+	}
+
+	injected := mockInjector.GetInjectedTexts()
+	if len(injected) != 1 || injected[0] != "hands free" {
+		t.Errorf("injected = %v, want the dictation injected after the silence", injected)
+	}
+}
+
+func TestPipeline_CaptureEnds(t *testing.T) {
+	recorder := testutil.NewMockRecorder()
+	recorder.EndAfterFrames = true
+	mockInjector := testutil.NewMockInjector()
+
+	p := New(testutil.TestConfig(),
+		WithRecorderFactory(testutil.MockRecorderFactory(recorder)),
+		WithTranscriberFactory(testutil.MockTranscriberFactory(testutil.NewMockTranscriber("from a file"))),
+		WithInjectorFactory(testutil.MockInjectorFactory(mockInjector)),
+	)
+
+	p.Run(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		p.Stop()
+		t.Fatalf("recording did not stop when the capture ended")
+	}
+
+	injected := mockInjector.GetInjectedTexts()
+	if len(injected) != 1 || injected[0] != "from a file" {
+		t.Errorf("injected = %v, want the dictation injected at the end of the capture", injected)
+	}
+}
+
+func TestPipeline_SilentMicrophone(t *testing.T) {
+	cfg := testutil.TestConfig()
+
+	// 16 kHz mono s16le: 4s of digital silence, as from a muted microphone
+	recorder := testutil.NewMockRecorder()
+	recorder.Frames = nil
+	for i := 0; i < 4; i++ {
+		recorder.Frames = append(recorder.Frames, testutil.MockAudioFrame(make([]byte, 32000)))
+	}
+
+	p := New(cfg,
+		WithRecorderFactory(testutil.MockRecorderFactory(recorder)),
+		WithTranscriberFactory(testutil.MockTranscriberFactory(testutil.NewMockTranscriber(""))),
+		WithInjectorFactory(testutil.MockInjectorFactory(testutil.NewMockInjector())),
+	)
+
+	p.Run(context.Background())
+	defer p.Stop()
+
+	select {
+	case mt := <-p.GetNotifyCh():
+		if mt != notify.MsgMicSilent {
+			t.Fatalf("notification = %v, want mic_silent", mt)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("no notification for a silent microphone")
+	}
+
+	select {
+	case levels := <-p.GetLevelsCh():
+		if levels.RMS != 0 || levels.Peak != 0 {
+			t.Errorf("levels = %+v, want silence", levels)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("no levels published while recording")
+	}
+
+	// a silent microphone is only reported, the dictation itself did not fail
+	p.GetActionCh() <- Inject
+	p.Wait()
+	for _, err := range p.Result().Errors {
+		if strings.Contains(err, "silence") {
+			t.Errorf("result errors = %v, want the silent microphone only notified", p.Result().Errors)
+		}
+	}
+}
+
+func TestPipeline_TimeoutDiscard(t *testing.T) {
+	cfg := testutil.TestConfig()
+	cfg.Recording.Timeout = 50 * time.Millisecond
+	cfg.Recording.OnTimeout = config.PolicyDiscard
+
+	mockInjector := testutil.NewMockInjector()
+
+	p := New(cfg,
+		WithRecorderFactory(testutil.MockRecorderFactory(testutil.NewMockRecorder())),
+		WithTranscriberFactory(testutil.MockTranscriberFactory(testutil.NewMockTranscriber("dropped"))),
+		WithInjectorFactory(testutil.MockInjectorFactory(mockInjector)),
+	)
+
+	p.Run(context.Background())
+	p.Wait()
+
+	if injected := mockInjector.GetInjectedTexts(); len(injected) != 0 {
+		t.Errorf("injected = %v, want nothing", injected)
+	}
+	notifyCh := p.GetNotifyCh()
+	if len(notifyCh) == 0 || <-notifyCh != notify.MsgRecordingAborted {
+		t.Errorf("expected a recording aborted notification")
+	}
+}
+
+func TestPipeline_ShutdownSavesRecovery(t *testing.T) {
+	dataDir := t.TempDir()
+	originalDataDir := os.Getenv("XDG_DATA_HOME")
+	os.Setenv("XDG_DATA_HOME", dataDir)
+	defer func() {
+		if originalDataDir == "" {
+			os.Unsetenv("XDG_DATA_HOME")
+		} else {
+			os.Setenv("XDG_DATA_HOME", originalDataDir)
+		}
+	}()
+
+	cfg := testutil.TestConfig()
+	cfg.Recording.OnShutdown = config.PolicySave
+	cfg.Stats.Enabled = true
+
+	mockInjector := testutil.NewMockInjector()
+	mockStats := testutil.NewMockStatsStore()
+
+	p := New(cfg,
+		WithRecorderFactory(testutil.MockRecorderFactory(testutil.NewMockRecorder())),
+		WithTranscriberFactory(testutil.MockTranscriberFactory(testutil.NewMockTranscriber("unsaved thought"))),
+		WithInjectorFactory(testutil.MockInjectorFactory(mockInjector)),
+		WithStatsFactory(testutil.MockStatsFactory(mockStats)),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.Run(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	// the daemon cancels its context on SIGTERM and waits for the pipeline
+	cancel()
+	p.Wait()
+
+	if injected := mockInjector.GetInjectedTexts(); len(injected) != 0 {
+		t.Errorf("injected = %v, want nothing with the save policy", injected)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dataDir, "hyprvoice", history.RecoveryFileName))
+	if err != nil {
+		t.Fatalf("failed to read recovery file: %v", err)
+	}
+	if !strings.Contains(string(data), "unsaved thought") || !strings.Contains(string(data), "daemon shutdown") {
+		t.Errorf("recovery file = %q", data)
+	}
+
+	if records, _ := mockStats.List(); len(records) != 1 || !records[0].Failed {
+		t.Errorf("stats = %+v, want one record marked as not injected", records)
+	}
+}
+
+// blockingLLMAdapter holds Process until release is closed
+type blockingLLMAdapter struct {
+	release chan struct{}
+}
+
+func (a *blockingLLMAdapter) Process(ctx context.Context, text string) (string, error) {
+	select {
+	case <-a.release:
+		return text, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func TestPipeline_OverlappingSessionsInjectInOrder(t *testing.T) {
+	var queue Queue
+	mockInjector := testutil.NewMockInjector()
+	slowLLM := &blockingLLMAdapter{release: make(chan struct{})}
+
+	newSession := func(text string, llmEnabled bool) Pipeline {
+		cfg := testutil.TestConfig()
+		cfg.LLM = config.LLMConfig{Enabled: llmEnabled, Provider: "openai", Model: "gpt-4o-mini"}
+		return New(cfg,
+			WithRecorderFactory(testutil.MockRecorderFactory(testutil.NewMockRecorder())),
+			WithTranscriberFactory(testutil.MockTranscriberFactory(testutil.NewMockTranscriber(text))),
+			WithInjectorFactory(testutil.MockInjectorFactory(mockInjector)),
+			WithLLMAdapterFactory(func(cfg llm.Config) (llm.Adapter, error) { return slowLLM, nil }),
+			WithTurn(queue.Next()),
+		)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// the first dictation is stuck in LLM processing
+	first := newSession("first", true)
+	first.Run(ctx)
+	time.Sleep(50 * time.Millisecond)
+	first.GetActionCh() <- Inject
+	testutil.WaitForCondition(t, func() bool { return first.Status() == Processing }, time.Second)
+
+	// a second one starts, is cancelled while waiting for its turn, and a
+	// third one finishes without LLM but must wait for the first
+	second := newSession("second", false)
+	second.Run(ctx)
+	third := newSession("third", false)
+	third.Run(ctx)
+	if third.Status() != Recording {
+		t.Errorf("status right after Run = %s, want %s", third.Status(), Recording)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	second.GetActionCh() <- Inject
+	third.GetActionCh() <- Inject
+	time.Sleep(100 * time.Millisecond)
+	second.Stop()
+
+	if injected := mockInjector.GetInjectedTexts(); len(injected) != 0 {
+		t.Fatalf("injected %v before the first dictation finished", injected)
+	}
+	if status := third.Status(); status != Queued {
+		t.Errorf("third status = %s, want %s while waiting", status, Queued)
+	}
+
+	close(slowLLM.release)
+	first.Wait()
+	third.Wait()
+
+	injected := mockInjector.GetInjectedTexts()
+	if len(injected) != 2 || injected[0] != "first" || injected[1] != "third" {
+		t.Errorf("injected = %v, want [first third]", injected)
+	}
+}
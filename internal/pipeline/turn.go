@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+)
+
+// Queue hands out injection turns in the order sessions start, so
+// overlapping dictations type their text in the order they were spoken.
+// The zero value is ready to use.
+type Queue struct {
+	mu   sync.Mutex
+	last *Turn
+}
+
+// Turn is a session's place in a Queue. It is ended when the session ends,
+// however it ends, which lets the sessions behind it inject.
+type Turn struct {
+	prev <-chan struct{} // closed once every earlier turn has ended
+	done chan struct{}   // closed once this and every earlier turn have ended
+	once sync.Once
+}
+
+// Next returns a turn behind every turn handed out before
+func (q *Queue) Next() *Turn {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	t := &Turn{done: make(chan struct{})}
+	if q.last != nil {
+		t.prev = q.last.done
+	}
+	q.last = t
+	return t
+}
+
+// Wait blocks until every earlier turn has ended or ctx is done
+func (t *Turn) Wait(ctx context.Context) error {
+	if t.prev == nil {
+		return nil
+	}
+	select {
+	case <-t.prev:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Ready reports whether every earlier turn has ended
+func (t *Turn) Ready() bool {
+	if t.prev == nil {
+		return true
+	}
+	select {
+	case <-t.prev:
+		return true
+	default:
+		return false
+	}
+}
+
+// End releases the turn; calling it again has no effect. A session that
+// ends early still holds back later ones until the earlier turns end.
+func (t *Turn) End() {
+	t.once.Do(func() {
+		if t.prev == nil {
+			close(t.done)
+			return
+		}
+		go func() {
+			<-t.prev
+			close(t.done)
+		}()
+	})
+}
@@ -0,0 +1,48 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func waitsFor(t *Turn) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	return t.Wait(ctx) != nil
+}
+
+func TestQueue(t *testing.T) {
+	var q Queue
+	first, second, third := q.Next(), q.Next(), q.Next()
+
+	if waitsFor(first) || !first.Ready() {
+		t.Errorf("first turn should not wait")
+	}
+	if second.Ready() {
+		t.Errorf("second turn is ready before the first ended")
+	}
+	if !waitsFor(second) || !waitsFor(third) {
+		t.Fatalf("later turns should wait for the first")
+	}
+
+	// a turn ending early does not let later turns skip earlier ones
+	second.End()
+	if !waitsFor(third) {
+		t.Errorf("third turn should still wait for the first")
+	}
+
+	first.End()
+	first.End()
+	if waitsFor(second) || !second.Ready() {
+		t.Errorf("second turn should not wait once the first ended")
+	}
+	if err := third.Wait(context.Background()); err != nil {
+		t.Errorf("third Wait() error = %v", err)
+	}
+
+	third.End()
+	if waitsFor(q.Next()) {
+		t.Errorf("a new turn should not wait once all earlier turns ended")
+	}
+}
@@ -52,6 +52,19 @@ func BaseProviderName(configProvider string) string {
 	}
 }
 
+// ConfigProviderName maps registry provider names to the names used for
+// transcription.provider, the inverse of BaseProviderName
+func ConfigProviderName(registryName string) string {
+	switch registryName {
+	case ProviderGroq:
+		return ConfigProviderGroqTranscription
+	case ProviderMistral:
+		return ConfigProviderMistralTranscription
+	default:
+		return registryName
+	}
+}
+
 // EnvVarForProvider returns the environment variable name for a provider's API key
 func EnvVarForProvider(provider string) string {
 	base := BaseProviderName(provider)
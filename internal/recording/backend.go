@@ -0,0 +1,398 @@
+package recording
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/audiofile"
+)
+
+// Capture backends for recording.backend
+const (
+	BackendPipeWire = "pw-record" // PipeWire
+	BackendPulse    = "parec"     // PulseAudio, or PipeWire through pipewire-pulse
+	BackendALSA     = "arecord"   // bare ALSA
+	BackendFFmpeg   = "ffmpeg"    // ffmpeg -f pulse
+	BackendFile     = "file"      // a WAV file, or raw PCM on stdin
+
+	DefaultBackend = BackendPipeWire
+)
+
+// Backends lists the names accepted by recording.backend
+var Backends = []string{BackendPipeWire, BackendPulse, BackendALSA, BackendFFmpeg, BackendFile}
+
+// Stdin is the recording.file value that reads raw PCM from standard input
+const Stdin = "-"
+
+// Backend captures raw audio in the format of the recording config
+type Backend interface {
+	Name() string
+	// Available reports why the backend cannot capture, or nil
+	Available(ctx context.Context) error
+	// Open starts capturing. The stream ends when ctx does; Close releases it.
+	Open(ctx context.Context) (io.ReadCloser, error)
+}
+
+// NewBackend returns the capture backend selected by cfg.Backend, or nil
+// when the name is unknown
+func NewBackend(cfg Config) Backend {
+	switch cfg.Backend {
+	case BackendPipeWire, "":
+		return &commandBackend{
+			name:   BackendPipeWire,
+			args:   pwRecordArgs,
+			check:  CheckPipeWireAvailable,
+			config: cfg,
+		}
+	case BackendPulse:
+		return &commandBackend{name: BackendPulse, args: parecArgs, install: "pulseaudio-utils", config: cfg}
+	case BackendALSA:
+		return &commandBackend{name: BackendALSA, args: arecordArgs, install: "alsa-utils", config: cfg}
+	case BackendFFmpeg:
+		return &commandBackend{name: BackendFFmpeg, args: ffmpegArgs, install: "ffmpeg", config: cfg}
+	case BackendFile:
+		return &fileBackend{path: cfg.File, config: cfg}
+	default:
+		return nil
+	}
+}
+
+// commandBackend captures with a tool that writes raw audio to stdout
+type commandBackend struct {
+	name    string // backend and executable name
+	args    func(cfg Config) ([]string, error)
+	install string                          // package to install when the tool is missing
+	check   func(ctx context.Context) error // replaces the PATH lookup when set
+	config  Config
+}
+
+func (b *commandBackend) Name() string { return b.name }
+
+func (b *commandBackend) Available(ctx context.Context) error {
+	if b.check != nil {
+		return b.check(ctx)
+	}
+	if _, err := exec.LookPath(b.name); err != nil {
+		return fmt.Errorf("%s not found: %w (install %s)", b.name, err, b.install)
+	}
+	return nil
+}
+
+func (b *commandBackend) Open(ctx context.Context) (io.ReadCloser, error) {
+	args, err := b.args(b.config)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, b.name, args...)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("create stdout pipe: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("create stderr pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start %s: %w", b.name, err)
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			logger.Debug(b.name+" stderr", "line", scanner.Text())
+		}
+	}()
+
+	return &commandStream{ReadCloser: stdout, cmd: cmd}, nil
+}
+
+// commandStream is the stdout of a capture command; Close waits for the
+// command, which exits once the recording context is cancelled
+type commandStream struct {
+	io.ReadCloser
+	cmd *exec.Cmd
+}
+
+func (s *commandStream) Close() error {
+	return s.cmd.Wait()
+}
+
+func pwRecordArgs(cfg Config) ([]string, error) {
+	args := []string{
+		"--format", cfg.Format,
+		"--rate", strconv.Itoa(cfg.SampleRate),
+		"--channels", strconv.Itoa(cfg.Channels),
+		"-", // stdout
+	}
+	if cfg.Device != "" {
+		args = append(args, "--target", cfg.Device)
+	}
+	return args, nil
+}
+
+func parecArgs(cfg Config) ([]string, error) {
+	format, ok := map[string]string{
+		"u8":  "u8",
+		"s16": "s16le",
+		"s32": "s32le",
+		"f32": "float32le",
+	}[cfg.Format]
+	if !ok {
+		return nil, unsupportedFormat(BackendPulse, cfg.Format)
+	}
+	args := []string{
+		"--raw",
+		"--format=" + format,
+		"--rate=" + strconv.Itoa(cfg.SampleRate),
+		"--channels=" + strconv.Itoa(cfg.Channels),
+	}
+	if cfg.Device != "" {
+		args = append(args, "--device="+cfg.Device)
+	}
+	return args, nil
+}
+
+func arecordArgs(cfg Config) ([]string, error) {
+	format, ok := map[string]string{
+		"u8":  "U8",
+		"s8":  "S8",
+		"s16": "S16_LE",
+		"s32": "S32_LE",
+		"f32": "FLOAT_LE",
+		"f64": "FLOAT64_LE",
+	}[cfg.Format]
+	if !ok {
+		return nil, unsupportedFormat(BackendALSA, cfg.Format)
+	}
+	args := []string{
+		"-q",
+		"-t", "raw",
+		"-f", format,
+		"-r", strconv.Itoa(cfg.SampleRate),
+		"-c", strconv.Itoa(cfg.Channels),
+	}
+	if cfg.Device != "" {
+		args = append(args, "-D", cfg.Device)
+	}
+	return args, nil
+}
+
+func ffmpegArgs(cfg Config) ([]string, error) {
+	format, ok := map[string]string{
+		"u8":  "u8",
+		"s8":  "s8",
+		"s16": "s16le",
+		"s32": "s32le",
+		"f32": "f32le",
+		"f64": "f64le",
+	}[cfg.Format]
+	if !ok {
+		return nil, unsupportedFormat(BackendFFmpeg, cfg.Format)
+	}
+	device := cfg.Device
+	if device == "" {
+		device = "default"
+	}
+	rate, channels := strconv.Itoa(cfg.SampleRate), strconv.Itoa(cfg.Channels)
+	return []string{
+		"-nostdin", "-hide_banner", "-loglevel", "error",
+		"-f", "pulse", "-sample_rate", rate, "-channels", channels, "-i", device,
+		"-f", format, "-acodec", "pcm_" + format, "-ar", rate, "-ac", channels,
+		"pipe:1",
+	}, nil
+}
+
+func unsupportedFormat(backend, format string) error {
+	return fmt.Errorf("format %q is not supported by the %s backend", format, backend)
+}
+
+// fileBackend plays a WAV file in real time, or streams raw PCM in the
+// recording format from stdin, so recordings are reproducible
+type fileBackend struct {
+	path   string
+	config Config
+}
+
+func (b *fileBackend) Name() string { return BackendFile }
+
+func (b *fileBackend) Available(ctx context.Context) error {
+	switch {
+	case b.path == "":
+		return fmt.Errorf("recording.file is not set")
+	case b.path == Stdin:
+		return nil
+	}
+	if _, err := os.Stat(b.path); err != nil {
+		return err
+	}
+	if b.config.SampleRate != audiofile.SampleRate || b.config.Channels != 1 || b.config.Format != "s16" {
+		return fmt.Errorf("audio files are read as %d Hz mono s16, set recording.sample_rate, channels and format to match", audiofile.SampleRate)
+	}
+	return nil
+}
+
+func (b *fileBackend) Open(ctx context.Context) (io.ReadCloser, error) {
+	// both sources play in real time like a microphone, so a fast one
+	// does not flood the recorder and lose frames
+	if b.path == Stdin {
+		return stdin.stream(ctx, newPacer(b.config)), nil
+	}
+	pcm, err := audiofile.Decode(ctx, b.path)
+	if err != nil {
+		return nil, err
+	}
+	return &pacedReader{ctx: ctx, pcm: pcm, pace: newPacer(b.config)}, nil
+}
+
+// pacer releases audio no faster than it would be recorded
+type pacer struct {
+	bytesPerSecond int
+	start          time.Time
+	released       int
+}
+
+func newPacer(cfg Config) *pacer {
+	return &pacer{
+		bytesPerSecond: cfg.SampleRate * cfg.Channels * sampleBytes(cfg.Format),
+		start:          time.Now(),
+	}
+}
+
+// wait blocks until n more bytes would have been recorded, or ctx ends
+func (p *pacer) wait(ctx context.Context, n int) error {
+	due := p.start.Add(time.Duration(p.released+n) * time.Second / time.Duration(p.bytesPerSecond))
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Until(due)):
+	}
+	p.released += n
+	return nil
+}
+
+// sampleBytes returns the size of one sample in format
+func sampleBytes(format string) int {
+	switch format {
+	case "u8", "s8":
+		return 1
+	case "s32", "f32":
+		return 4
+	case "f64":
+		return 8
+	default:
+		return 2
+	}
+}
+
+// pacedReader returns pcm no faster than it would be recorded
+type pacedReader struct {
+	ctx  context.Context
+	pcm  []byte
+	read int
+	pace *pacer
+}
+
+func (r *pacedReader) Read(p []byte) (int, error) {
+	if r.read >= len(r.pcm) {
+		return 0, io.EOF
+	}
+	n := min(len(p), len(r.pcm)-r.read)
+	n -= n % 2
+	n = max(n, 1)
+
+	if err := r.pace.wait(r.ctx, n); err != nil {
+		return 0, err
+	}
+	copy(p, r.pcm[r.read:r.read+n])
+	r.read += n
+	return n, nil
+}
+
+func (r *pacedReader) Close() error { return nil }
+
+// stdin is shared by every recording that reads standard input
+var stdin = &sharedReader{r: os.Stdin}
+
+// sharedReader reads r in a single goroutine and hands the data to one
+// stream at a time. A read blocked on stdin cannot be interrupted, so the
+// goroutine outlives a recording and serves the next one.
+type sharedReader struct {
+	r    io.Reader
+	once sync.Once
+	ch   chan []byte
+	err  error // set before ch is closed
+
+	// mu serializes streams; buf is the part of a chunk not read yet,
+	// kept here so a recording that ends does not take it along
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (s *sharedReader) start() {
+	s.ch = make(chan []byte)
+	go func() {
+		defer close(s.ch)
+		for {
+			buf := make([]byte, 32*1024)
+			n, err := s.r.Read(buf)
+			if n > 0 {
+				s.ch <- buf[:n]
+			}
+			if err != nil {
+				s.err = err
+				return
+			}
+		}
+	}()
+}
+
+// stream returns a reader of the data read from r, released by pace, until
+// ctx ends
+func (s *sharedReader) stream(ctx context.Context, pace *pacer) io.ReadCloser {
+	s.once.Do(s.start)
+	return &sharedStream{ctx: ctx, shared: s, pace: pace}
+}
+
+type sharedStream struct {
+	ctx    context.Context
+	shared *sharedReader
+	pace   *pacer
+}
+
+func (s *sharedStream) Read(p []byte) (int, error) {
+	shared := s.shared
+	shared.mu.Lock()
+	defer shared.mu.Unlock()
+
+	if err := s.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if len(shared.buf) == 0 {
+		select {
+		case chunk, ok := <-shared.ch:
+			if !ok {
+				return 0, shared.err
+			}
+			shared.buf = chunk
+		case <-s.ctx.Done():
+			return 0, s.ctx.Err()
+		}
+	}
+	n := min(len(p), len(shared.buf))
+	if err := s.pace.wait(s.ctx, n); err != nil {
+		return 0, err
+	}
+	copy(p, shared.buf[:n])
+	shared.buf = shared.buf[n:]
+	return n, nil
+}
+
+func (s *sharedStream) Close() error { return nil }
@@ -0,0 +1,221 @@
+package recording
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewBackend(t *testing.T) {
+	for _, name := range Backends {
+		backend := NewBackend(Config{Backend: name})
+		if backend == nil || backend.Name() != name {
+			t.Errorf("NewBackend(%q) = %v", name, backend)
+		}
+	}
+	if backend := NewBackend(Config{}); backend == nil || backend.Name() != DefaultBackend {
+		t.Errorf("NewBackend() without a backend = %v, want %s", backend, DefaultBackend)
+	}
+	if backend := NewBackend(Config{Backend: "oss"}); backend != nil {
+		t.Errorf("NewBackend(oss) = %v, want nil", backend)
+	}
+}
+
+func TestBackendArgs(t *testing.T) {
+	cfg := Config{SampleRate: 16000, Channels: 1, Format: "s16"}
+	withDevice := cfg
+	withDevice.Device = "mic"
+
+	tests := []struct {
+		name string
+		args func(Config) ([]string, error)
+		cfg  Config
+		want string
+	}{
+		{"pw-record", pwRecordArgs, cfg, "--format s16 --rate 16000 --channels 1 -"},
+		{"pw-record device", pwRecordArgs, withDevice, "--format s16 --rate 16000 --channels 1 - --target mic"},
+		{"parec", parecArgs, cfg, "--raw --format=s16le --rate=16000 --channels=1"},
+		{"parec device", parecArgs, withDevice, "--raw --format=s16le --rate=16000 --channels=1 --device=mic"},
+		{"arecord", arecordArgs, cfg, "-q -t raw -f S16_LE -r 16000 -c 1"},
+		{"arecord device", arecordArgs, withDevice, "-q -t raw -f S16_LE -r 16000 -c 1 -D mic"},
+		{"ffmpeg", ffmpegArgs, cfg, "-nostdin -hide_banner -loglevel error -f pulse -sample_rate 16000 -channels 1 -i default -f s16le -acodec pcm_s16le -ar 16000 -ac 1 pipe:1"},
+		{"ffmpeg device", ffmpegArgs, withDevice, "-nostdin -hide_banner -loglevel error -f pulse -sample_rate 16000 -channels 1 -i mic -f s16le -acodec pcm_s16le -ar 16000 -ac 1 pipe:1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := tt.args(tt.cfg)
+			if err != nil {
+				t.Fatalf("args error = %v", err)
+			}
+			if got := strings.Join(args, " "); got != tt.want {
+				t.Errorf("args = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	unsupported := cfg
+	unsupported.Format = "s24"
+	for _, args := range []func(Config) ([]string, error){parecArgs, arecordArgs, ffmpegArgs} {
+		if _, err := args(unsupported); err == nil {
+			t.Errorf("args accepted format s24")
+		}
+	}
+}
+
+// writeWAV writes pcm as a 16 kHz mono 16-bit WAV file
+func writeWAV(t *testing.T, pcm []byte) string {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+len(pcm)))
+	buf.WriteString("WAVEfmt ")
+	for _, v := range []any{uint32(16), uint16(1), uint16(1), uint32(16000), uint32(32000), uint16(2), uint16(16)} {
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(pcm)))
+	buf.Write(pcm)
+
+	path := filepath.Join(t.TempDir(), "speech.wav")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func fileConfig(path string) Config {
+	return Config{
+		SampleRate:        16000,
+		Channels:          1,
+		Format:            "s16",
+		BufferSize:        3200,
+		ChannelBufferSize: 30,
+		Backend:           BackendFile,
+		File:              path,
+	}
+}
+
+func TestFileBackend_Recording(t *testing.T) {
+	pcm := slices.Concat(tone(1000, 100*time.Millisecond), tone(2000, 150*time.Millisecond))
+	recorder := NewRecorder(fileConfig(writeWAV(t, pcm)))
+
+	start := time.Now()
+	frameCh, errCh, err := recorder.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	var got []byte
+	for frame := range frameCh {
+		got = append(got, frame.Data...)
+	}
+	if err := <-errCh; err != nil {
+		t.Errorf("recording error = %v", err)
+	}
+	if !bytes.Equal(got, pcm) {
+		t.Errorf("recorded %d bytes, want the %d bytes of the file", len(got), len(pcm))
+	}
+	// the file plays in real time like a microphone
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("file played in %v, want about 250ms", elapsed)
+	}
+	if recorder.IsRecording() {
+		t.Errorf("IsRecording() = true after the file ended")
+	}
+}
+
+func TestFileBackend_Stop(t *testing.T) {
+	recorder := NewRecorder(fileConfig(writeWAV(t, tone(1000, 5*time.Second))))
+	frameCh, _, err := recorder.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	<-frameCh
+
+	recorder.Stop()
+	for range frameCh {
+	}
+	if recorder.IsRecording() {
+		t.Errorf("IsRecording() = true after Stop()")
+	}
+}
+
+func TestFileBackend_Available(t *testing.T) {
+	path := writeWAV(t, tone(0, 10*time.Millisecond))
+	stereo := fileConfig(path)
+	stereo.Channels = 2
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"wav file", fileConfig(path), false},
+		{"stdin", fileConfig(Stdin), false},
+		{"no file", fileConfig(""), true},
+		{"missing file", fileConfig(filepath.Join(t.TempDir(), "missing.wav")), true},
+		{"stereo", stereo, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewBackend(tt.cfg).Available(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Available() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSharedReader(t *testing.T) {
+	pr, pw := io.Pipe()
+	shared := &sharedReader{r: pr}
+	fast := Config{SampleRate: 16000 * 1000, Channels: 1, Format: "s16"}
+	buf := make([]byte, 3)
+
+	// the first recording ends with part of a chunk unread
+	ctx, cancel := context.WithCancel(context.Background())
+	first := shared.stream(ctx, newPacer(fast))
+	go pw.Write([]byte("first"))
+	if n, err := first.Read(buf); err != nil || string(buf[:n]) != "fir" {
+		t.Fatalf("first Read() = %q, %v", buf[:n], err)
+	}
+	cancel()
+	if _, err := first.Read(buf); err != context.Canceled {
+		t.Errorf("Read() after cancel error = %v, want %v", err, context.Canceled)
+	}
+	first.Close()
+
+	// the next recording continues where it stopped, from the same reader
+	second := shared.stream(context.Background(), newPacer(fast))
+	go func() {
+		pw.Write([]byte("second"))
+		pw.Close()
+	}()
+	got, err := io.ReadAll(second)
+	if err != nil || string(got) != "stsecond" {
+		t.Errorf("second ReadAll() = %q, %v", got, err)
+	}
+}
+
+func TestSharedReader_Paced(t *testing.T) {
+	// 100ms of 16 kHz mono s16 arrives at once but is read in real time
+	pcm := make([]byte, 3200)
+	shared := &sharedReader{r: bytes.NewReader(pcm)}
+	cfg := Config{SampleRate: 16000, Channels: 1, Format: "s16"}
+
+	start := time.Now()
+	got, err := io.ReadAll(shared.stream(context.Background(), newPacer(cfg)))
+	if err != nil || len(got) != len(pcm) {
+		t.Fatalf("ReadAll() = %d bytes, %v", len(got), err)
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("read 100ms of audio in %v, want it paced", elapsed)
+	}
+}
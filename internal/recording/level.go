@@ -0,0 +1,100 @@
+package recording
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// DefaultSilenceLevel is the level below which audio counts as silence (about -40 dBFS)
+const DefaultSilenceLevel = 0.01
+
+// Level returns the RMS level of s16le samples, from 0 (silence) to 1 (full scale)
+func Level(pcm []byte) float64 {
+	n := len(pcm) / 2
+	if n == 0 {
+		return 0
+	}
+	var sum float64
+	for i := 0; i < n; i++ {
+		sample := float64(int16(binary.LittleEndian.Uint16(pcm[2*i:]))) / math.MaxInt16
+		sum += sample * sample
+	}
+	return math.Sqrt(sum / float64(n))
+}
+
+// ZeroCrossingRate returns the fraction of adjacent s16le samples that
+// change sign, from 0 to 1. Hiss-like speech sounds such as "s" and "f" have
+// a high rate at low energy.
+func ZeroCrossingRate(pcm []byte) float64 {
+	n := len(pcm) / 2
+	if n < 2 {
+		return 0
+	}
+	crossings := 0
+	prev := int16(binary.LittleEndian.Uint16(pcm))
+	for i := 1; i < n; i++ {
+		sample := int16(binary.LittleEndian.Uint16(pcm[2*i:]))
+		if (prev < 0) != (sample < 0) {
+			crossings++
+		}
+		prev = sample
+	}
+	return float64(crossings) / float64(n-1)
+}
+
+// fricativeRate is the zero-crossing rate above which a frame at half the
+// speech level still counts as speech
+const fricativeRate = 0.3
+
+// IsSpeech reports whether a frame sounds like speech: its level reaches
+// threshold, or it is a quieter frame with the high zero-crossing rate of a
+// fricative, so trailing "s" sounds do not count as silence
+func IsSpeech(pcm []byte, threshold float64) bool {
+	level := Level(pcm)
+	if level >= threshold {
+		return true
+	}
+	return level >= threshold/2 && ZeroCrossingRate(pcm) >= fricativeRate
+}
+
+// SilenceDetector tells when speech has been followed by a stretch of
+// silence, so a recording can stop on its own. Silence before the first
+// speech does not count.
+type SilenceDetector struct {
+	level          float64
+	silence        time.Duration
+	minSpeech      time.Duration
+	bytesPerSecond int
+
+	speech time.Duration
+	quiet  time.Duration
+}
+
+// NewSilenceDetector returns a detector for audio recorded with cfg that
+// fires after silence of at least the given length below level
+func NewSilenceDetector(cfg Config, level float64, silence time.Duration) *SilenceDetector {
+	return &SilenceDetector{
+		level:          level,
+		silence:        silence,
+		bytesPerSecond: cfg.SampleRate * cfg.Channels * 2,
+	}
+}
+
+// Feed adds a frame and reports whether speech has been followed by enough silence
+func (d *SilenceDetector) Feed(pcm []byte) bool {
+	if d.bytesPerSecond <= 0 {
+		return false
+	}
+	length := time.Duration(len(pcm)) * time.Second / time.Duration(d.bytesPerSecond)
+	if IsSpeech(pcm, d.level) {
+		d.speech += length
+		d.quiet = 0
+		return false
+	}
+	if d.speech == 0 || d.speech < d.minSpeech {
+		return false
+	}
+	d.quiet += length
+	return d.quiet >= d.silence
+}
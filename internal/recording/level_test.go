@@ -0,0 +1,96 @@
+package recording
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// tone returns d of 16 kHz mono s16le audio at a constant amplitude
+func tone(amplitude int16, d time.Duration) []byte {
+	pcm := make([]byte, int(d.Seconds()*16000)*2)
+	for i := 0; i < len(pcm); i += 2 {
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(amplitude))
+	}
+	return pcm
+}
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		pcm  []byte
+		want float64
+	}{
+		{"empty", nil, 0},
+		{"silence", tone(0, 100*time.Millisecond), 0},
+		{"full scale", tone(math.MaxInt16, 100*time.Millisecond), 1},
+		{"half scale negative", tone(-math.MaxInt16/2, 100*time.Millisecond), 0.5},
+	}
+	for _, tt := range tests {
+		if got := Level(tt.pcm); math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("Level(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSilenceDetector(t *testing.T) {
+	cfg := Config{SampleRate: 16000, Channels: 1}
+	d := NewSilenceDetector(cfg, DefaultSilenceLevel, time.Second)
+	quiet := tone(0, 250*time.Millisecond)
+	loud := tone(5000, 250*time.Millisecond)
+
+	// leading silence does not count
+	for i := 0; i < 8; i++ {
+		if d.Feed(quiet) {
+			t.Fatalf("fired on silence before any speech")
+		}
+	}
+
+	d.Feed(loud)
+	for i := 0; i < 3; i++ {
+		if d.Feed(quiet) {
+			t.Fatalf("fired after %v of silence, want 1s", time.Duration(i+1)*250*time.Millisecond)
+		}
+	}
+	// speech resets the silence
+	d.Feed(loud)
+	for i := 0; i < 3; i++ {
+		d.Feed(quiet)
+	}
+	if !d.Feed(quiet) {
+		t.Errorf("did not fire after 1s of silence following speech")
+	}
+}
+
+// hiss returns d of 16 kHz mono s16le audio alternating between +amplitude
+// and -amplitude, the highest possible zero-crossing rate
+func hiss(amplitude int16, d time.Duration) []byte {
+	pcm := tone(amplitude, d)
+	for i := 2; i < len(pcm); i += 4 {
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(-amplitude))
+	}
+	return pcm
+}
+
+func TestIsSpeech(t *testing.T) {
+	tests := []struct {
+		name string
+		pcm  []byte
+		want bool
+	}{
+		{"silence", tone(0, 100*time.Millisecond), false},
+		{"loud", tone(5000, 100*time.Millisecond), true},
+		{"quiet hum", tone(200, 100*time.Millisecond), false},
+		{"quiet hiss", hiss(200, 100*time.Millisecond), true},
+		{"faint hiss", hiss(50, 100*time.Millisecond), false},
+	}
+	for _, tt := range tests {
+		if got := IsSpeech(tt.pcm, 0.01); got != tt.want {
+			t.Errorf("IsSpeech(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if rate := ZeroCrossingRate(hiss(100, 10*time.Millisecond)); rate != 1 {
+		t.Errorf("ZeroCrossingRate(hiss) = %v, want 1", rate)
+	}
+}
@@ -0,0 +1,145 @@
+package recording
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// MeterInterval is how much audio a Meter averages over before reporting levels
+const MeterInterval = 100 * time.Millisecond
+
+// SilentMicAfter is how long a recording has to be digital silence before
+// the microphone is reported as silent
+const SilentMicAfter = 3 * time.Second
+
+// mutedPeak is the peak below which audio counts as digital silence (about
+// -80 dBFS, a few LSB), the output of a muted source or a disconnected device
+const mutedPeak = 1e-4
+
+// MinDBFS is the level reported for silence by DBFS, the range of 16-bit audio
+const MinDBFS = -96
+
+// Levels is the loudness of the audio over one meter interval, from 0
+// (silence) to 1 (full scale)
+type Levels struct {
+	RMS  float64
+	Peak float64
+}
+
+// DBFS converts a level from 0 to 1 to dBFS, clamped to MinDBFS
+func DBFS(level float64) float64 {
+	if level <= 0 {
+		return MinDBFS
+	}
+	return max(MinDBFS, 20*math.Log10(level))
+}
+
+// meterFloor is the dBFS level shown as an empty meter by LevelPercent
+const meterFloor = -60
+
+// LevelPercent maps a level from 0 to 1 to 0-100 on a dBFS scale from -60
+// to 0, so a meter moves like a VU meter rather than staying near zero
+func LevelPercent(level float64) int {
+	percent := math.Round(100 * (DBFS(level) - meterFloor) / -meterFloor)
+	return int(min(100, max(0, percent)))
+}
+
+// Peak returns the largest absolute s16le sample, from 0 to 1
+func Peak(pcm []byte) float64 {
+	var peak int
+	for i := 0; i+1 < len(pcm); i += 2 {
+		sample := int(int16(binary.LittleEndian.Uint16(pcm[i:])))
+		if sample < 0 {
+			sample = -sample
+		}
+		peak = max(peak, sample)
+	}
+	return min(1, float64(peak)/math.MaxInt16)
+}
+
+// Meter measures the levels of recorded audio over fixed intervals and
+// tells when the start of a recording is digital silence
+type Meter struct {
+	bytesPerSecond int
+	intervalBytes  int
+
+	sum   float64 // squared samples of the current interval
+	count int
+	peak  float64
+
+	elapsed  time.Duration
+	heard    bool // a sample above mutedPeak was recorded
+	reported bool // Silent already returned true
+}
+
+// NewMeter returns a meter for audio recorded with cfg, or nil when the
+// format is not s16
+func NewMeter(cfg Config) *Meter {
+	if cfg.Format != "s16" {
+		return nil
+	}
+	bytesPerSecond := cfg.SampleRate * cfg.Channels * 2
+	return &Meter{
+		bytesPerSecond: bytesPerSecond,
+		intervalBytes:  int(time.Duration(bytesPerSecond) * MeterInterval / time.Second),
+	}
+}
+
+// Feed adds a frame and returns the levels of the interval it completed, if any
+func (m *Meter) Feed(pcm []byte) (Levels, bool) {
+	if m.bytesPerSecond <= 0 {
+		return Levels{}, false
+	}
+	m.elapsed += time.Duration(len(pcm)) * time.Second / time.Duration(m.bytesPerSecond)
+
+	peak := Peak(pcm)
+	if peak >= mutedPeak {
+		m.heard = true
+	}
+	m.peak = max(m.peak, peak)
+	if samples := len(pcm) / 2; samples > 0 {
+		level := Level(pcm)
+		m.sum += level * level * float64(samples)
+		m.count += samples
+	}
+
+	if m.count*2 < m.intervalBytes {
+		return Levels{}, false
+	}
+	levels := Levels{RMS: math.Sqrt(m.sum / float64(m.count)), Peak: m.peak}
+	m.sum, m.count, m.peak = 0, 0, 0
+	return levels, true
+}
+
+// Silent reports whether the first SilentMicAfter of the recording was
+// digital silence, which usually means a muted microphone or the wrong
+// device. It returns true at most once.
+func (m *Meter) Silent() bool {
+	if m.reported || m.heard || m.elapsed < SilentMicAfter {
+		return false
+	}
+	m.reported = true
+	return true
+}
+
+// MeterFrames passes the frames of in through m. The returned channel
+// carries the same frames, levels gets the levels of every interval (those
+// not read in time are dropped) and silent is closed when m reports a silent
+// microphone.
+func MeterFrames(in <-chan AudioFrame, m *Meter) (<-chan AudioFrame, <-chan Levels, <-chan struct{}) {
+	levels := make(chan Levels, 10)
+	silent := make(chan struct{})
+	out, _ := tap(in, func(frame AudioFrame) {
+		if l, ok := m.Feed(frame.Data); ok {
+			select {
+			case levels <- l:
+			default:
+			}
+		}
+		if m.Silent() {
+			close(silent)
+		}
+	})
+	return out, levels, silent
+}
@@ -0,0 +1,131 @@
+package recording
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDBFS(t *testing.T) {
+	tests := []struct {
+		level float64
+		want  float64
+	}{
+		{0, MinDBFS},
+		{1e-9, MinDBFS},
+		{1, 0},
+		{0.1, -20},
+	}
+	for _, tt := range tests {
+		if got := DBFS(tt.level); math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("DBFS(%v) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestLevelPercent(t *testing.T) {
+	tests := []struct {
+		level float64
+		want  int
+	}{
+		{0, 0},
+		{0.0001, 0},
+		{0.001, 0},
+		{0.01, 33},
+		{0.1, 67},
+		{1, 100},
+	}
+	for _, tt := range tests {
+		if got := LevelPercent(tt.level); got != tt.want {
+			t.Errorf("LevelPercent(%v) = %d, want %d", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestPeak(t *testing.T) {
+	if got := Peak(tone(-32768, 10*time.Millisecond)); got != 1 {
+		t.Errorf("Peak(min sample) = %v, want 1", got)
+	}
+	if got := Peak(tone(3277, 10*time.Millisecond)); math.Abs(got-0.1) > 0.001 {
+		t.Errorf("Peak(3277) = %v, want 0.1", got)
+	}
+}
+
+func TestMeter_Levels(t *testing.T) {
+	if NewMeter(Config{SampleRate: 16000, Channels: 1, Format: "f32"}) != nil {
+		t.Fatalf("NewMeter() should return nil for non-s16 formats")
+	}
+	m := NewMeter(Config{SampleRate: 16000, Channels: 1, Format: "s16"})
+
+	if _, ok := m.Feed(tone(3277, 60*time.Millisecond)); ok {
+		t.Fatalf("reported levels before a full interval")
+	}
+	levels, ok := m.Feed(tone(0, 40*time.Millisecond))
+	if !ok {
+		t.Fatalf("no levels after %v", MeterInterval)
+	}
+	// 60% of the interval at 0.1 and the rest silent
+	if want := 0.1 * math.Sqrt(0.6); math.Abs(levels.RMS-want) > 0.001 {
+		t.Errorf("RMS = %v, want %v", levels.RMS, want)
+	}
+	if math.Abs(levels.Peak-0.1) > 0.001 {
+		t.Errorf("Peak = %v, want 0.1", levels.Peak)
+	}
+
+	levels, ok = m.Feed(tone(0, MeterInterval))
+	if !ok || levels.RMS != 0 || levels.Peak != 0 {
+		t.Errorf("next interval = %+v, %v, want silence", levels, ok)
+	}
+}
+
+func TestMeter_Silent(t *testing.T) {
+	cfg := Config{SampleRate: 16000, Channels: 1, Format: "s16"}
+
+	m := NewMeter(cfg)
+	for elapsed := time.Duration(0); elapsed < SilentMicAfter; elapsed += 500 * time.Millisecond {
+		if m.Silent() {
+			t.Fatalf("reported silent after %v", elapsed)
+		}
+		m.Feed(tone(0, 500*time.Millisecond))
+	}
+	if !m.Silent() {
+		t.Errorf("did not report silent after %v of zeros", SilentMicAfter)
+	}
+	if m.Silent() {
+		t.Errorf("reported silent twice")
+	}
+
+	// quiet room noise is not a muted microphone
+	m = NewMeter(cfg)
+	m.Feed(tone(20, SilentMicAfter))
+	if m.Silent() {
+		t.Errorf("reported low noise as silent")
+	}
+}
+
+func TestMeterFrames(t *testing.T) {
+	cfg := Config{SampleRate: 16000, Channels: 1, Format: "s16"}
+	in := make(chan AudioFrame, 40)
+	out, levels, silent := MeterFrames(in, NewMeter(cfg))
+
+	for i := 0; i < 31; i++ {
+		in <- AudioFrame{Data: tone(0, MeterInterval)}
+	}
+	close(in)
+
+	frames := 0
+	for range out {
+		frames++
+	}
+	if frames != 31 {
+		t.Errorf("got %d frames, want 31", frames)
+	}
+	select {
+	case <-silent:
+	default:
+		t.Errorf("silent not closed after %v of zeros", SilentMicAfter)
+	}
+	if got := len(levels); got != cap(levels) {
+		t.Errorf("got %d buffered levels, want the %d that fit", got, cap(levels))
+	}
+}
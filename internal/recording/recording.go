@@ -1,19 +1,20 @@
 package recording
 
 import (
-	"bufio"
 	"context"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os/exec"
-	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 )
 
+var logger = logging.For("recording")
+
 type AudioFrame struct {
 	Data      []byte
 	Timestamp time.Time
@@ -24,9 +25,13 @@ type Config struct {
 	Channels          int
 	Format            string
 	BufferSize        int
+	Backend           string // capture backend, one of Backends ("" = DefaultBackend)
 	Device            string
+	File              string // audio file read by the file backend, Stdin for raw PCM on stdin
 	ChannelBufferSize int
 	Timeout           time.Duration
+	VAD               VADConfig
+	Warm              WarmConfig
 }
 
 // Recorder interface for audio recording
@@ -40,8 +45,7 @@ type recorder struct {
 	config    Config
 	recording atomic.Bool
 
-	mu     sync.Mutex // guards cmd and cancel
-	cmd    *exec.Cmd
+	mu     sync.Mutex // guards cancel
 	cancel context.CancelFunc
 
 	wg sync.WaitGroup
@@ -64,8 +68,12 @@ func (r *recorder) Start(ctx context.Context) (<-chan AudioFrame, <-chan error,
 		return nil, nil, err
 	}
 
-	if err := CheckPipeWireAvailable(ctx); err != nil {
-		return nil, nil, fmt.Errorf("PipeWire not available: %w", err)
+	backend := NewBackend(r.config)
+	if backend == nil {
+		return nil, nil, fmt.Errorf("unknown recording backend: %q", r.config.Backend)
+	}
+	if err := backend.Available(ctx); err != nil {
+		return nil, nil, fmt.Errorf("%s not available: %w", backend.Name(), err)
 	}
 
 	recordingCtx, cancel := context.WithCancel(ctx)
@@ -79,7 +87,7 @@ func (r *recorder) Start(ctx context.Context) (<-chan AudioFrame, <-chan error,
 
 	r.recording.Store(true)
 	r.wg.Add(1)
-	go r.captureLoop(recordingCtx, frameCh, errCh)
+	go r.captureLoop(recordingCtx, backend, frameCh, errCh)
 
 	return frameCh, errCh, nil
 }
@@ -94,7 +102,7 @@ func (r *recorder) Stop() {
 	r.wg.Wait()
 }
 
-func (r *recorder) captureLoop(ctx context.Context, frameCh chan<- AudioFrame, errCh chan<- error) {
+func (r *recorder) captureLoop(ctx context.Context, backend Backend, frameCh chan<- AudioFrame, errCh chan<- error) {
 	defer func() {
 		close(frameCh)
 		close(errCh)
@@ -107,37 +115,18 @@ func (r *recorder) captureLoop(ctx context.Context, frameCh chan<- AudioFrame, e
 		r.wg.Done()
 	}()
 
-	args := r.buildPwRecordArgs()
-	cmd := exec.CommandContext(ctx, "pw-record", args...)
-
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		r.emitErr(errCh, fmt.Errorf("create stdout pipe: %w", err))
-		r.requestCancel()
-		return
-	}
-
-	stderr, err := cmd.StderrPipe()
+	stream, err := backend.Open(ctx)
 	if err != nil {
-		r.emitErr(errCh, fmt.Errorf("create stderr pipe: %w", err))
+		r.emitErr(errCh, err)
 		r.requestCancel()
 		return
 	}
-
-	if err := cmd.Start(); err != nil {
-		r.emitErr(errCh, fmt.Errorf("start pw-record: %w", err))
+	defer func() {
+		// report why a capture command exited before the recording was stopped
+		stopped := ctx.Err() != nil
 		r.requestCancel()
-		return
-	}
-
-	r.mu.Lock()
-	r.cmd = cmd
-	r.mu.Unlock()
-
-	go func() {
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			log.Printf("Recording stderr: %s", scanner.Text())
+		if err := stream.Close(); err != nil && !stopped {
+			r.emitErr(errCh, fmt.Errorf("%s exited: %w", backend.Name(), err))
 		}
 	}()
 
@@ -150,7 +139,7 @@ func (r *recorder) captureLoop(ctx context.Context, frameCh chan<- AudioFrame, e
 			var sentCount int
 			var droppedCount int
 			lastDropLog := time.Now()
-			n, readErr := stdout.Read(buffer)
+			n, readErr := stream.Read(buffer)
 			if n > 0 {
 				frameData := make([]byte, n)
 				copy(frameData, buffer[:n])
@@ -165,7 +154,7 @@ func (r *recorder) captureLoop(ctx context.Context, frameCh chan<- AudioFrame, e
 				default:
 					droppedCount++
 					if time.Since(lastDropLog) > time.Second {
-						log.Printf("Recording: dropped %d frames due to backpressure", droppedCount)
+						logger.Warn("Dropped frames due to backpressure", "frames", droppedCount)
 						lastDropLog = time.Now()
 						droppedCount = 0
 					}
@@ -173,7 +162,7 @@ func (r *recorder) captureLoop(ctx context.Context, frameCh chan<- AudioFrame, e
 			}
 
 			if readErr != nil {
-				if errors.Is(readErr, io.EOF) {
+				if errors.Is(readErr, io.EOF) || ctx.Err() != nil {
 					return
 				}
 				r.emitErr(errCh, fmt.Errorf("read audio: %w", readErr))
@@ -199,20 +188,7 @@ func (r *recorder) emitErr(errCh chan<- error, err error) {
 	case errCh <- err:
 	default:
 	}
-	log.Printf("Recording error: %v", err)
-}
-
-func (r *recorder) buildPwRecordArgs() []string {
-	args := []string{
-		"--format", r.config.Format,
-		"--rate", strconv.Itoa(r.config.SampleRate),
-		"--channels", strconv.Itoa(r.config.Channels),
-		"-", // stdout
-	}
-	if r.config.Device != "" {
-		args = append(args, "--target", r.config.Device)
-	}
-	return args
+	logger.Error("Recording error", "err", err)
 }
 
 func CheckPipeWireAvailable(ctx context.Context) error {
@@ -249,9 +225,42 @@ func (r *recorder) validateConfig() error {
 	if r.config.Format == "s16" {
 		frameBytes := 2 * r.config.Channels
 		if r.config.BufferSize%frameBytes != 0 {
-			log.Printf("Recording: BufferSize %d not aligned to frame size %d; audio frames may split",
-				r.config.BufferSize, frameBytes)
+			logger.Warn("Buffer size not aligned to frame size, audio frames may split",
+				"buffer_size", r.config.BufferSize, "frame_bytes", frameBytes)
 		}
 	}
 	return nil
 }
+
+// tap passes the frames of in on to the returned channel, calling inspect
+// (when set) with each one first. The done channel is closed after the
+// returned channel, once in is closed. Like the recorder, a tap drops frames
+// instead of blocking when its reader falls behind, so a slow stage never
+// holds up the capture.
+func tap(in <-chan AudioFrame, inspect func(AudioFrame)) (<-chan AudioFrame, <-chan struct{}) {
+	out := make(chan AudioFrame, cap(in))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer close(out)
+		for frame := range in {
+			if inspect != nil {
+				inspect(frame)
+			}
+			select {
+			case out <- frame:
+			default:
+				logger.Warn("Dropped frame due to backpressure")
+			}
+		}
+	}()
+	return out, done
+}
+
+// WatchEnd passes the frames of in through. The ended channel is closed once
+// in is closed and every frame was passed on, which also happens when the
+// capture ends by itself: a file backend at the end of the file, or a capture
+// tool that exited.
+func WatchEnd(in <-chan AudioFrame) (<-chan AudioFrame, <-chan struct{}) {
+	return tap(in, nil)
+}
@@ -0,0 +1,45 @@
+package recording
+
+import "time"
+
+// VAD defaults, used when [recording.vad] leaves them out
+const (
+	DefaultVADSilence   = 1500 * time.Millisecond
+	DefaultVADMinSpeech = 300 * time.Millisecond
+)
+
+// VADConfig configures voice activity detection, which ends a recording
+// after speech followed by silence
+type VADConfig struct {
+	Enabled   bool
+	Threshold float64       // RMS level that counts as speech (0-1)
+	Silence   time.Duration // trailing silence that ends the recording
+	MinSpeech time.Duration // speech needed before silence can end it
+}
+
+// NewVAD returns the detector configured in cfg.VAD, or nil when voice
+// activity detection is off. It only fires once at least MinSpeech of
+// speech was heard, so a cough or a click does not end the recording.
+func NewVAD(cfg Config) *SilenceDetector {
+	if !cfg.VAD.Enabled {
+		return nil
+	}
+	d := NewSilenceDetector(cfg, cfg.VAD.Threshold, cfg.VAD.Silence)
+	d.minSpeech = cfg.VAD.MinSpeech
+	return d
+}
+
+// StopOnSilence passes the frames of in through d. The returned channel
+// carries the same frames; the stop channel is closed the first time d
+// hears enough silence after speech.
+func StopOnSilence(in <-chan AudioFrame, d *SilenceDetector) (<-chan AudioFrame, <-chan struct{}) {
+	stop := make(chan struct{})
+	stopped := false
+	out, _ := tap(in, func(frame AudioFrame) {
+		if !stopped && d.Feed(frame.Data) {
+			stopped = true
+			close(stop)
+		}
+	})
+	return out, stop
+}
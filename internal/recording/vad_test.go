@@ -0,0 +1,65 @@
+package recording
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewVAD_MinSpeech(t *testing.T) {
+	cfg := Config{SampleRate: 16000, Channels: 1}
+	if NewVAD(cfg) != nil {
+		t.Fatalf("NewVAD() with VAD disabled should return nil")
+	}
+
+	cfg.VAD = VADConfig{Enabled: true, Threshold: DefaultSilenceLevel, Silence: 500 * time.Millisecond, MinSpeech: 300 * time.Millisecond}
+	d := NewVAD(cfg)
+	quiet := tone(0, 250*time.Millisecond)
+
+	// a click is not enough speech
+	d.Feed(tone(5000, 100*time.Millisecond))
+	for i := 0; i < 4; i++ {
+		if d.Feed(quiet) {
+			t.Fatalf("fired after only 100ms of speech")
+		}
+	}
+
+	d.Feed(tone(5000, 250*time.Millisecond))
+	d.Feed(quiet)
+	if !d.Feed(quiet) {
+		t.Errorf("did not fire after 500ms of silence following 350ms of speech")
+	}
+}
+
+func TestStopOnSilence(t *testing.T) {
+	cfg := Config{SampleRate: 16000, Channels: 1}
+	in := make(chan AudioFrame, 10)
+	out, stop := StopOnSilence(in, NewSilenceDetector(cfg, DefaultSilenceLevel, 200*time.Millisecond))
+
+	in <- AudioFrame{Data: tone(5000, 100*time.Millisecond)}
+	in <- AudioFrame{Data: tone(0, 100*time.Millisecond)}
+	<-out
+	<-out
+	select {
+	case <-stop:
+		t.Fatalf("stopped after 100ms of silence")
+	default:
+	}
+
+	in <- AudioFrame{Data: tone(0, 100*time.Millisecond)}
+	in <- AudioFrame{Data: tone(0, 100*time.Millisecond)}
+	close(in)
+	select {
+	case <-stop:
+	case <-time.After(time.Second):
+		t.Fatalf("did not stop after 200ms of silence")
+	}
+
+	// frames keep flowing after the stop until the input closes
+	frames := 0
+	for range out {
+		frames++
+	}
+	if frames != 2 {
+		t.Errorf("got %d frames after the stop, want 2", frames)
+	}
+}
@@ -0,0 +1,244 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Pre-roll limits for [recording.warm]
+const (
+	DefaultPreRoll = 300 * time.Millisecond
+	MaxPreRoll     = 5 * time.Second
+)
+
+// warmRetryDelay is how long a warm recorder waits before starting the
+// capture again after it failed or the capture command exited
+const warmRetryDelay = 2 * time.Second
+
+// errWarmStopped is reported to a recording when the warm capture under it ends
+var errWarmStopped = errors.New("warm recorder stopped")
+
+// WarmConfig keeps the microphone open between recordings
+type WarmConfig struct {
+	Enabled bool
+	PreRoll time.Duration // audio from before Start prepended to each recording
+}
+
+// Warm keeps a capture running while the daemon is idle, so starting a
+// recording does not wait for the capture command and its checks. It keeps
+// the last PreRoll of audio in memory and prepends it to the next
+// recording, so the first syllable is not lost when speech starts right at
+// the toggle. Older audio is discarded and never leaves the process.
+type Warm struct {
+	config       Config
+	source       Recorder
+	preRollBytes int
+
+	mu      sync.Mutex
+	running bool         // source is capturing
+	ring    []byte       // the last PreRoll of audio while idle
+	session *warmSession // the recording reading the capture, nil while idle
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWarm returns a warm recorder that captures with source, which is
+// started with cfg and restarted when it fails
+func NewWarm(cfg Config, source Recorder) *Warm {
+	frameBytes := cfg.Channels * 2
+	preRoll := int(time.Duration(cfg.SampleRate*frameBytes) * cfg.Warm.PreRoll / time.Second)
+	if frameBytes > 0 {
+		preRoll -= preRoll % frameBytes
+	}
+	return &Warm{config: cfg, source: source, preRollBytes: preRoll}
+}
+
+// Start starts capturing in the background until ctx ends or Close is called
+func (w *Warm) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	w.cancel = cancel
+	w.wg.Add(1)
+	go w.run(ctx)
+}
+
+// Close stops capturing and ends a recording still reading from it
+func (w *Warm) Close() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+}
+
+// Running reports whether the capture is running
+func (w *Warm) Running() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.running
+}
+
+// Recorder returns a recorder for one recording that reads from the warm
+// capture; it fits pipeline.RecorderFactory. Configs that capture
+// differently, such as a profile with another device, and recordings
+// started while the capture is down get a cold NewRecorder instead.
+func (w *Warm) Recorder(cfg Config) Recorder {
+	if !w.captures(cfg) || !w.Running() {
+		return NewRecorder(cfg)
+	}
+	return &warmSession{warm: w, config: cfg}
+}
+
+// captures reports whether cfg records the same audio as the warm capture
+func (w *Warm) captures(cfg Config) bool {
+	return cfg.SampleRate == w.config.SampleRate &&
+		cfg.Channels == w.config.Channels &&
+		cfg.Format == w.config.Format &&
+		cfg.Backend == w.config.Backend &&
+		cfg.Device == w.config.Device &&
+		cfg.File == w.config.File &&
+		cfg.BufferSize == w.config.BufferSize
+}
+
+func (w *Warm) run(ctx context.Context) {
+	defer w.wg.Done()
+	for {
+		frameCh, errCh, err := w.source.Start(ctx)
+		if err != nil {
+			logger.Warn("Failed to start warm recorder", "retry_in", warmRetryDelay, "err", err)
+		} else {
+			logger.Info("Warm recorder started, the microphone stays open while idle", "pre_roll", w.config.Warm.PreRoll)
+			err = w.capture(frameCh, errCh)
+			w.source.Stop()
+			if ctx.Err() == nil {
+				logger.Warn("Warm recorder stopped", "retry_in", warmRetryDelay, "err", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(warmRetryDelay):
+		}
+	}
+}
+
+// capture feeds frames to the ring or the attached recording until the
+// source stops, and returns the error it stopped with
+func (w *Warm) capture(frameCh <-chan AudioFrame, errCh <-chan error) error {
+	w.mu.Lock()
+	w.running = true
+	w.mu.Unlock()
+
+	for frame := range frameCh {
+		w.feed(frame)
+	}
+	err := <-errCh
+	if err == nil {
+		err = errWarmStopped
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.running = false
+	w.ring = nil
+	if w.session != nil {
+		w.detach(w.session, err)
+	}
+	return err
+}
+
+func (w *Warm) feed(frame AudioFrame) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if s := w.session; s != nil {
+		select {
+		case s.frames <- frame:
+		default:
+			logger.Warn("Dropped frame due to backpressure")
+		}
+		return
+	}
+
+	w.ring = append(w.ring, frame.Data...)
+	if excess := len(w.ring) - w.preRollBytes; excess > 0 {
+		w.ring = w.ring[:copy(w.ring, w.ring[excess:])]
+	}
+}
+
+// attach makes s receive the capture, starting with the buffered pre-roll
+func (w *Warm) attach(s *warmSession) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.running {
+		return errWarmStopped
+	}
+	if w.session != nil {
+		return fmt.Errorf("already recording")
+	}
+
+	s.frames = make(chan AudioFrame, max(1, s.config.ChannelBufferSize))
+	s.errs = make(chan error, 1)
+	s.done = make(chan struct{})
+	if len(w.ring) > 0 {
+		length := time.Duration(len(w.ring)) * time.Second / time.Duration(w.config.SampleRate*w.config.Channels*2)
+		s.frames <- AudioFrame{Data: w.ring, Timestamp: time.Now().Add(-length)}
+		w.ring = nil
+	}
+	w.session = s
+	return nil
+}
+
+// detach ends the recording s with err, if not nil. The caller holds w.mu.
+func (w *Warm) detach(s *warmSession, err error) {
+	if w.session != s {
+		return
+	}
+	w.session = nil
+	if err != nil {
+		s.errs <- err
+	}
+	close(s.frames)
+	close(s.errs)
+	close(s.done)
+}
+
+// warmSession is one recording reading from a Warm capture
+type warmSession struct {
+	warm   *Warm
+	config Config
+
+	frames chan AudioFrame
+	errs   chan error
+	done   chan struct{}
+}
+
+func (s *warmSession) Start(ctx context.Context) (<-chan AudioFrame, <-chan error, error) {
+	if err := s.warm.attach(s); err != nil {
+		return nil, nil, err
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Stop()
+		case <-s.done:
+		}
+	}()
+	return s.frames, s.errs, nil
+}
+
+func (s *warmSession) Stop() {
+	s.warm.mu.Lock()
+	defer s.warm.mu.Unlock()
+	s.warm.detach(s, nil)
+}
+
+func (s *warmSession) IsRecording() bool {
+	s.warm.mu.Lock()
+	defer s.warm.mu.Unlock()
+	return s.warm.session == s
+}
@@ -0,0 +1,175 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSource is a Recorder whose frames and end are driven by the test.
+// Like pw-record, a capture ends when its context does.
+type fakeSource struct {
+	mu     sync.Mutex
+	frames chan AudioFrame
+	end    func(error)
+	starts int
+}
+
+func (f *fakeSource) Start(ctx context.Context) (<-chan AudioFrame, <-chan error, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	frames, errs := make(chan AudioFrame, 10), make(chan error, 1)
+	var once sync.Once
+	end := func(err error) {
+		once.Do(func() {
+			if err != nil {
+				errs <- err
+			}
+			close(frames)
+			close(errs)
+		})
+	}
+	f.frames, f.end, f.starts = frames, end, f.starts+1
+	go func() {
+		<-ctx.Done()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		end(nil)
+	}()
+	return frames, errs, nil
+}
+
+func (f *fakeSource) Stop()             {}
+func (f *fakeSource) IsRecording() bool { return true }
+
+func (f *fakeSource) send(data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.frames <- AudioFrame{Data: data}
+}
+
+// fail ends the current capture with err
+func (f *fakeSource) fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.end(err)
+}
+
+func warmConfig() Config {
+	return Config{
+		SampleRate:        16000,
+		Channels:          1,
+		Format:            "s16",
+		BufferSize:        3200,
+		ChannelBufferSize: 10,
+		Warm:              WarmConfig{Enabled: true, PreRoll: 200 * time.Millisecond},
+	}
+}
+
+func startWarm(t *testing.T, source *fakeSource) *Warm {
+	t.Helper()
+	w := NewWarm(warmConfig(), source)
+	w.Start(context.Background())
+	t.Cleanup(w.Close)
+	waitUntil(t, w.Running)
+	return w
+}
+
+func waitUntil(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWarm_PreRoll(t *testing.T) {
+	source := &fakeSource{}
+	w := startWarm(t, source)
+
+	// 300ms of audio while idle; only the last 200ms are kept
+	for _, amplitude := range []int16{1000, 2000, 3000} {
+		source.send(tone(amplitude, 100*time.Millisecond))
+	}
+	waitUntil(t, func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return len(w.ring) == w.preRollBytes
+	})
+
+	rec := w.Recorder(warmConfig())
+	frameCh, errCh, err := rec.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	source.send(tone(4000, 100*time.Millisecond))
+
+	preRoll := <-frameCh
+	if len(preRoll.Data) != 6400 || Peak(preRoll.Data[:2]) != Peak(tone(2000, time.Millisecond)) {
+		t.Errorf("pre-roll = %d bytes starting at %v, want the last 200ms", len(preRoll.Data), Peak(preRoll.Data[:2]))
+	}
+	if live := <-frameCh; Peak(live.Data) != Peak(tone(4000, time.Millisecond)) {
+		t.Errorf("live frame peak = %v", Peak(live.Data))
+	}
+
+	if _, _, err := w.Recorder(warmConfig()).Start(context.Background()); err == nil {
+		t.Errorf("second recording started while the first is attached")
+	}
+
+	rec.Stop()
+	if _, ok := <-frameCh; ok {
+		t.Errorf("frame channel still open after Stop()")
+	}
+	if _, ok := <-errCh; ok {
+		t.Errorf("error channel still open after Stop()")
+	}
+	if rec.IsRecording() {
+		t.Errorf("IsRecording() = true after Stop()")
+	}
+	if source.starts != 1 {
+		t.Errorf("source started %d times, want the capture kept running", source.starts)
+	}
+}
+
+func TestWarm_ColdFallback(t *testing.T) {
+	w := startWarm(t, &fakeSource{})
+
+	other := warmConfig()
+	other.Device = "usb-mic"
+	if _, ok := w.Recorder(other).(*recorder); !ok {
+		t.Errorf("Recorder() with another device should record cold")
+	}
+	if _, ok := w.Recorder(warmConfig()).(*warmSession); !ok {
+		t.Errorf("Recorder() with the warm config should read the capture")
+	}
+}
+
+func TestWarm_SourceFailure(t *testing.T) {
+	source := &fakeSource{}
+	w := startWarm(t, source)
+
+	frameCh, errCh, err := w.Recorder(warmConfig()).Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	boom := errors.New("pw-record exited")
+	source.fail(boom)
+	if err := <-errCh; !errors.Is(err, boom) {
+		t.Errorf("recording error = %v, want %v", err, boom)
+	}
+	if _, ok := <-frameCh; ok {
+		t.Errorf("frame channel still open after the capture failed")
+	}
+	if w.Running() {
+		t.Errorf("Running() = true after the capture failed")
+	}
+	if _, ok := w.Recorder(warmConfig()).(*recorder); !ok {
+		t.Errorf("Recorder() should record cold while the capture is down")
+	}
+}
@@ -0,0 +1,339 @@
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
+)
+
+var logger = logging.For("stats")
+
+// FileName is the stats file inside the hyprvoice data directory
+const FileName = "stats.jsonl"
+
+// compactAgeSlack is how far past max_age the oldest record may be before the
+// file is rewritten, so steady use does not rewrite it on every dictation
+const compactAgeSlack = time.Hour
+
+// Record holds the measurements of one dictation. It never contains the
+// dictated text, so stats can be kept when history is disabled.
+type Record struct {
+	Time        time.Time `json:"time"`
+	Provider    string    `json:"provider"`
+	Model       string    `json:"model"`
+	LLMProvider string    `json:"llm_provider,omitempty"`
+	LLMModel    string    `json:"llm_model,omitempty"`
+	Language    string    `json:"language,omitempty"`
+
+	RecorderStartMs int64 `json:"recorder_start_ms"`
+	AudioMs         int64 `json:"audio_ms"`
+	TranscriptionMs int64 `json:"transcription_ms"` // transcriber stop and finalize
+	LLMMs           int64 `json:"llm_ms,omitempty"`
+	InjectionMs     int64 `json:"injection_ms"`
+
+	Words  int  `json:"words"`
+	Chars  int  `json:"chars"`
+	Failed bool `json:"failed,omitempty"` // nothing was injected
+}
+
+// LatencyMs is the wait between stopping the recording and injected text
+func (r Record) LatencyMs() int64 {
+	return r.TranscriptionMs + r.LLMMs + r.InjectionMs
+}
+
+// Config controls where stats are stored and how long they are kept
+type Config struct {
+	Enabled    bool
+	MaxEntries int           // 0 = unlimited
+	MaxAge     time.Duration // 0 = keep forever
+	Path       string        // empty = DefaultPath()
+}
+
+// Store persists dictation stats
+type Store interface {
+	Append(r Record) error
+	// List returns the records within the retention limits, oldest first
+	List() ([]Record, error)
+}
+
+type fileStore struct {
+	mu     sync.Mutex
+	config Config
+	path   string
+}
+
+// NewStore opens the stats file described by cfg
+func NewStore(cfg Config) (Store, error) {
+	path := cfg.Path
+	if path == "" {
+		p, err := DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+		path = p
+	}
+	return &fileStore{config: cfg, path: path}, nil
+}
+
+// DefaultPath returns $XDG_DATA_HOME/hyprvoice/stats.jsonl
+func DefaultPath() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "hyprvoice", FileName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "hyprvoice", FileName), nil
+}
+
+func (s *fileStore) Append(r Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+
+	if err := s.appendLine(r); err != nil {
+		return err
+	}
+	if s.needsCompaction() {
+		s.compact()
+	}
+	return nil
+}
+
+func (s *fileStore) appendLine(r Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode stats record: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create stats directory: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open stats: %w", err)
+	}
+	defer f.Close()
+
+	n, err := f.Write(append(data, '\n'))
+	if err != nil {
+		return fmt.Errorf("failed to write stats: %w", err)
+	}
+	if info, err := f.Stat(); err == nil {
+		addLines(s.path, info.Size(), int64(n), 1)
+	}
+	return nil
+}
+
+func (s *fileStore) List() ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return Prune(records, s.config.MaxEntries, s.config.MaxAge, time.Now()), nil
+}
+
+// needsCompaction reports whether the file holds clearly more than the
+// retention limits allow. The line count comes from lineCounts and only the
+// first line is read, so appending does not read the whole file.
+func (s *fileStore) needsCompaction() bool {
+	if s.config.MaxEntries > 0 {
+		slack := max(s.config.MaxEntries/10, 1)
+		if lines, err := countLines(s.path); err == nil && lines > s.config.MaxEntries+slack {
+			return true
+		}
+	}
+	if s.config.MaxAge > 0 {
+		oldest, err := s.first()
+		if err == nil && time.Since(oldest.Time) > s.config.MaxAge+compactAgeSlack {
+			return true
+		}
+	}
+	return false
+}
+
+// first returns the oldest record in the file
+func (s *fileStore) first() (Record, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return Record{}, err
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if len(line) == 0 && err != nil {
+		return Record{}, err
+	}
+	var r Record
+	if err := json.Unmarshal(line, &r); err != nil {
+		return Record{}, err
+	}
+	return r, nil
+}
+
+// lineCount is the number of lines in a stats file of the given size
+type lineCount struct {
+	size  int64
+	lines int
+}
+
+// lineCounts caches the line count of each stats file by path. A store is
+// opened for every dictation, so the count has to outlive it. A count whose
+// size no longer matches the file, after a write by another process, is
+// recounted.
+var (
+	lineCountsMu sync.Mutex
+	lineCounts   = map[string]lineCount{}
+)
+
+// countLines returns the number of lines in the file at path
+func countLines(path string) (int, error) {
+	lineCountsMu.Lock()
+	defer lineCountsMu.Unlock()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if c, ok := lineCounts[path]; ok && c.size == info.Size() {
+		return c.lines, nil
+	}
+
+	lines := 0
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := f.Read(buf)
+		lines += bytes.Count(buf[:n], []byte("\n"))
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	lineCounts[path] = lineCount{size: info.Size(), lines: lines}
+	return lines, nil
+}
+
+// addLines updates the cached count after n lines of written bytes were
+// appended to a file that now has size bytes. When the cached count does not
+// add up, another write happened in between and the next countLines recounts.
+func addLines(path string, size, written int64, n int) {
+	lineCountsMu.Lock()
+	defer lineCountsMu.Unlock()
+	if c, ok := lineCounts[path]; ok && c.size+written == size {
+		lineCounts[path] = lineCount{size: size, lines: c.lines + n}
+	}
+}
+
+// setLines records the count of a file that was just rewritten
+func setLines(path string, size int64, n int) {
+	lineCountsMu.Lock()
+	defer lineCountsMu.Unlock()
+	lineCounts[path] = lineCount{size: size, lines: n}
+}
+
+// compact rewrites the file without the records outside the retention limits
+func (s *fileStore) compact() {
+	records, err := s.read()
+	if err != nil {
+		logger.Warn("Failed to read stats for trimming", "err", err)
+		return
+	}
+	if err := s.write(Prune(records, s.config.MaxEntries, s.config.MaxAge, time.Now())); err != nil {
+		logger.Warn("Failed to trim stats", "err", err)
+	}
+}
+
+// Prune drops records older than maxAge and keeps at most maxEntries of the
+// newest ones. Zero disables either limit. records must be oldest first.
+func Prune(records []Record, maxEntries int, maxAge time.Duration, now time.Time) []Record {
+	if maxAge > 0 {
+		cutoff := now.Add(-maxAge)
+		start := 0
+		for start < len(records) && records[start].Time.Before(cutoff) {
+			start++
+		}
+		records = records[start:]
+	}
+	if maxEntries > 0 && len(records) > maxEntries {
+		records = records[len(records)-maxEntries:]
+	}
+	return records
+}
+
+// write replaces the stats file atomically
+func (s *fileStore) write(records []Record) error {
+	var sb strings.Builder
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to encode stats record: %w", err)
+		}
+		sb.Write(data)
+		sb.WriteString("\n")
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(sb.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write stats: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace stats: %w", err)
+	}
+	setLines(s.path, int64(sb.Len()), len(records))
+	return nil
+}
+
+// read returns every record in the file, skipping torn lines
+func (s *fileStore) read() ([]Record, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stats: %w", err)
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal(line, &r); err != nil {
+			// a torn line from a crash should not hide the rest
+			continue
+		}
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stats: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,161 @@
+package stats
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore_AppendList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	store, err := NewStore(Config{Enabled: true, Path: path})
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	records, err := store.List()
+	if err != nil || len(records) != 0 {
+		t.Fatalf("List() on missing file = %v, %v", records, err)
+	}
+
+	for _, words := range []int{3, 5} {
+		if err := store.Append(Record{Provider: "openai", Model: "whisper-1", Words: words}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	// a torn line is skipped
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	f.WriteString(`{"time":"2025-`)
+	f.Close()
+
+	records, err = store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(records) != 2 || records[0].Words != 3 || records[1].Words != 5 || records[0].Time.IsZero() {
+		t.Errorf("records = %+v", records)
+	}
+
+	info, _ := os.Stat(path)
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("stats file mode = %o, want 600", perm)
+	}
+}
+
+func TestStore_Retention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	store, err := NewStore(Config{Enabled: true, MaxEntries: 10, MaxAge: 24 * time.Hour, Path: path})
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	lines := func() int {
+		data, _ := os.ReadFile(path)
+		return bytes.Count(data, []byte("\n"))
+	}
+
+	store.Append(Record{Time: time.Now().Add(-48 * time.Hour), Words: 1})
+	if got := lines(); got != 0 {
+		t.Errorf("file has %d lines, want the expired record dropped", got)
+	}
+
+	for i := 0; i < 11; i++ {
+		store.Append(Record{Words: i})
+	}
+	if got := lines(); got != 11 {
+		t.Errorf("file has %d lines, want 11 before the slack is used up", got)
+	}
+	records, err := store.List()
+	if err != nil || len(records) != 10 || records[0].Words != 1 {
+		t.Errorf("List() = %d records, %v; want the newest 10", len(records), err)
+	}
+
+	store.Append(Record{Words: 11})
+	if got := lines(); got != 10 {
+		t.Errorf("file has %d lines after trimming, want 10", got)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []int64{50, 10, 40, 20, 30, 60, 70, 80, 90, 100}
+
+	tests := []struct {
+		p    float64
+		want int64
+	}{
+		{50, 50},
+		{95, 100},
+		{0, 10},
+		{100, 100},
+	}
+
+	for _, tt := range tests {
+		if got := Percentile(values, tt.p); got != tt.want {
+			t.Errorf("Percentile(%v) = %d, want %d", tt.p, got, tt.want)
+		}
+	}
+	if got := Percentile(nil, 50); got != 0 {
+		t.Errorf("Percentile(nil) = %d, want 0", got)
+	}
+	if values[0] != 50 {
+		t.Errorf("Percentile() modified its input")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	now := time.Date(2025, 3, 14, 18, 0, 0, 0, time.UTC)
+	at := func(daysAgo int) time.Time {
+		return now.AddDate(0, 0, -daysAgo)
+	}
+
+	records := []Record{
+		{Time: at(0), Provider: "openai", Model: "whisper-1", TranscriptionMs: 800, InjectionMs: 20, Words: 10, AudioMs: 4000},
+		{Time: at(0), Provider: "openai", Model: "whisper-1", TranscriptionMs: 1200, InjectionMs: 30, Words: 20, AudioMs: 6000, LLMProvider: "openai", LLMModel: "gpt-4o-mini", LLMMs: 500},
+		{Time: at(1), Provider: "groq-transcription", Model: "whisper-large-v3", TranscriptionMs: 300, InjectionMs: 20, Words: 5},
+		{Time: at(2), Provider: "openai", Model: "whisper-1", Failed: true, TranscriptionMs: 9000},
+		{Time: at(9), Provider: "openai", Model: "whisper-1", TranscriptionMs: 2000, Words: 8},
+		{Time: at(40), Provider: "openai", Model: "whisper-1", Words: 100},
+	}
+
+	s := Summarize(records, now, 30)
+
+	if s.Count != 5 || s.Failed != 1 || s.Words != 43 || s.AudioMs != 10000 {
+		t.Errorf("totals = count %d failed %d words %d audio %d", s.Count, s.Failed, s.Words, s.AudioMs)
+	}
+
+	if len(s.Models) != 2 {
+		t.Fatalf("models = %+v", s.Models)
+	}
+	openai := s.Models[0]
+	if openai.Model != "whisper-1" || openai.Count != 4 || openai.Failed != 1 {
+		t.Errorf("openai = %+v", openai)
+	}
+	// the failed dictation does not count towards latency
+	if openai.Transcription.P50 != 1200 || openai.Transcription.P95 != 2000 {
+		t.Errorf("openai transcription = %+v", openai.Transcription)
+	}
+	if openai.Total.P95 != 2000 || openai.LLM.P50 != 500 {
+		t.Errorf("openai total = %+v, llm = %+v", openai.Total, openai.LLM)
+	}
+
+	if len(s.LLMs) != 1 || s.LLMs[0].Model != "gpt-4o-mini" || s.LLMs[0].LLM.P50 != 500 {
+		t.Errorf("llms = %+v", s.LLMs)
+	}
+
+	if len(s.Days) != 30 || s.Days[29].Date != "2025-03-14" || s.Days[29].Count != 2 || s.Days[29].Words != 30 {
+		t.Errorf("last day = %+v (of %d)", s.Days[len(s.Days)-1], len(s.Days))
+	}
+	if s.Days[28].Words != 5 || s.Days[27].Count != 1 {
+		t.Errorf("days = %+v", s.Days[27:])
+	}
+
+	if s.Trend.Current.Count != 4 || s.Trend.Current.Words != 35 {
+		t.Errorf("current trend = %+v", s.Trend.Current)
+	}
+	if s.Trend.Previous.Count != 1 || s.Trend.Previous.Words != 8 || s.Trend.Previous.LatencyMs != 2000 {
+		t.Errorf("previous trend = %+v", s.Trend.Previous)
+	}
+}
@@ -0,0 +1,211 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Latency is a p50/p95 pair in milliseconds
+type Latency struct {
+	P50 int64 `json:"p50_ms"`
+	P95 int64 `json:"p95_ms"`
+}
+
+// ModelStats aggregates the dictations made with one provider/model pair.
+// Latencies only cover dictations that were injected.
+type ModelStats struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	Count    int    `json:"count"`
+	Failed   int    `json:"failed"`
+	Words    int    `json:"words"`
+
+	RecorderStart Latency `json:"recorder_start"`
+	Transcription Latency `json:"transcription"`
+	LLM           Latency `json:"llm"`
+	Injection     Latency `json:"injection"`
+	Total         Latency `json:"total"` // stop to injected text
+}
+
+// DayStats is the usage of a single local calendar day
+type DayStats struct {
+	Date    string `json:"date"` // YYYY-MM-DD
+	Count   int    `json:"count"`
+	Words   int    `json:"words"`
+	AudioMs int64  `json:"audio_ms"`
+}
+
+// Period summarizes a span of days for trend comparisons
+type Period struct {
+	Count     int   `json:"count"`
+	Words     int   `json:"words"`
+	LatencyMs int64 `json:"latency_p50_ms"`
+}
+
+// Trend compares the last week with the week before it
+type Trend struct {
+	Current  Period `json:"current"`
+	Previous Period `json:"previous"`
+}
+
+// Summary is what hyprvoice stats reports
+type Summary struct {
+	Since   time.Time    `json:"since"`
+	Count   int          `json:"count"`
+	Failed  int          `json:"failed"`
+	Words   int          `json:"words"`
+	Chars   int          `json:"chars"`
+	AudioMs int64        `json:"audio_ms"`
+	Models  []ModelStats `json:"models"`
+	LLMs    []ModelStats `json:"llms"`
+	Days    []DayStats   `json:"days"`
+	Trend   Trend        `json:"trend"`
+}
+
+// trendDays is the length of each period compared in Trend
+const trendDays = 7
+
+// Summarize aggregates the records of the last days days (including today)
+// as of now. Days with no dictations are included so gaps show up.
+func Summarize(records []Record, now time.Time, days int) Summary {
+	if days < 1 {
+		days = 1
+	}
+	today := startOfDay(now)
+	since := today.AddDate(0, 0, -(days - 1))
+
+	s := Summary{Since: since}
+
+	models := map[[2]string][]Record{}
+	llms := map[[2]string][]Record{}
+	dayIndex := map[string]int{}
+	for d := 0; d < days; d++ {
+		date := since.AddDate(0, 0, d).Format("2006-01-02")
+		dayIndex[date] = d
+		s.Days = append(s.Days, DayStats{Date: date})
+	}
+
+	for _, r := range records {
+		if r.Time.Before(since) {
+			continue
+		}
+
+		s.Count++
+		s.Words += r.Words
+		s.Chars += r.Chars
+		s.AudioMs += r.AudioMs
+		if r.Failed {
+			s.Failed++
+		}
+
+		if i, ok := dayIndex[r.Time.In(now.Location()).Format("2006-01-02")]; ok {
+			s.Days[i].Count++
+			s.Days[i].Words += r.Words
+			s.Days[i].AudioMs += r.AudioMs
+		}
+
+		key := [2]string{r.Provider, r.Model}
+		models[key] = append(models[key], r)
+		if r.LLMModel != "" {
+			key := [2]string{r.LLMProvider, r.LLMModel}
+			llms[key] = append(llms[key], r)
+		}
+	}
+
+	s.Models = aggregate(models)
+	s.LLMs = aggregate(llms)
+	s.Trend = Trend{
+		Current:  period(records, today.AddDate(0, 0, -(trendDays-1)), today.AddDate(0, 0, 1)),
+		Previous: period(records, today.AddDate(0, 0, -(2*trendDays-1)), today.AddDate(0, 0, -(trendDays-1))),
+	}
+
+	return s
+}
+
+func aggregate(groups map[[2]string][]Record) []ModelStats {
+	result := []ModelStats{}
+	for key, records := range groups {
+		ms := ModelStats{Provider: key[0], Model: key[1], Count: len(records)}
+
+		var recorderStart, transcription, llm, injection, total []int64
+		for _, r := range records {
+			ms.Words += r.Words
+			if r.Failed {
+				ms.Failed++
+				continue
+			}
+			recorderStart = append(recorderStart, r.RecorderStartMs)
+			transcription = append(transcription, r.TranscriptionMs)
+			if r.LLMModel != "" {
+				llm = append(llm, r.LLMMs)
+			}
+			injection = append(injection, r.InjectionMs)
+			total = append(total, r.LatencyMs())
+		}
+
+		ms.RecorderStart = latency(recorderStart)
+		ms.Transcription = latency(transcription)
+		ms.LLM = latency(llm)
+		ms.Injection = latency(injection)
+		ms.Total = latency(total)
+		result = append(result, ms)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		if result[i].Provider != result[j].Provider {
+			return result[i].Provider < result[j].Provider
+		}
+		return result[i].Model < result[j].Model
+	})
+	return result
+}
+
+func period(records []Record, from, to time.Time) Period {
+	var p Period
+	var latencies []int64
+	for _, r := range records {
+		if r.Time.Before(from) || !r.Time.Before(to) {
+			continue
+		}
+		p.Count++
+		p.Words += r.Words
+		if !r.Failed {
+			latencies = append(latencies, r.LatencyMs())
+		}
+	}
+	p.LatencyMs = Percentile(latencies, 50)
+	return p
+}
+
+func latency(values []int64) Latency {
+	return Latency{P50: Percentile(values, 50), P95: Percentile(values, 95)}
+}
+
+// Percentile returns the nearest-rank percentile p (0-100) of values,
+// or 0 when values is empty
+func Percentile(values []int64, p float64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]int64, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,264 @@
+package statusbar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/bus"
+	"github.com/leonardotrapani/hyprvoice/internal/notify"
+	"github.com/leonardotrapani/hyprvoice/internal/recording"
+)
+
+// ClassStopped is used when the daemon cannot be reached
+const ClassStopped = "stopped"
+
+// retryDelay is how long Follow waits before reconnecting to the daemon
+const retryDelay = 2 * time.Second
+
+// Output is a single Waybar/i3bar custom module update
+type Output struct {
+	Text    string `json:"text"`
+	Alt     string `json:"alt"`
+	Tooltip string `json:"tooltip"`
+	Class   string `json:"class"`
+
+	// Percentage is the input level while recording, for format-icons
+	Percentage int `json:"percentage,omitempty"`
+}
+
+// labels maps pipeline status to the short text shown in the bar
+var labels = map[string]string{
+	"idle":         "",
+	"recording":    "rec",
+	"transcribing": "rec",
+	"processing":   "processing",
+	"queued":       "queued",
+	"injecting":    "typing",
+}
+
+// Tracker follows daemon status and events and renders bar output
+type Tracker struct {
+	info   bus.StatusInfo
+	start  time.Time
+	silent bool // the recording reported a silent microphone
+}
+
+// NewTracker seeds a tracker from a status response received at now
+func NewTracker(info bus.StatusInfo, now time.Time) *Tracker {
+	t := &Tracker{info: info}
+	if info.SessionSeconds > 0 {
+		t.start = now.Add(-time.Duration(info.SessionSeconds * float64(time.Second)))
+	} else if isActive(info.Status) {
+		t.start = now
+	}
+	return t
+}
+
+// Apply updates the tracker with ev and reports whether the output changed
+func (t *Tracker) Apply(ev bus.Event) bool {
+	switch ev.Type {
+	case bus.EventStatus:
+		if ev.Status == t.info.Status && ev.Queue == t.info.Queue {
+			return false
+		}
+		if t.info.Status == "idle" || t.info.Status == "" {
+			t.newSession(ev.Time)
+			t.info.LastError = ""
+		} else if isRecording(ev.Status) && !isRecording(t.info.Status) {
+			// a new dictation started while earlier ones are still processing
+			t.newSession(ev.Time)
+		}
+		t.info.Status = ev.Status
+		t.info.Queue = ev.Queue
+		return true
+	case bus.EventError:
+		t.info.LastError = ev.Message
+		return true
+	case bus.EventNotification:
+		if ev.Notification != notify.MsgMicSilent.String() {
+			return false
+		}
+		t.silent = true
+		return true
+	case bus.EventLevel:
+		// levels arrive every 100ms; they show with the next timer update
+		t.info.Level, t.info.Peak = ev.Level, ev.Peak
+	}
+	return false
+}
+
+// newSession restarts the timer and clears the input state of the last recording
+func (t *Tracker) newSession(start time.Time) {
+	t.start = start
+	t.silent = false
+	t.info.Level, t.info.Peak = 0, 0
+}
+
+// Recording reports whether the elapsed timer is running
+func (t *Tracker) Recording() bool {
+	return isRecording(t.info.Status)
+}
+
+// Output renders the current state at now
+func (t *Tracker) Output(now time.Time) Output {
+	status := t.info.Status
+	if status == "" {
+		status = "idle"
+	}
+
+	text := labels[status]
+	tooltip := fmt.Sprintf("Hyprvoice: %s", status)
+	if isRecording(status) && !t.start.IsZero() {
+		elapsed := FormatElapsed(now.Sub(t.start))
+		text = fmt.Sprintf("%s %s", text, elapsed)
+		tooltip = fmt.Sprintf("%s (%s)", tooltip, elapsed)
+	}
+
+	if t.info.Queue > 0 {
+		text = strings.TrimSpace(fmt.Sprintf("%s +%d", text, t.info.Queue))
+		tooltip = fmt.Sprintf("%s, %d more processing", tooltip, t.info.Queue)
+	}
+
+	var lines []string
+	lines = append(lines, tooltip)
+	var percentage int
+	if isRecording(status) {
+		percentage = recording.LevelPercent(t.info.Level)
+		lines = append(lines, fmt.Sprintf("Input: %.0f dBFS (peak %.0f dBFS)", recording.DBFS(t.info.Level), recording.DBFS(t.info.Peak)))
+		if t.silent {
+			lines = append(lines, "No sound from the microphone, check that it is not muted")
+		}
+	}
+	if t.info.Provider != "" {
+		lines = append(lines, fmt.Sprintf("Model: %s / %s", t.info.Provider, t.info.Model))
+	}
+	if t.info.LastError != "" {
+		lines = append(lines, fmt.Sprintf("Last error: %s", t.info.LastError))
+	}
+
+	return Output{
+		Text:       text,
+		Alt:        status,
+		Tooltip:    strings.Join(lines, "\n"),
+		Class:      status,
+		Percentage: percentage,
+	}
+}
+
+// Stopped renders the output shown while the daemon is unreachable
+func Stopped() Output {
+	return Output{
+		Text:    "",
+		Alt:     ClassStopped,
+		Tooltip: "Hyprvoice daemon not running",
+		Class:   ClassStopped,
+	}
+}
+
+// FormatElapsed formats d as m:ss
+func FormatElapsed(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	secs := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
+// Once writes a single update for the current daemon status
+func Once(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	resp, err := bus.Call(bus.Request{Cmd: bus.CmdStatus})
+	if err != nil || resp.Status == nil {
+		return enc.Encode(Stopped())
+	}
+	return enc.Encode(NewTracker(*resp.Status, time.Now()).Output(time.Now()))
+}
+
+// Follow writes an update on every status change, and once per second while
+// recording with the elapsed time and input level, until ctx is cancelled. It reconnects when the daemon restarts.
+func Follow(ctx context.Context, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for {
+		if err := followOnce(ctx, enc); err != nil && ctx.Err() == nil {
+			if err := enc.Encode(Stopped()); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(retryDelay):
+		}
+	}
+}
+
+func followOnce(ctx context.Context, enc *json.Encoder) error {
+	resp, err := bus.Call(bus.Request{Cmd: bus.CmdStatus})
+	if err != nil {
+		return err
+	}
+	if resp.Status == nil {
+		return fmt.Errorf("daemon returned no status: %s", resp.Error)
+	}
+
+	tracker := NewTracker(*resp.Status, time.Now())
+	if err := enc.Encode(tracker.Output(time.Now())); err != nil {
+		return err
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events := make(chan bus.Event)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- bus.Subscribe(subCtx, func(ev bus.Event) error {
+			select {
+			case events <- ev:
+				return nil
+			case <-subCtx.Done():
+				return subCtx.Err()
+			}
+		})
+	}()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case ev := <-events:
+			if tracker.Apply(ev) {
+				if err := enc.Encode(tracker.Output(time.Now())); err != nil {
+					return err
+				}
+			}
+		case <-ticker.C:
+			if tracker.Recording() {
+				if err := enc.Encode(tracker.Output(time.Now())); err != nil {
+					return err
+				}
+			}
+		case err := <-errCh:
+			if err == nil {
+				err = fmt.Errorf("event stream closed")
+			}
+			return err
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func isActive(status string) bool {
+	return status != "" && status != "idle"
+}
+
+func isRecording(status string) bool {
+	return status == "recording" || status == "transcribing"
+}
@@ -0,0 +1,147 @@
+package statusbar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/bus"
+)
+
+func TestFormatElapsed(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0:00"},
+		{9 * time.Second, "0:09"},
+		{75 * time.Second, "1:15"},
+		{10*time.Minute + 3*time.Second, "10:03"},
+		{-time.Second, "0:00"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatElapsed(tt.d); got != tt.want {
+			t.Errorf("FormatElapsed(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestTracker_Output(t *testing.T) {
+	now := time.Now()
+
+	t.Run("idle", func(t *testing.T) {
+		tr := NewTracker(bus.StatusInfo{Status: "idle", Provider: "openai", Model: "whisper-1"}, now)
+		out := tr.Output(now)
+		if out.Class != "idle" || out.Alt != "idle" || out.Text != "" {
+			t.Errorf("Output() = %+v", out)
+		}
+		if !strings.Contains(out.Tooltip, "openai / whisper-1") {
+			t.Errorf("tooltip = %q, want provider/model", out.Tooltip)
+		}
+	})
+
+	t.Run("recording with elapsed timer", func(t *testing.T) {
+		tr := NewTracker(bus.StatusInfo{Status: "transcribing", SessionSeconds: 12}, now)
+		out := tr.Output(now)
+		if out.Class != "transcribing" || out.Text != "rec 0:12" {
+			t.Errorf("Output() = %+v", out)
+		}
+		if !tr.Recording() {
+			t.Errorf("Recording() = false, want true")
+		}
+	})
+
+	t.Run("queued dictations", func(t *testing.T) {
+		tr := NewTracker(bus.StatusInfo{Status: "recording", SessionSeconds: 3, Queue: 2}, now)
+		out := tr.Output(now)
+		if out.Text != "rec 0:03 +2" || !strings.Contains(out.Tooltip, "2 more processing") {
+			t.Errorf("Output() = %+v", out)
+		}
+	})
+
+	t.Run("input level while recording", func(t *testing.T) {
+		tr := NewTracker(bus.StatusInfo{Status: "recording", Level: 0.1, Peak: 1}, now)
+		out := tr.Output(now)
+		if out.Percentage != 67 || !strings.Contains(out.Tooltip, "Input: -20 dBFS (peak 0 dBFS)") {
+			t.Errorf("Output() = %+v", out)
+		}
+	})
+
+	t.Run("last error in tooltip", func(t *testing.T) {
+		tr := NewTracker(bus.StatusInfo{Status: "idle", LastError: "Injection Error: boom"}, now)
+		if out := tr.Output(now); !strings.Contains(out.Tooltip, "Last error: Injection Error: boom") {
+			t.Errorf("tooltip = %q", out.Tooltip)
+		}
+	})
+}
+
+func TestTracker_Apply(t *testing.T) {
+	start := time.Now()
+	tr := NewTracker(bus.StatusInfo{Status: "idle", LastError: "old"}, start)
+
+	if tr.Apply(bus.Event{Type: bus.EventStatus, Status: "idle", Time: start}) {
+		t.Errorf("Apply() same status reported a change")
+	}
+	if tr.Apply(bus.Event{Type: bus.EventTranscript, Text: "hi", Time: start}) {
+		t.Errorf("Apply() transcript reported a change")
+	}
+
+	if !tr.Apply(bus.Event{Type: bus.EventStatus, Status: "recording", Time: start}) {
+		t.Fatalf("Apply() status change not reported")
+	}
+	out := tr.Output(start.Add(5 * time.Second))
+	if out.Class != "recording" || out.Text != "rec 0:05" {
+		t.Errorf("Output() = %+v", out)
+	}
+	if strings.Contains(out.Tooltip, "old") {
+		t.Errorf("stale error kept after new session: %q", out.Tooltip)
+	}
+
+	for _, status := range []string{"transcribing", "processing", "injecting", "idle"} {
+		tr.Apply(bus.Event{Type: bus.EventStatus, Status: status, Time: start})
+		if got := tr.Output(start).Class; got != status {
+			t.Errorf("class = %q, want %q", got, status)
+		}
+	}
+
+	// a new dictation while the previous one is processing restarts the timer
+	tr.Apply(bus.Event{Type: bus.EventStatus, Status: "processing", Time: start})
+	later := start.Add(time.Minute)
+	if !tr.Apply(bus.Event{Type: bus.EventStatus, Status: "recording", Queue: 1, Time: later}) {
+		t.Fatalf("Apply() overlapping session not reported")
+	}
+	if out := tr.Output(later.Add(2 * time.Second)); out.Text != "rec 0:02 +1" {
+		t.Errorf("Output() = %+v", out)
+	}
+	if !tr.Apply(bus.Event{Type: bus.EventStatus, Status: "recording", Time: later}) {
+		t.Errorf("Apply() queue change not reported")
+	}
+
+	if tr.Apply(bus.Event{Type: bus.EventLevel, Level: 0.01, Time: later}) {
+		t.Errorf("Apply() level event reported a change")
+	}
+	if out := tr.Output(later); out.Percentage != 33 {
+		t.Errorf("percentage = %d, want the level of the last event", out.Percentage)
+	}
+	if !tr.Apply(bus.Event{Type: bus.EventNotification, Notification: "mic_silent", Time: later}) {
+		t.Errorf("Apply() silent microphone not reported")
+	}
+	if out := tr.Output(later); !strings.Contains(out.Tooltip, "No sound from the microphone") {
+		t.Errorf("tooltip = %q", out.Tooltip)
+	}
+
+	if !tr.Apply(bus.Event{Type: bus.EventError, Message: "Transcription Error: timeout"}) {
+		t.Errorf("Apply() error event not reported")
+	}
+	if out := tr.Output(start); !strings.Contains(out.Tooltip, "timeout") {
+		t.Errorf("tooltip = %q", out.Tooltip)
+	}
+}
+
+func TestStopped(t *testing.T) {
+	out := Stopped()
+	if out.Class != ClassStopped || out.Text != "" {
+		t.Errorf("Stopped() = %+v", out)
+	}
+}
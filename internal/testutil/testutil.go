@@ -11,9 +11,11 @@ import (
 	"time"
 
 	"github.com/leonardotrapani/hyprvoice/internal/config"
+	"github.com/leonardotrapani/hyprvoice/internal/history"
 	"github.com/leonardotrapani/hyprvoice/internal/injection"
 	"github.com/leonardotrapani/hyprvoice/internal/llm"
 	"github.com/leonardotrapani/hyprvoice/internal/recording"
+	"github.com/leonardotrapani/hyprvoice/internal/stats"
 	"github.com/leonardotrapani/hyprvoice/internal/transcriber"
 )
 
@@ -28,6 +30,8 @@ func TestConfig() *config.Config {
 			Device:            "",
 			ChannelBufferSize: 30,
 			Timeout:           5 * time.Minute,
+			OnTimeout:         config.PolicyInject,
+			OnShutdown:        config.PolicySave,
 		},
 		Transcription: config.TranscriptionConfig{
 			Provider: "openai",
@@ -191,6 +195,9 @@ func CaptureOutput(t *testing.T, fn func()) string {
 type MockRecorder struct {
 	Frames     []recording.AudioFrame
 	StartError error
+	// EndAfterFrames closes the frame channel once Frames are sent, like a
+	// file backend at the end of the file
+	EndAfterFrames bool
 
 	mu        sync.Mutex
 	recording atomic.Bool
@@ -230,6 +237,9 @@ func (m *MockRecorder) Start(ctx context.Context) (<-chan recording.AudioFrame,
 			case frameCh <- frame:
 			}
 		}
+		if m.EndAfterFrames {
+			return
+		}
 
 		// keep channel open until stopped
 		select {
@@ -365,6 +375,56 @@ func (m *MockLLMAdapter) Process(ctx context.Context, text string) (string, erro
 	return m.ProcessedText, nil
 }
 
+// MockHistoryStore implements history.Store in memory for testing
+type MockHistoryStore struct {
+	mu      sync.Mutex
+	entries []history.Entry
+}
+
+func NewMockHistoryStore() *MockHistoryStore {
+	return &MockHistoryStore{}
+}
+
+func (m *MockHistoryStore) Append(e history.Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+func (m *MockHistoryStore) List() ([]history.Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]history.Entry, len(m.entries))
+	copy(result, m.entries)
+	return result, nil
+}
+
+// MockStatsStore implements stats.Store in memory for testing
+type MockStatsStore struct {
+	mu      sync.Mutex
+	records []stats.Record
+}
+
+func NewMockStatsStore() *MockStatsStore {
+	return &MockStatsStore{}
+}
+
+func (m *MockStatsStore) Append(r stats.Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, r)
+	return nil
+}
+
+func (m *MockStatsStore) List() ([]stats.Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]stats.Record, len(m.records))
+	copy(result, m.records)
+	return result, nil
+}
+
 // Factory helpers for pipeline testing
 
 // MockRecorderFactory returns a factory that creates the given mock recorder
@@ -394,3 +454,17 @@ func MockLLMAdapterFactory(mock *MockLLMAdapter) func(cfg llm.Config) (llm.Adapt
 		return mock, nil
 	}
 }
+
+// MockHistoryFactory returns a factory that creates the given mock history store
+func MockHistoryFactory(mock *MockHistoryStore) func(cfg history.Config) (history.Store, error) {
+	return func(cfg history.Config) (history.Store, error) {
+		return mock, nil
+	}
+}
+
+// MockStatsFactory returns a factory that creates the given mock stats store
+func MockStatsFactory(mock *MockStatsStore) func(cfg stats.Config) (stats.Store, error) {
+	return func(cfg stats.Config) (stats.Store, error) {
+		return mock, nil
+	}
+}
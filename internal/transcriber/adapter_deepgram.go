@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"strings"
@@ -12,9 +11,12 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 	"github.com/leonardotrapani/hyprvoice/internal/provider"
 )
 
+var deepgramLogger = logger.With("provider", "deepgram")
+
 // DeepgramAdapter implements StreamingAdapter for Deepgram real-time transcription
 type DeepgramAdapter struct {
 	endpoint  *provider.EndpointConfig
@@ -126,7 +128,7 @@ func (a *DeepgramAdapter) Start(ctx context.Context, lang string) error {
 	a.wg.Add(1)
 	go a.readLoop()
 
-	log.Printf("deepgram: connected, model=%s, language=%s", a.model, a.language)
+	deepgramLogger.Info("Connected", "model", a.model, "language", a.language)
 	return nil
 }
 
@@ -140,11 +142,11 @@ func (a *DeepgramAdapter) connectLocked() error {
 	headers := http.Header{}
 	headers.Set("Authorization", "Token "+a.apiKey)
 
-	log.Printf("deepgram: connecting to %s", wsURL)
+	deepgramLogger.Debug("Connecting", "url", wsURL)
 	conn, resp, err := websocket.DefaultDialer.DialContext(a.ctx, wsURL, headers)
 	if err != nil {
 		if resp != nil {
-			log.Printf("deepgram: dial failed with status %d", resp.StatusCode)
+			deepgramLogger.Warn("Dial failed", "status", resp.StatusCode)
 		}
 		return fmt.Errorf("websocket dial: %w", err)
 	}
@@ -169,7 +171,7 @@ func (a *DeepgramAdapter) reconnect() bool {
 			if attempt-1 >= len(a.retryDelays) {
 				delay = a.retryDelays[len(a.retryDelays)-1]
 			}
-			log.Printf("deepgram: reconnect attempt %d/%d after %v", attempt+1, a.maxRetries, delay)
+			deepgramLogger.Info("Reconnecting", "attempt", attempt+1, "max", a.maxRetries, "delay", delay)
 
 			select {
 			case <-a.ctx.Done():
@@ -177,7 +179,7 @@ func (a *DeepgramAdapter) reconnect() bool {
 			case <-time.After(delay):
 			}
 		} else {
-			log.Printf("deepgram: reconnect attempt %d/%d", attempt+1, a.maxRetries)
+			deepgramLogger.Info("Reconnecting", "attempt", attempt+1, "max", a.maxRetries)
 		}
 
 		a.mu.Lock()
@@ -191,7 +193,7 @@ func (a *DeepgramAdapter) reconnect() bool {
 		a.mu.Unlock()
 
 		if err == nil {
-			log.Printf("deepgram: reconnected successfully")
+			deepgramLogger.Info("Reconnected")
 			// notify caller of brief interruption
 			select {
 			case a.resultsCh <- TranscriptionResult{Error: fmt.Errorf("connection interrupted, reconnected"), IsFinal: false}:
@@ -200,7 +202,7 @@ func (a *DeepgramAdapter) reconnect() bool {
 			return true
 		}
 
-		log.Printf("deepgram: reconnect failed: %v", err)
+		deepgramLogger.Warn("Reconnect failed", "err", err)
 	}
 
 	return false
@@ -294,7 +296,7 @@ func (a *DeepgramAdapter) readLoop() {
 			}
 
 			// attempt reconnection
-			log.Printf("deepgram: read error: %v, attempting reconnection", err)
+			deepgramLogger.Warn("Read failed, reconnecting", "err", err)
 			if !a.reconnect() {
 				a.resultsCh <- TranscriptionResult{Error: fmt.Errorf("websocket read: %w, reconnection failed", err)}
 				return
@@ -305,7 +307,7 @@ func (a *DeepgramAdapter) readLoop() {
 		// parse message
 		var resp deepgramWSResponse
 		if err := json.Unmarshal(message, &resp); err != nil {
-			log.Printf("deepgram: parse error: %v", err)
+			deepgramLogger.Warn("Failed to parse message", "err", err)
 			continue
 		}
 
@@ -313,8 +315,7 @@ func (a *DeepgramAdapter) readLoop() {
 		switch resp.Type {
 		case "Metadata":
 			if resp.Metadata != nil {
-				log.Printf("deepgram: session started, request_id=%s, model=%s",
-					resp.Metadata.RequestID, resp.Metadata.ModelInfo.Name)
+				deepgramLogger.Debug("Session started", "request_id", resp.Metadata.RequestID, "model", resp.Metadata.ModelInfo.Name)
 			}
 
 		case "Results":
@@ -324,7 +325,7 @@ func (a *DeepgramAdapter) readLoop() {
 				if transcript != "" {
 					isFinal := resp.IsFinal || resp.SpeechFinal
 					if isFinal {
-						log.Printf("deepgram: final: %q", transcript)
+						deepgramLogger.Debug("Final result", logging.Text("text", transcript))
 						// signal finalization (non-blocking)
 						select {
 						case a.finalizeDone <- struct{}{}:
@@ -341,18 +342,18 @@ func (a *DeepgramAdapter) readLoop() {
 				if resp.Error.Description != "" {
 					errMsg = fmt.Sprintf("%s: %s", errMsg, resp.Error.Description)
 				}
-				log.Printf("deepgram: error: %s", errMsg)
+				deepgramLogger.Error("Provider error", "error", errMsg)
 				a.resultsCh <- TranscriptionResult{Error: fmt.Errorf("deepgram: %s", errMsg)}
 			}
 
 		case "UtteranceEnd":
-			log.Printf("deepgram: utterance end detected")
+			deepgramLogger.Debug("Utterance end detected")
 
 		case "SpeechStarted":
-			log.Printf("deepgram: speech started")
+			deepgramLogger.Debug("Speech started")
 
 		default:
-			log.Printf("deepgram: unknown message type: %s", resp.Type)
+			deepgramLogger.Debug("Unknown message type", "type", resp.Type)
 		}
 	}
 }
@@ -386,7 +387,7 @@ func (a *DeepgramAdapter) SendChunk(audio []byte) error {
 
 	if err != nil {
 		// attempt reconnection
-		log.Printf("deepgram: write error: %v, attempting reconnection", err)
+		deepgramLogger.Warn("Write failed, reconnecting", "err", err)
 		if a.reconnect() {
 			// retry the chunk after reconnection
 			a.mu.Lock()
@@ -440,19 +441,19 @@ func (a *DeepgramAdapter) Finalize(ctx context.Context) error {
 	a.mu.Unlock()
 
 	if err != nil {
-		log.Printf("deepgram: finalize write error: %v", err)
+		deepgramLogger.Error("Failed to send finalize message", "err", err)
 		return fmt.Errorf("finalize write: %w", err)
 	}
 
-	log.Printf("deepgram: sent CloseStream, waiting for final transcript")
+	deepgramLogger.Debug("Sent CloseStream, waiting for final transcript")
 
 	// wait for final result or timeout
 	select {
 	case <-a.finalizeDone:
-		log.Printf("deepgram: finalize complete")
+		deepgramLogger.Debug("Finalize complete")
 		return nil
 	case <-ctx.Done():
-		log.Printf("deepgram: finalize timeout")
+		deepgramLogger.Warn("Finalize timed out")
 		return ctx.Err()
 	case <-a.ctx.Done():
 		return a.ctx.Err()
@@ -492,6 +493,6 @@ func (a *DeepgramAdapter) Close() error {
 	// wait for reader to finish
 	a.wg.Wait()
 
-	log.Printf("deepgram: closed")
+	deepgramLogger.Debug("Closed")
 	return nil
 }
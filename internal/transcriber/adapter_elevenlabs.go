@@ -6,11 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"mime/multipart"
 	"net/http"
 	"time"
 
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 	"github.com/leonardotrapani/hyprvoice/internal/provider"
 )
 
@@ -110,14 +110,14 @@ func (a *ElevenLabsAdapter) Transcribe(ctx context.Context, audioData []byte) (s
 	duration := time.Since(start)
 
 	if err != nil {
-		log.Printf("elevenlabs-adapter: API call failed after %v: %v", duration, err)
+		logger.Error("API call failed", "provider", "elevenlabs", "duration", duration, "err", err)
 		return "", fmt.Errorf("elevenlabs request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		log.Printf("elevenlabs-adapter: API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+		logger.Error("API returned an error", "provider", "elevenlabs", "status", resp.StatusCode, "body", string(bodyBytes))
 		return "", fmt.Errorf("elevenlabs API status %d: %s", resp.StatusCode, string(bodyBytes))
 	}
 
@@ -126,6 +126,6 @@ func (a *ElevenLabsAdapter) Transcribe(ctx context.Context, audioData []byte) (s
 		return "", fmt.Errorf("decode response: %w", err)
 	}
 
-	log.Printf("elevenlabs-adapter: transcribed %d bytes in %v: %q", len(audioData), duration, result.Text)
+	logger.Info("Transcribed audio", "provider", "elevenlabs", "bytes", len(audioData), "duration", duration, logging.Text("text", result.Text))
 	return result.Text, nil
 }
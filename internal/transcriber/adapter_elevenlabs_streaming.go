@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"strings"
@@ -14,9 +13,12 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 	"github.com/leonardotrapani/hyprvoice/internal/provider"
 )
 
+var elevenLabsStreamingLogger = logger.With("provider", "elevenlabs-streaming")
+
 // default retry delays for reconnection (exponential backoff: 1s, 2s, 4s)
 var defaultRetryDelays = []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second}
 
@@ -108,7 +110,7 @@ func (a *ElevenLabsStreamingAdapter) Start(ctx context.Context, lang string) err
 	a.wg.Add(1)
 	go a.readLoop()
 
-	log.Printf("elevenlabs-streaming: connected, model=%s, language=%s", a.model, a.language)
+	elevenLabsStreamingLogger.Info("Connected", "model", a.model, "language", a.language)
 	return nil
 }
 
@@ -122,11 +124,11 @@ func (a *ElevenLabsStreamingAdapter) connectLocked() error {
 	headers := http.Header{}
 	headers.Set("xi-api-key", a.apiKey)
 
-	log.Printf("elevenlabs-streaming: connecting to %s", wsURL)
+	elevenLabsStreamingLogger.Debug("Connecting", "url", wsURL)
 	conn, resp, err := websocket.DefaultDialer.DialContext(a.ctx, wsURL, headers)
 	if err != nil {
 		if resp != nil {
-			log.Printf("elevenlabs-streaming: dial failed with status %d", resp.StatusCode)
+			elevenLabsStreamingLogger.Warn("Dial failed", "status", resp.StatusCode)
 		}
 		return fmt.Errorf("websocket dial: %w", err)
 	}
@@ -152,7 +154,7 @@ func (a *ElevenLabsStreamingAdapter) reconnect() bool {
 			if attempt-1 >= len(a.retryDelays) {
 				delay = a.retryDelays[len(a.retryDelays)-1]
 			}
-			log.Printf("elevenlabs-streaming: reconnect attempt %d/%d after %v", attempt+1, a.maxRetries, delay)
+			elevenLabsStreamingLogger.Info("Reconnecting", "attempt", attempt+1, "max", a.maxRetries, "delay", delay)
 
 			select {
 			case <-a.ctx.Done():
@@ -160,7 +162,7 @@ func (a *ElevenLabsStreamingAdapter) reconnect() bool {
 			case <-time.After(delay):
 			}
 		} else {
-			log.Printf("elevenlabs-streaming: reconnect attempt %d/%d", attempt+1, a.maxRetries)
+			elevenLabsStreamingLogger.Info("Reconnecting", "attempt", attempt+1, "max", a.maxRetries)
 		}
 
 		a.mu.Lock()
@@ -174,7 +176,7 @@ func (a *ElevenLabsStreamingAdapter) reconnect() bool {
 		a.mu.Unlock()
 
 		if err == nil {
-			log.Printf("elevenlabs-streaming: reconnected successfully")
+			elevenLabsStreamingLogger.Info("Reconnected")
 			// notify caller of brief interruption
 			select {
 			case a.resultsCh <- TranscriptionResult{Error: fmt.Errorf("connection interrupted, reconnected"), IsFinal: false}:
@@ -183,7 +185,7 @@ func (a *ElevenLabsStreamingAdapter) reconnect() bool {
 			return true
 		}
 
-		log.Printf("elevenlabs-streaming: reconnect failed: %v", err)
+		elevenLabsStreamingLogger.Warn("Reconnect failed", "err", err)
 	}
 
 	return false
@@ -254,7 +256,7 @@ func (a *ElevenLabsStreamingAdapter) readLoop() {
 			}
 
 			// attempt reconnection
-			log.Printf("elevenlabs-streaming: read error: %v, attempting reconnection", err)
+			elevenLabsStreamingLogger.Warn("Read failed, reconnecting", "err", err)
 			if !a.reconnect() {
 				a.resultsCh <- TranscriptionResult{Error: fmt.Errorf("websocket read: %w, reconnection failed", err)}
 				return
@@ -265,14 +267,14 @@ func (a *ElevenLabsStreamingAdapter) readLoop() {
 		// parse message
 		var msg elevenLabsWSMessage
 		if err := json.Unmarshal(message, &msg); err != nil {
-			log.Printf("elevenlabs-streaming: parse error: %v", err)
+			elevenLabsStreamingLogger.Warn("Failed to parse message", "err", err)
 			continue
 		}
 
 		// handle different message types
 		switch msg.MessageType {
 		case "session_started":
-			log.Printf("elevenlabs-streaming: session started, id=%s", msg.SessionID)
+			elevenLabsStreamingLogger.Debug("Session started", "id", msg.SessionID)
 
 		case "partial_transcript":
 			// interim result
@@ -282,7 +284,7 @@ func (a *ElevenLabsStreamingAdapter) readLoop() {
 
 		case "committed_transcript", "committed_transcript_with_timestamps":
 			// final result
-			log.Printf("elevenlabs-streaming: committed: %q", msg.Text)
+			elevenLabsStreamingLogger.Debug("Committed", logging.Text("text", msg.Text))
 			if msg.Text != "" {
 				a.resultsCh <- TranscriptionResult{Text: msg.Text, IsFinal: true}
 			}
@@ -301,7 +303,7 @@ func (a *ElevenLabsStreamingAdapter) readLoop() {
 			if errMsg == "" {
 				errMsg = msg.MessageType
 			}
-			log.Printf("elevenlabs-streaming: error: %s", errMsg)
+			elevenLabsStreamingLogger.Error("Provider error", "error", errMsg)
 			err := fmt.Errorf("elevenlabs: %s", errMsg)
 			if isElevenLabsFatalMessageType(msg.MessageType) {
 				a.handleFatalError(err)
@@ -310,7 +312,7 @@ func (a *ElevenLabsStreamingAdapter) readLoop() {
 			a.emitResultError(err)
 
 		default:
-			log.Printf("elevenlabs-streaming: unknown message type: %s payload=%s", msg.MessageType, strings.TrimSpace(string(message)))
+			elevenLabsStreamingLogger.Debug("Unknown message type", "type", msg.MessageType, logging.Text("payload", strings.TrimSpace(string(message))))
 		}
 	}
 }
@@ -324,7 +326,7 @@ func (a *ElevenLabsStreamingAdapter) emitResultError(err error) {
 
 func (a *ElevenLabsStreamingAdapter) handleFatalError(err error) {
 	fatalErr := NewFatalTranscriptionError(err)
-	log.Printf("elevenlabs-streaming: fatal error: %v", err)
+	elevenLabsStreamingLogger.Error("Fatal error", "err", err)
 	a.emitResultError(fatalErr)
 	a.closeConn()
 	if a.cancel != nil {
@@ -427,7 +429,7 @@ func (a *ElevenLabsStreamingAdapter) SendChunk(audio []byte) error {
 
 	if err != nil {
 		// attempt reconnection
-		log.Printf("elevenlabs-streaming: write error: %v, attempting reconnection", err)
+		elevenLabsStreamingLogger.Warn("Write failed, reconnecting", "err", err)
 		if a.reconnect() {
 			// retry the chunk after reconnection
 			a.mu.Lock()
@@ -482,19 +484,19 @@ func (a *ElevenLabsStreamingAdapter) Finalize(ctx context.Context) error {
 	a.mu.Unlock()
 
 	if err != nil {
-		log.Printf("elevenlabs-streaming: finalize write error: %v", err)
+		elevenLabsStreamingLogger.Error("Failed to send finalize message", "err", err)
 		return fmt.Errorf("finalize write: %w", err)
 	}
 
-	log.Printf("elevenlabs-streaming: sent commit, waiting for final transcript")
+	elevenLabsStreamingLogger.Debug("Sent commit, waiting for final transcript")
 
 	// wait for committed_transcript or timeout
 	select {
 	case <-a.commitDone:
-		log.Printf("elevenlabs-streaming: finalize complete")
+		elevenLabsStreamingLogger.Debug("Finalize complete")
 		return nil
 	case <-ctx.Done():
-		log.Printf("elevenlabs-streaming: finalize timeout")
+		elevenLabsStreamingLogger.Warn("Finalize timed out")
 		return ctx.Err()
 	case <-a.ctx.Done():
 		return a.ctx.Err()
@@ -531,6 +533,6 @@ func (a *ElevenLabsStreamingAdapter) Close() error {
 	// wait for reader to finish
 	a.wg.Wait()
 
-	log.Printf("elevenlabs-streaming: closed")
+	elevenLabsStreamingLogger.Debug("Closed")
 	return nil
 }
@@ -4,10 +4,10 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 	"github.com/leonardotrapani/hyprvoice/internal/provider"
 	"github.com/sashabaranov/go-openai"
 )
@@ -80,10 +80,10 @@ func (a *OpenAIAdapter) Transcribe(ctx context.Context, audioData []byte) (strin
 	duration := time.Since(start)
 
 	if err != nil {
-		log.Printf("%s-adapter: API call failed after %v: %v", a.providerName, duration, err)
+		logger.Error("API call failed", "provider", a.providerName, "duration", duration, "err", err)
 		return "", fmt.Errorf("%s transcription: %w", a.providerName, err)
 	}
 
-	log.Printf("%s-adapter: transcribed %d bytes in %v: %q", a.providerName, len(audioData), duration, resp.Text)
+	logger.Info("Transcribed audio", "provider", a.providerName, "bytes", len(audioData), "duration", duration, logging.Text("text", resp.Text))
 	return resp.Text, nil
 }
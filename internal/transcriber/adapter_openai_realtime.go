@@ -5,7 +5,6 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"strings"
@@ -13,9 +12,12 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 	"github.com/leonardotrapani/hyprvoice/internal/provider"
 )
 
+var openAIRealtimeLogger = logger.With("provider", "openai-realtime")
+
 // OpenAIRealtimeAdapter implements StreamingAdapter for OpenAI Realtime API transcription
 type OpenAIRealtimeAdapter struct {
 	endpoint  *provider.EndpointConfig
@@ -148,7 +150,7 @@ func (a *OpenAIRealtimeAdapter) Start(ctx context.Context, lang string) error {
 	a.wg.Add(1)
 	go a.readLoop()
 
-	log.Printf("openai-realtime: connected, model=%s, language=%s", a.model, a.language)
+	openAIRealtimeLogger.Info("Connected", "model", a.model, "language", a.language)
 	return nil
 }
 
@@ -163,11 +165,11 @@ func (a *OpenAIRealtimeAdapter) connectLocked() error {
 	headers.Set("Authorization", "Bearer "+a.apiKey)
 	headers.Set("OpenAI-Beta", "realtime=v1")
 
-	log.Printf("openai-realtime: connecting to %s", wsURL)
+	openAIRealtimeLogger.Debug("Connecting", "url", wsURL)
 	conn, resp, err := websocket.DefaultDialer.DialContext(a.ctx, wsURL, headers)
 	if err != nil {
 		if resp != nil {
-			log.Printf("openai-realtime: dial failed with status %d", resp.StatusCode)
+			openAIRealtimeLogger.Warn("Dial failed", "status", resp.StatusCode)
 		}
 		return fmt.Errorf("websocket dial: %w", err)
 	}
@@ -234,7 +236,7 @@ func (a *OpenAIRealtimeAdapter) reconnect() bool {
 			if attempt-1 >= len(a.retryDelays) {
 				delay = a.retryDelays[len(a.retryDelays)-1]
 			}
-			log.Printf("openai-realtime: reconnect attempt %d/%d after %v", attempt+1, a.maxRetries, delay)
+			openAIRealtimeLogger.Info("Reconnecting", "attempt", attempt+1, "max", a.maxRetries, "delay", delay)
 
 			select {
 			case <-a.ctx.Done():
@@ -242,7 +244,7 @@ func (a *OpenAIRealtimeAdapter) reconnect() bool {
 			case <-time.After(delay):
 			}
 		} else {
-			log.Printf("openai-realtime: reconnect attempt %d/%d", attempt+1, a.maxRetries)
+			openAIRealtimeLogger.Info("Reconnecting", "attempt", attempt+1, "max", a.maxRetries)
 		}
 
 		a.mu.Lock()
@@ -256,7 +258,7 @@ func (a *OpenAIRealtimeAdapter) reconnect() bool {
 		a.mu.Unlock()
 
 		if err == nil {
-			log.Printf("openai-realtime: reconnected successfully")
+			openAIRealtimeLogger.Info("Reconnected")
 			// notify caller of brief interruption
 			select {
 			case a.resultsCh <- TranscriptionResult{Error: fmt.Errorf("connection interrupted, reconnected"), IsFinal: false}:
@@ -265,7 +267,7 @@ func (a *OpenAIRealtimeAdapter) reconnect() bool {
 			return true
 		}
 
-		log.Printf("openai-realtime: reconnect failed: %v", err)
+		openAIRealtimeLogger.Warn("Reconnect failed", "err", err)
 	}
 
 	return false
@@ -324,7 +326,7 @@ func (a *OpenAIRealtimeAdapter) readLoop() {
 			}
 
 			// attempt reconnection
-			log.Printf("openai-realtime: read error: %v, attempting reconnection", err)
+			openAIRealtimeLogger.Warn("Read failed, reconnecting", "err", err)
 			if !a.reconnect() {
 				a.resultsCh <- TranscriptionResult{Error: fmt.Errorf("websocket read: %w, reconnection failed", err)}
 				return
@@ -335,7 +337,7 @@ func (a *OpenAIRealtimeAdapter) readLoop() {
 		// parse message
 		var event openaiRealtimeServerEvent
 		if err := json.Unmarshal(message, &event); err != nil {
-			log.Printf("openai-realtime: parse error: %v", err)
+			openAIRealtimeLogger.Warn("Failed to parse message", "err", err)
 			continue
 		}
 
@@ -349,11 +351,11 @@ func (a *OpenAIRealtimeAdapter) handleEvent(event openaiRealtimeServerEvent) {
 	switch event.Type {
 	case "session.created":
 		if event.Session != nil {
-			log.Printf("openai-realtime: session created, id=%s, model=%s", event.Session.ID, event.Session.Model)
+			openAIRealtimeLogger.Debug("Session created", "id", event.Session.ID, "model", event.Session.Model)
 		}
 
 	case "session.updated":
-		log.Printf("openai-realtime: session updated")
+		openAIRealtimeLogger.Debug("Session updated")
 
 	case "error":
 		if event.Error != nil {
@@ -361,19 +363,19 @@ func (a *OpenAIRealtimeAdapter) handleEvent(event openaiRealtimeServerEvent) {
 			if event.Error.Code != "" {
 				errMsg = fmt.Sprintf("%s: %s", event.Error.Code, errMsg)
 			}
-			log.Printf("openai-realtime: error: %s", errMsg)
+			openAIRealtimeLogger.Error("Provider error", "error", errMsg)
 			a.resultsCh <- TranscriptionResult{Error: fmt.Errorf("openai: %s", errMsg)}
 		}
 
 	case "input_audio_buffer.speech_started":
-		log.Printf("openai-realtime: speech started")
+		openAIRealtimeLogger.Debug("Speech started")
 
 	case "input_audio_buffer.speech_stopped":
-		log.Printf("openai-realtime: speech stopped, item_id=%s", event.ItemID)
+		openAIRealtimeLogger.Debug("Speech stopped", "item_id", event.ItemID)
 		a.currentItemID = event.ItemID
 
 	case "input_audio_buffer.committed":
-		log.Printf("openai-realtime: audio committed, item_id=%s", event.ItemID)
+		openAIRealtimeLogger.Debug("Audio committed", "item_id", event.ItemID)
 		a.currentItemID = event.ItemID
 
 	case "conversation.item.input_audio_transcription.delta":
@@ -384,7 +386,7 @@ func (a *OpenAIRealtimeAdapter) handleEvent(event openaiRealtimeServerEvent) {
 
 	case "conversation.item.input_audio_transcription.completed":
 		// final transcription result
-		log.Printf("openai-realtime: transcription completed: %q", event.Transcript)
+		openAIRealtimeLogger.Debug("Transcription completed", logging.Text("text", event.Transcript))
 		if event.Transcript != "" {
 			a.resultsCh <- TranscriptionResult{Text: event.Transcript, IsFinal: true}
 		}
@@ -395,19 +397,19 @@ func (a *OpenAIRealtimeAdapter) handleEvent(event openaiRealtimeServerEvent) {
 		}
 
 	case "conversation.item.input_audio_transcription.failed":
-		log.Printf("openai-realtime: transcription failed for item %s", event.ItemID)
+		openAIRealtimeLogger.Warn("Transcription failed", "item_id", event.ItemID)
 		if event.Error != nil {
 			a.resultsCh <- TranscriptionResult{Error: fmt.Errorf("transcription failed: %s", event.Error.Message)}
 		}
 
 	case "conversation.item.created", "conversation.item.added":
-		log.Printf("openai-realtime: conversation item created/added")
+		openAIRealtimeLogger.Debug("Conversation item added")
 
 	case "rate_limits.updated":
 		// ignore rate limit updates
 
 	default:
-		log.Printf("openai-realtime: unhandled event type: %s", event.Type)
+		openAIRealtimeLogger.Debug("Unhandled event type", "type", event.Type)
 	}
 }
 
@@ -453,7 +455,7 @@ func (a *OpenAIRealtimeAdapter) SendChunk(audio []byte) error {
 
 	if err != nil {
 		// attempt reconnection
-		log.Printf("openai-realtime: write error: %v, attempting reconnection", err)
+		openAIRealtimeLogger.Warn("Write failed, reconnecting", "err", err)
 		if a.reconnect() {
 			// retry the chunk after reconnection
 			a.mu.Lock()
@@ -548,19 +550,19 @@ func (a *OpenAIRealtimeAdapter) Finalize(ctx context.Context) error {
 	a.mu.Unlock()
 
 	if err != nil {
-		log.Printf("openai-realtime: finalize write error: %v", err)
+		openAIRealtimeLogger.Error("Failed to send finalize message", "err", err)
 		return fmt.Errorf("finalize write: %w", err)
 	}
 
-	log.Printf("openai-realtime: sent commit, waiting for final transcription")
+	openAIRealtimeLogger.Debug("Sent commit, waiting for final transcription")
 
 	// wait for transcription.completed or timeout
 	select {
 	case <-a.transcriptionDone:
-		log.Printf("openai-realtime: finalize complete")
+		openAIRealtimeLogger.Debug("Finalize complete")
 		return nil
 	case <-ctx.Done():
-		log.Printf("openai-realtime: finalize timeout")
+		openAIRealtimeLogger.Warn("Finalize timed out")
 		return ctx.Err()
 	case <-a.ctx.Done():
 		return a.ctx.Err()
@@ -597,6 +599,6 @@ func (a *OpenAIRealtimeAdapter) Close() error {
 	// wait for reader to finish
 	a.wg.Wait()
 
-	log.Printf("openai-realtime: closed")
+	openAIRealtimeLogger.Debug("Closed")
 	return nil
 }
@@ -4,12 +4,13 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 )
 
 // WhisperCppAdapter implements BatchAdapter for local whisper-cpp transcription
@@ -96,13 +97,13 @@ func (a *WhisperCppAdapter) Transcribe(ctx context.Context, audioData []byte) (s
 		if ctx.Err() != nil {
 			return "", ctx.Err()
 		}
-		log.Printf("whisper-cpp: command failed after %v: %v\nstderr: %s", duration, err, stderr.String())
+		logger.Error("Command failed", "provider", "whisper-cpp", "duration", duration, "err", err, "stderr", stderr.String())
 		return "", fmt.Errorf("whisper-cli failed: %w", err)
 	}
 
 	// parse output - whisper-cli outputs transcription text directly (with -nt flag)
 	text := strings.TrimSpace(stdout.String())
 
-	log.Printf("whisper-cpp: transcribed %d bytes in %v: %q", len(audioData), duration, text)
+	logger.Info("Transcribed audio", "provider", "whisper-cpp", "bytes", len(audioData), "duration", duration, logging.Text("text", text))
 	return text, nil
 }
@@ -3,9 +3,9 @@ package transcriber
 import (
 	"context"
 	"fmt"
-	"log"
 	"sync"
 
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 	"github.com/leonardotrapani/hyprvoice/internal/recording"
 )
 
@@ -76,12 +76,12 @@ func (t *SimpleTranscriber) collectAudio(ctx context.Context, frameCh <-chan rec
 	for {
 		select {
 		case <-ctx.Done():
-			log.Printf("transcriber: stopping audio collection")
+			logger.Debug("Stopping audio collection")
 			return
 
 		case frame, ok := <-frameCh:
 			if !ok {
-				log.Printf("transcriber: audio channel closed")
+				logger.Debug("Audio channel closed")
 				return
 			}
 
@@ -99,20 +99,26 @@ func (t *SimpleTranscriber) transcribeAll(ctx context.Context) error {
 	t.bufferMu.Unlock()
 
 	if len(audioData) == 0 {
-		log.Printf("transcriber: no audio data to transcribe")
+		logger.Info("No audio data to transcribe")
 		return nil
 	}
 
-	log.Printf("transcriber: transcribing %d bytes of audio", len(audioData))
+	if t.config.Trim.Enabled {
+		trimmed := TrimSilence(audioData, t.config.Trim)
+		logger.Debug("Trimmed silence", "from_bytes", len(audioData), "to_bytes", len(trimmed))
+		audioData = trimmed
+	}
+
+	logger.Debug("Transcribing audio", "bytes", len(audioData))
 
 	// Use the context passed from the pipeline for proper cancellation chain
 	text, err := t.adapter.Transcribe(ctx, audioData)
 	if err != nil {
-		log.Printf("transcriber: transcription failed: %v", err)
+		logger.Error("Transcription failed", "err", err)
 		return fmt.Errorf("transcription failed: %w", err)
 	}
 
-	log.Printf("transcriber: transcription completed: %q", text)
+	logger.Debug("Transcription completed", logging.Text("text", text))
 
 	t.transcriptionMu.Lock()
 	t.transcriptionText = text
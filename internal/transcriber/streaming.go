@@ -9,6 +9,12 @@ type TranscriptionResult struct {
 	Error   error  // non-nil if an error occurred
 }
 
+// ResultReporter is implemented by transcribers that surface partial and final
+// results while audio is still being captured
+type ResultReporter interface {
+	Results() <-chan TranscriptionResult
+}
+
 // StreamingAdapter interface for streaming transcription backends (send audio in real-time)
 type StreamingAdapter interface {
 	// Start initiates the streaming connection with the given language setting
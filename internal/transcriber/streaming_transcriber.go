@@ -3,7 +3,6 @@ package transcriber
 import (
 	"context"
 	"errors"
-	"log"
 	"strings"
 	"sync"
 	"time"
@@ -11,6 +10,9 @@ import (
 	"github.com/leonardotrapani/hyprvoice/internal/recording"
 )
 
+// sendDrainTimeout bounds how long Stop waits for queued audio to be sent
+const sendDrainTimeout = 2 * time.Second
+
 // StreamingTranscriber wraps a StreamingAdapter and implements the Transcriber interface.
 // It streams audio chunks to the adapter in real-time and accumulates transcription results.
 type StreamingTranscriber struct {
@@ -22,16 +24,37 @@ type StreamingTranscriber struct {
 	mu        sync.Mutex
 	fatalErr  error
 
+	// live partial/final results for observers
+	resultsCh chan TranscriptionResult
+
 	// coordination
-	ctx    context.Context
-	cancel context.CancelFunc
-	wg     sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	sendDone chan struct{} // closed once sendAudio has returned
 }
 
 func NewStreamingTranscriber(adapter StreamingAdapter, language string) *StreamingTranscriber {
 	return &StreamingTranscriber{
-		adapter:  adapter,
-		language: language,
+		adapter:   adapter,
+		language:  language,
+		resultsCh: make(chan TranscriptionResult, 32),
+	}
+}
+
+// Results returns partial and final transcription results as they arrive.
+// Results are dropped when nobody is reading.
+func (t *StreamingTranscriber) Results() <-chan TranscriptionResult {
+	return t.resultsCh
+}
+
+func (t *StreamingTranscriber) reportResult(result TranscriptionResult) {
+	if result.Text == "" {
+		return
+	}
+	select {
+	case t.resultsCh <- result:
+	default:
 	}
 }
 
@@ -44,6 +67,7 @@ func (t *StreamingTranscriber) Start(ctx context.Context, frameCh <-chan recordi
 	}
 
 	errCh := make(chan error, 2)
+	t.sendDone = make(chan struct{})
 
 	// goroutine 1: read audio frames and send to adapter
 	t.wg.Add(1)
@@ -58,6 +82,7 @@ func (t *StreamingTranscriber) Start(ctx context.Context, frameCh <-chan recordi
 
 func (t *StreamingTranscriber) sendAudio(frameCh <-chan recording.AudioFrame, errCh chan<- error) {
 	defer t.wg.Done()
+	defer close(t.sendDone)
 
 	for {
 		select {
@@ -91,7 +116,7 @@ func (t *StreamingTranscriber) sendAudio(frameCh <-chan recording.AudioFrame, er
 				default:
 				}
 				// don't treat send errors as fatal - adapter may handle reconnection
-				log.Printf("streaming transcriber: send error: %v", err)
+				logger.Warn("Failed to send audio", "err", err)
 			}
 		}
 	}
@@ -125,7 +150,7 @@ func (t *StreamingTranscriber) processResult(result TranscriptionResult, errCh c
 				default:
 				}
 			}
-			log.Printf("streaming transcriber: result error: %v", result.Error)
+			logger.Warn("Result error", "err", result.Error)
 			if t.cancel != nil {
 				t.cancel()
 			}
@@ -135,9 +160,10 @@ func (t *StreamingTranscriber) processResult(result TranscriptionResult, errCh c
 		case errCh <- result.Error:
 		default:
 		}
-		log.Printf("streaming transcriber: result error: %v", result.Error)
+		logger.Warn("Result error", "err", result.Error)
 		return
 	}
+	t.reportResult(result)
 	if result.IsFinal && result.Text != "" {
 		t.mu.Lock()
 		if t.finalText.Len() > 0 {
@@ -158,6 +184,7 @@ func (t *StreamingTranscriber) drainRemainingResults(resultsCh <-chan Transcript
 				return
 			}
 			if result.IsFinal && result.Text != "" {
+				t.reportResult(result)
 				t.mu.Lock()
 				if t.finalText.Len() > 0 {
 					t.finalText.WriteString(" ")
@@ -172,10 +199,20 @@ func (t *StreamingTranscriber) drainRemainingResults(resultsCh <-chan Transcript
 }
 
 func (t *StreamingTranscriber) Stop(ctx context.Context) error {
+	// let frames still queued after the frame channel closed reach the adapter
+	if t.sendDone != nil {
+		select {
+		case <-t.sendDone:
+		case <-ctx.Done():
+		case <-time.After(sendDrainTimeout):
+			logger.Warn("Audio still pending, finalizing anyway", "after", sendDrainTimeout)
+		}
+	}
+
 	// finalize adapter first to commit pending audio and wait for final results
 	// this must happen before canceling context so receiveResults can collect them
 	if err := t.adapter.Finalize(ctx); err != nil {
-		log.Printf("streaming transcriber: finalize error (continuing): %v", err)
+		logger.Warn("Finalize failed, continuing", "err", err)
 	}
 
 	// now cancel context to stop goroutines
@@ -0,0 +1,45 @@
+package transcriber
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/recording"
+)
+
+const (
+	bytesPerMs = 32               // 16 kHz s16 mono
+	frameBytes = 100 * bytesPerMs // chunk size fed to the transcriber
+)
+
+// TranscribeAudio runs t over a complete recording of 16 kHz mono s16le
+// audio, the way the pipeline does for a dictation, and returns the final text
+func TranscribeAudio(ctx context.Context, t Transcriber, pcm []byte) (string, error) {
+	frameCh := make(chan recording.AudioFrame)
+	// errors on the returned channel are either retried by streaming
+	// adapters or surface again from Stop, so it is not read here
+	if _, err := t.Start(ctx, frameCh); err != nil {
+		return "", fmt.Errorf("failed to start transcriber: %w", err)
+	}
+
+	start := time.Now()
+	for off := 0; off < len(pcm); off += frameBytes {
+		end := min(off+frameBytes, len(pcm))
+		offset := time.Duration(off/bytesPerMs) * time.Millisecond
+		frame := recording.AudioFrame{Data: pcm[off:end], Timestamp: start.Add(offset)}
+		select {
+		case frameCh <- frame:
+		case <-ctx.Done():
+			close(frameCh)
+			_ = t.Stop(ctx)
+			return "", ctx.Err()
+		}
+	}
+	close(frameCh)
+
+	if err := t.Stop(ctx); err != nil {
+		return "", err
+	}
+	return t.GetFinalTranscription()
+}
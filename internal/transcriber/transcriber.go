@@ -7,11 +7,14 @@ import (
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
 	"github.com/leonardotrapani/hyprvoice/internal/models/whisper"
 	"github.com/leonardotrapani/hyprvoice/internal/provider"
 	"github.com/leonardotrapani/hyprvoice/internal/recording"
 )
 
+var logger = logging.For("transcriber")
+
 // Main transcriber interface
 type Transcriber interface {
 	Start(ctx context.Context, frameCh <-chan recording.AudioFrame) (<-chan error, error)
@@ -33,6 +36,7 @@ type Config struct {
 	Keywords  []string
 	Threads   int  // CPU threads for local transcription (0 = auto)
 	Streaming bool // use streaming mode if model supports it
+	Trim      TrimConfig
 }
 
 // NewTranscriber creates a new transcriber based on model metadata
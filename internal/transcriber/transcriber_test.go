@@ -1048,3 +1048,66 @@ func TestNewTranscriber_MultilingualModelAllLanguages(t *testing.T) {
 		t.Errorf("NewTranscriber() returned nil transcriber")
 	}
 }
+
+func TestTranscribeAudio(t *testing.T) {
+	pcm := make([]byte, 10000)
+	for i := range pcm {
+		pcm[i] = byte(i)
+	}
+
+	t.Run("batch", func(t *testing.T) {
+		var got []byte
+		adapter := &MockBatchAdapter{TranscribeFunc: func(ctx context.Context, audioData []byte) (string, error) {
+			got = audioData
+			return "batch text", nil
+		}}
+
+		text, err := TranscribeAudio(context.Background(), NewSimpleTranscriber(Config{}, adapter), pcm)
+		if err != nil {
+			t.Fatalf("TranscribeAudio() error = %v", err)
+		}
+		if text != "batch text" {
+			t.Errorf("TranscribeAudio() = %q, want %q", text, "batch text")
+		}
+		if string(got) != string(pcm) {
+			t.Errorf("adapter got %d bytes, want the whole %d byte recording", len(got), len(pcm))
+		}
+	})
+
+	t.Run("streaming sends every chunk before finalizing", func(t *testing.T) {
+		adapter := NewMockStreamingAdapter()
+		var sent int
+		adapter.SendChunkFunc = func(audio []byte) error {
+			time.Sleep(time.Millisecond)
+			sent += len(audio)
+			return nil
+		}
+		var sentAtFinalize int
+		adapter.FinalizeFunc = func(ctx context.Context) error {
+			sentAtFinalize = sent
+			adapter.SendResult(TranscriptionResult{Text: "streamed text", IsFinal: true})
+			return nil
+		}
+
+		text, err := TranscribeAudio(context.Background(), NewStreamingTranscriber(adapter, "en"), pcm)
+		if err != nil {
+			t.Fatalf("TranscribeAudio() error = %v", err)
+		}
+		if text != "streamed text" {
+			t.Errorf("TranscribeAudio() = %q, want %q", text, "streamed text")
+		}
+		if sentAtFinalize != len(pcm) {
+			t.Errorf("sent %d bytes before Finalize, want %d", sentAtFinalize, len(pcm))
+		}
+	})
+
+	t.Run("start error", func(t *testing.T) {
+		adapter := NewMockStreamingAdapter()
+		adapter.StartFunc = func(ctx context.Context, language string) error {
+			return fmt.Errorf("connection failed")
+		}
+		if _, err := TranscribeAudio(context.Background(), NewStreamingTranscriber(adapter, "en"), pcm); err == nil {
+			t.Errorf("TranscribeAudio() should fail when the adapter cannot start")
+		}
+	})
+}
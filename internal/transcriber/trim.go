@@ -0,0 +1,96 @@
+package transcriber
+
+import (
+	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/recording"
+)
+
+// trimWindowBytes is the 20ms window silence is detected in
+const trimWindowBytes = 20 * bytesPerMs
+
+// minSpeechWindows is the shortest run of loud windows that counts as speech
+// (100ms), so a click or a key press does not keep the silence around it
+const minSpeechWindows = 5
+
+// TrimConfig controls how silence is cut from recordings before batch upload
+type TrimConfig struct {
+	Enabled   bool
+	Threshold float64       // RMS level (0-1) that counts as speech
+	Margin    time.Duration // audio kept around speech so words are not clipped
+	MaxPause  time.Duration // pauses longer than this are shortened to it (0 = keep pauses)
+}
+
+// TrimSilence cuts leading and trailing silence from 16 kHz mono s16le
+// audio, keeping cfg.Margin around the speech, and shortens pauses longer
+// than cfg.MaxPause. Audio without any speech is returned unchanged, so a
+// quiet microphone never loses a dictation.
+func TrimSilence(pcm []byte, cfg TrimConfig) []byte {
+	windows := len(pcm) / trimWindowBytes
+	speech := make([]bool, windows)
+	for i := range speech {
+		speech[i] = recording.IsSpeech(pcm[i*trimWindowBytes:(i+1)*trimWindowBytes], cfg.Threshold)
+	}
+	dropShortRuns(speech, minSpeechWindows)
+
+	first, last := -1, -1
+	for i, s := range speech {
+		if s {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return pcm
+	}
+
+	margin := int(cfg.Margin/time.Millisecond) * bytesPerMs
+	start := max(0, first*trimWindowBytes-margin)
+	end := min(len(pcm), (last+1)*trimWindowBytes+margin)
+	if cfg.MaxPause <= 0 {
+		return pcm[start:end]
+	}
+
+	// keep half of the allowed pause after the speech before a long pause
+	// and half before the speech after it
+	keep := int(cfg.MaxPause/time.Millisecond) * bytesPerMs / 2
+	out := make([]byte, 0, end-start)
+	pos := start
+	for i := first; i <= last; {
+		if speech[i] {
+			i++
+			continue
+		}
+		j := i
+		for !speech[j] {
+			j++
+		}
+		pauseStart, pauseEnd := i*trimWindowBytes, j*trimWindowBytes
+		if pauseEnd-pauseStart > 2*keep {
+			out = append(out, pcm[pos:pauseStart+keep]...)
+			pos = pauseEnd - keep
+		}
+		i = j
+	}
+	return append(out, pcm[pos:end]...)
+}
+
+// dropShortRuns clears runs of speech windows shorter than minRun
+func dropShortRuns(speech []bool, minRun int) {
+	for i := 0; i < len(speech); {
+		if !speech[i] {
+			i++
+			continue
+		}
+		j := i
+		for j < len(speech) && speech[j] {
+			j++
+		}
+		if j-i < minRun {
+			clear(speech[i:j])
+		}
+		i = j
+	}
+}
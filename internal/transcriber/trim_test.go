@@ -0,0 +1,118 @@
+package transcriber
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// pcmOf returns d of 16 kHz mono s16le audio at a constant amplitude
+func pcmOf(amplitude int16, d time.Duration) []byte {
+	pcm := make([]byte, int(d/time.Millisecond)*bytesPerMs)
+	for i := 0; i < len(pcm); i += 2 {
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(amplitude))
+	}
+	return pcm
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+func TestTrimSilence(t *testing.T) {
+	silence := func(d time.Duration) []byte { return pcmOf(0, d) }
+	speech := func(d time.Duration) []byte { return pcmOf(5000, d) }
+	ms := func(pcm []byte) int { return len(pcm) / bytesPerMs }
+
+	tests := []struct {
+		name   string
+		pcm    []byte
+		cfg    TrimConfig
+		wantMs int
+	}{
+		{
+			name:   "leading and trailing silence",
+			pcm:    concat(silence(time.Second), speech(500*time.Millisecond), silence(2*time.Second)),
+			cfg:    TrimConfig{Threshold: 0.01, Margin: 200 * time.Millisecond},
+			wantMs: 900,
+		},
+		{
+			name:   "margin clamped at the edges",
+			pcm:    concat(silence(100*time.Millisecond), speech(500*time.Millisecond), silence(100*time.Millisecond)),
+			cfg:    TrimConfig{Threshold: 0.01, Margin: 300 * time.Millisecond},
+			wantMs: 700,
+		},
+		{
+			name:   "click is not speech",
+			pcm:    concat(silence(500*time.Millisecond), speech(40*time.Millisecond), silence(time.Second), speech(500*time.Millisecond), silence(time.Second)),
+			cfg:    TrimConfig{Threshold: 0.01, Margin: 200 * time.Millisecond},
+			wantMs: 900,
+		},
+		{
+			name:   "only clicks kept whole",
+			pcm:    concat(silence(time.Second), speech(40*time.Millisecond), silence(time.Second)),
+			cfg:    TrimConfig{Threshold: 0.01, Margin: 200 * time.Millisecond},
+			wantMs: 2040,
+		},
+		{
+			name:   "no speech kept whole",
+			pcm:    silence(time.Second),
+			cfg:    TrimConfig{Threshold: 0.01, Margin: 200 * time.Millisecond},
+			wantMs: 1000,
+		},
+		{
+			name:   "internal pause kept without max_pause",
+			pcm:    concat(speech(200*time.Millisecond), silence(3*time.Second), speech(200*time.Millisecond)),
+			cfg:    TrimConfig{Threshold: 0.01},
+			wantMs: 3400,
+		},
+		{
+			name:   "long pause shortened",
+			pcm:    concat(speech(200*time.Millisecond), silence(3*time.Second), speech(200*time.Millisecond)),
+			cfg:    TrimConfig{Threshold: 0.01, MaxPause: 600 * time.Millisecond},
+			wantMs: 1000,
+		},
+		{
+			name:   "short pause untouched",
+			pcm:    concat(speech(200*time.Millisecond), silence(400*time.Millisecond), speech(200*time.Millisecond)),
+			cfg:    TrimConfig{Threshold: 0.01, MaxPause: 600 * time.Millisecond},
+			wantMs: 800,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TrimSilence(tt.pcm, tt.cfg)
+			if ms(got) != tt.wantMs {
+				t.Errorf("TrimSilence() kept %dms, want %dms", ms(got), tt.wantMs)
+			}
+			if len(got)%2 != 0 {
+				t.Errorf("TrimSilence() split a sample: %d bytes", len(got))
+			}
+		})
+	}
+}
+
+func TestSimpleTranscriber_Trim(t *testing.T) {
+	var uploaded int
+	adapter := &MockBatchAdapter{
+		TranscribeFunc: func(ctx context.Context, audioData []byte) (string, error) {
+			uploaded = len(audioData)
+			return "hello", nil
+		},
+	}
+	cfg := Config{Trim: TrimConfig{Enabled: true, Threshold: 0.01, Margin: 100 * time.Millisecond}}
+	transcriber := NewSimpleTranscriber(cfg, adapter)
+	transcriber.audioBuffer = concat(pcmOf(0, time.Second), pcmOf(5000, 500*time.Millisecond), pcmOf(0, time.Second))
+
+	if err := transcriber.transcribeAll(context.Background()); err != nil {
+		t.Fatalf("transcribeAll() error = %v", err)
+	}
+	if want := 700 * bytesPerMs; uploaded != want {
+		t.Errorf("uploaded %d bytes, want %d", uploaded, want)
+	}
+}
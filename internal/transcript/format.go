@@ -0,0 +1,126 @@
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatSRT  = "srt"
+	FormatVTT  = "vtt"
+)
+
+// Formats lists the output formats
+var Formats = []string{FormatText, FormatJSON, FormatSRT, FormatVTT}
+
+// ValidateFormat checks that format is one of Formats
+func ValidateFormat(format string) error {
+	for _, f := range Formats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid format: %s (must be one of %s)", format, strings.Join(Formats, ", "))
+}
+
+// FormatForPath guesses the format from the extension of path, FormatText
+// when it is not a known one
+func FormatForPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".srt":
+		return FormatSRT
+	case ".vtt":
+		return FormatVTT
+	}
+	return FormatText
+}
+
+// IsSubtitle reports whether format is a timed subtitle format
+func IsSubtitle(format string) bool {
+	return format == FormatSRT || format == FormatVTT
+}
+
+// Write writes r to w in format
+func Write(w io.Writer, format string, r *Result) error {
+	switch format {
+	case FormatText:
+		if r.Text == "" {
+			return nil
+		}
+		_, err := fmt.Fprintln(w, r.Text)
+		return err
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(toJSON(r))
+	case FormatSRT:
+		for i, s := range r.Segments {
+			if _, err := fmt.Fprintf(w, "%d\n%s --> %s\n%s\n\n", i+1, subtitleTime(s.Start, ","), subtitleTime(s.End, ","), s.Text); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatVTT:
+		if _, err := fmt.Fprint(w, "WEBVTT\n\n"); err != nil {
+			return err
+		}
+		for _, s := range r.Segments {
+			if _, err := fmt.Fprintf(w, "%s --> %s\n%s\n\n", subtitleTime(s.Start, "."), subtitleTime(s.End, "."), s.Text); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return ValidateFormat(format)
+}
+
+// subtitleTime formats d as hh:mm:ss followed by sep and milliseconds
+func subtitleTime(d time.Duration, sep string) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+type jsonSegment struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+type jsonResult struct {
+	Text        string        `json:"text"`
+	Language    string        `json:"language,omitempty"`
+	Duration    float64       `json:"duration"`
+	Provider    string        `json:"provider"`
+	Model       string        `json:"model"`
+	LLMProvider string        `json:"llm_provider,omitempty"`
+	LLMModel    string        `json:"llm_model,omitempty"`
+	Segments    []jsonSegment `json:"segments"`
+	Warnings    []string      `json:"warnings,omitempty"`
+}
+
+func toJSON(r *Result) jsonResult {
+	out := jsonResult{
+		Text:        r.Text,
+		Language:    r.Language,
+		Duration:    r.Duration.Seconds(),
+		Provider:    r.Provider,
+		Model:       r.Model,
+		LLMProvider: r.LLMProvider,
+		LLMModel:    r.LLMModel,
+		Segments:    []jsonSegment{},
+		Warnings:    r.Warnings,
+	}
+	for _, s := range r.Segments {
+		out.Segments = append(out.Segments, jsonSegment{Start: s.Start.Seconds(), End: s.End.Seconds(), Text: s.Text})
+	}
+	return out
+}
@@ -0,0 +1,173 @@
+// Package transcript transcribes complete recordings, such as voice memos
+// and meeting recordings, with the configured providers and formats the
+// result as text, JSON or subtitles.
+package transcript
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/audiofile"
+	"github.com/leonardotrapani/hyprvoice/internal/config"
+	"github.com/leonardotrapani/hyprvoice/internal/llm"
+	"github.com/leonardotrapani/hyprvoice/internal/logging"
+	"github.com/leonardotrapani/hyprvoice/internal/transcriber"
+)
+
+var logger = logging.For("transcript")
+
+const (
+	// DefaultSegmentLength keeps uploads well below provider size limits
+	DefaultSegmentLength = 5 * time.Minute
+	// SubtitleSegmentLength gives subtitle cues a readable length
+	SubtitleSegmentLength = 10 * time.Second
+)
+
+// Segment is the transcript of one piece of the recording
+type Segment struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// Result is the transcript of a recording
+type Result struct {
+	Text        string
+	Language    string
+	Duration    time.Duration
+	Provider    string
+	Model       string
+	LLMProvider string
+	LLMModel    string
+	Segments    []Segment
+	// Warnings lists problems that did not stop the transcription, such as
+	// an LLM failure that left a segment unprocessed
+	Warnings []string
+}
+
+// Factory types for dependency injection
+type TranscriberFactory func(cfg transcriber.Config) (transcriber.Transcriber, error)
+type LLMAdapterFactory func(cfg llm.Config) (llm.Adapter, error)
+
+// Option configures Transcribe
+type Option func(*options)
+
+type options struct {
+	segmentLength      time.Duration
+	progress           func(done, total int)
+	transcriberFactory TranscriberFactory
+	llmAdapterFactory  LLMAdapterFactory
+}
+
+// WithSegmentLength sets the longest piece transcribed in one request
+func WithSegmentLength(d time.Duration) Option {
+	return func(o *options) {
+		o.segmentLength = d
+	}
+}
+
+// WithProgress calls f after each segment
+func WithProgress(f func(done, total int)) Option {
+	return func(o *options) {
+		o.progress = f
+	}
+}
+
+// WithTranscriberFactory sets a custom transcriber factory
+func WithTranscriberFactory(f TranscriberFactory) Option {
+	return func(o *options) {
+		o.transcriberFactory = f
+	}
+}
+
+// WithLLMAdapterFactory sets a custom LLM adapter factory
+func WithLLMAdapterFactory(f LLMAdapterFactory) Option {
+	return func(o *options) {
+		o.llmAdapterFactory = f
+	}
+}
+
+// Transcribe transcribes pcm (16 kHz mono s16le) with the transcription
+// provider of cfg. The recording is split at quiet points into segments no
+// longer than the segment length, and each segment is transcribed with a
+// fresh transcriber and, when enabled, post-processed by the LLM.
+func Transcribe(ctx context.Context, cfg *config.Config, pcm []byte, opts ...Option) (*Result, error) {
+	o := options{
+		segmentLength:      DefaultSegmentLength,
+		transcriberFactory: transcriber.NewTranscriber,
+		llmAdapterFactory:  llm.NewAdapter,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	tcfg := cfg.ToTranscriberConfig()
+	result := &Result{
+		Language: tcfg.Language,
+		Duration: audiofile.Duration(pcm),
+		Provider: tcfg.Provider,
+		Model:    tcfg.Model,
+	}
+
+	var adapter llm.Adapter
+	if cfg.IsLLMEnabled() {
+		llmCfg := cfg.ToLLMConfig()
+		a, err := o.llmAdapterFactory(llm.Config(llmCfg))
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("LLM post-processing skipped: failed to create LLM adapter: %v", err))
+		} else {
+			adapter = a
+			result.LLMProvider = llmCfg.Provider
+			result.LLMModel = llmCfg.Model
+		}
+	}
+
+	chunks := audiofile.Split(pcm, o.segmentLength)
+	var texts []string
+	for i, chunk := range chunks {
+		t, err := o.transcriberFactory(tcfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create transcriber: %w", err)
+		}
+		text, err := transcriber.TranscribeAudio(ctx, t, chunk.PCM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to transcribe %s-%s: %w", FormatClock(chunk.Start), FormatClock(chunk.End()), err)
+		}
+		text = strings.TrimSpace(text)
+
+		if adapter != nil && text != "" {
+			processed, err := adapter.Process(ctx, text)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				logger.Warn("LLM processing failed, using raw transcription", "err", err)
+				result.Warnings = append(result.Warnings, fmt.Sprintf("LLM processing failed for %s-%s, kept the raw transcription: %v", FormatClock(chunk.Start), FormatClock(chunk.End()), err))
+			} else {
+				text = strings.TrimSpace(processed)
+			}
+		}
+
+		if text != "" {
+			result.Segments = append(result.Segments, Segment{Start: chunk.Start, End: chunk.End(), Text: text})
+			texts = append(texts, text)
+		}
+		if o.progress != nil {
+			o.progress(i+1, len(chunks))
+		}
+	}
+
+	result.Text = strings.Join(texts, "\n\n")
+	return result, nil
+}
+
+// FormatClock formats d as h:mm:ss, or m:ss below an hour
+func FormatClock(d time.Duration) string {
+	s := int(d / time.Second)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
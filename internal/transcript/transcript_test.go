@@ -0,0 +1,194 @@
+package transcript
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leonardotrapani/hyprvoice/internal/audiofile"
+	"github.com/leonardotrapani/hyprvoice/internal/llm"
+	"github.com/leonardotrapani/hyprvoice/internal/testutil"
+	"github.com/leonardotrapani/hyprvoice/internal/transcriber"
+)
+
+// numbered returns a transcriber factory whose transcribers say "part N"
+func numbered(calls *int) TranscriberFactory {
+	return func(cfg transcriber.Config) (transcriber.Transcriber, error) {
+		*calls++
+		return testutil.NewMockTranscriber(fmt.Sprintf(" part %d ", *calls)), nil
+	}
+}
+
+func TestTranscribe(t *testing.T) {
+	pcm := make([]byte, 25*audiofile.BytesPerSecond)
+
+	t.Run("segments", func(t *testing.T) {
+		var calls int
+		var progress []int
+		r, err := Transcribe(context.Background(), testutil.TestConfig(), pcm,
+			WithSegmentLength(10*time.Second),
+			WithTranscriberFactory(numbered(&calls)),
+			WithProgress(func(done, total int) { progress = append(progress, done*10+total) }),
+		)
+		if err != nil {
+			t.Fatalf("Transcribe() error = %v", err)
+		}
+		if calls != 3 || len(r.Segments) != 3 {
+			t.Fatalf("got %d transcribers and %d segments, want 3 each", calls, len(r.Segments))
+		}
+		if r.Text != "part 1\n\npart 2\n\npart 3" {
+			t.Errorf("Text = %q", r.Text)
+		}
+		if r.Segments[0].Start != 0 || r.Segments[2].End != 25*time.Second || r.Segments[1].Start != r.Segments[0].End {
+			t.Errorf("segments = %+v", r.Segments)
+		}
+		if r.Provider != "openai" || r.Model != "whisper-1" || r.Duration != 25*time.Second {
+			t.Errorf("result = %+v", r)
+		}
+		if fmt.Sprint(progress) != "[13 23 33]" {
+			t.Errorf("progress = %v", progress)
+		}
+	})
+
+	t.Run("llm", func(t *testing.T) {
+		cfg := testutil.TestConfig()
+		cfg.LLM.Enabled = true
+		cfg.LLM.Provider = "openai"
+		cfg.LLM.Model = "gpt-4o-mini"
+
+		var calls int
+		var inputs []string
+		r, err := Transcribe(context.Background(), cfg, pcm,
+			WithSegmentLength(10*time.Second),
+			WithTranscriberFactory(numbered(&calls)),
+			WithLLMAdapterFactory(func(cfg llm.Config) (llm.Adapter, error) {
+				return llmFunc(func(text string) (string, error) {
+					inputs = append(inputs, text)
+					if text == "part 2" {
+						return "", fmt.Errorf("rate limited")
+					}
+					return strings.ToUpper(text), nil
+				}), nil
+			}),
+		)
+		if err != nil {
+			t.Fatalf("Transcribe() error = %v", err)
+		}
+		if len(inputs) != 3 {
+			t.Errorf("LLM called %d times, want once per segment", len(inputs))
+		}
+		// a failed segment keeps its raw text and is reported
+		if r.Text != "PART 1\n\npart 2\n\nPART 3" {
+			t.Errorf("Text = %q", r.Text)
+		}
+		if len(r.Warnings) != 1 || !strings.Contains(r.Warnings[0], "rate limited") {
+			t.Errorf("Warnings = %v", r.Warnings)
+		}
+		if r.LLMModel != "gpt-4o-mini" {
+			t.Errorf("LLMModel = %q", r.LLMModel)
+		}
+	})
+
+	t.Run("transcription error", func(t *testing.T) {
+		_, err := Transcribe(context.Background(), testutil.TestConfig(), pcm,
+			WithTranscriberFactory(func(cfg transcriber.Config) (transcriber.Transcriber, error) {
+				mock := testutil.NewMockTranscriber("")
+				mock.StopError = fmt.Errorf("quota exceeded")
+				return mock, nil
+			}),
+		)
+		if err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+			t.Errorf("Transcribe() error = %v, want the provider error", err)
+		}
+	})
+}
+
+type llmFunc func(text string) (string, error)
+
+func (f llmFunc) Process(ctx context.Context, text string) (string, error) {
+	return f(text)
+}
+
+func TestWrite(t *testing.T) {
+	r := &Result{
+		Text:     "Hello there.\n\nGeneral Kenobi.",
+		Duration: 3725 * time.Second,
+		Provider: "openai",
+		Model:    "whisper-1",
+		Segments: []Segment{
+			{Start: 0, End: 2500 * time.Millisecond, Text: "Hello there."},
+			{Start: 3720 * time.Second, End: 3725 * time.Second, Text: "General Kenobi."},
+		},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{FormatText, "Hello there.\n\nGeneral Kenobi.\n"},
+		{FormatSRT, "1\n00:00:00,000 --> 00:00:02,500\nHello there.\n\n2\n01:02:00,000 --> 01:02:05,000\nGeneral Kenobi.\n\n"},
+		{FormatVTT, "WEBVTT\n\n00:00:00.000 --> 00:00:02.500\nHello there.\n\n01:02:00.000 --> 01:02:05.000\nGeneral Kenobi.\n\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Write(&buf, tt.format, r); err != nil {
+			t.Fatalf("Write(%s) error = %v", tt.format, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("Write(%s) = %q, want %q", tt.format, buf.String(), tt.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSON, r); err != nil {
+		t.Fatalf("Write(json) error = %v", err)
+	}
+	var decoded struct {
+		Text     string  `json:"text"`
+		Duration float64 `json:"duration"`
+		Segments []struct {
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+		} `json:"segments"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Text != r.Text || decoded.Duration != 3725 || len(decoded.Segments) != 2 || decoded.Segments[0].End != 2.5 {
+		t.Errorf("Write(json) = %s", buf.String())
+	}
+
+	if err := Write(&buf, "docx", r); err == nil {
+		t.Errorf("Write(docx) should fail")
+	}
+}
+
+func TestFormatForPath(t *testing.T) {
+	for path, want := range map[string]string{
+		"meeting.srt":  FormatSRT,
+		"meeting.VTT":  FormatVTT,
+		"meeting.json": FormatJSON,
+		"meeting.txt":  FormatText,
+		"-":            FormatText,
+	} {
+		if got := FormatForPath(path); got != want {
+			t.Errorf("FormatForPath(%s) = %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestFormatClock(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                  "0:00",
+		75 * time.Second:   "1:15",
+		3725 * time.Second: "1:02:05",
+	} {
+		if got := FormatClock(d); got != want {
+			t.Errorf("FormatClock(%v) = %s, want %s", d, got, want)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/leonardotrapani/hyprvoice/internal/models/whisper"
 	"github.com/leonardotrapani/hyprvoice/internal/notify"
 	"github.com/leonardotrapani/hyprvoice/internal/provider"
+	"github.com/leonardotrapani/hyprvoice/internal/recording"
 )
 
 const (
@@ -715,7 +716,7 @@ func newAdvancedPromptScreen(state *wizardState, onBack func() screen) screen {
 
 func newAdvancedMenuScreen(state *wizardState, onBack func() screen, onboarding bool) screen {
 	items := []optionItem{
-		{title: formatAdvancedRecordingLabel(state.cfg), desc: "Sample rate, channels, device, and timeout.", value: "recording"},
+		{title: formatAdvancedRecordingLabel(state.cfg), desc: "Sample rate, channels, backend, device, and timeout.", value: "recording"},
 	}
 	if !onboarding {
 		items = append(items, optionItem{title: formatInjectionLabel(state.cfg), desc: "Backends for typing and clipboard fallback.", value: "injection"})
@@ -783,6 +784,18 @@ func newRecordingSettingsScreen(state *wizardState, onBack func() screen) screen
 			}
 			return nil
 		}),
+		makeInputField("backend", "Capture Backend", "pw-record (PipeWire), parec (PulseAudio), arecord (ALSA), or ffmpeg.", cfg.Backend, recording.DefaultBackend, func(s string) error {
+			switch s {
+			case recording.BackendPipeWire, recording.BackendPulse, recording.BackendALSA, recording.BackendFFmpeg:
+				return nil
+			case recording.BackendFile:
+				if cfg.File != "" {
+					return nil
+				}
+				return fmt.Errorf("set recording.file in the config file to use the file backend")
+			}
+			return fmt.Errorf("must be pw-record, parec, arecord, or ffmpeg")
+		}),
 		makeInputField("device", "Device", "Leave empty for default microphone.", cfg.Device, "(default)", nil),
 		makeInputField("timeout", "Recording Timeout", "Examples: 30s, 2m, 5m.", cfg.Timeout.String(), "5m", func(s string) error {
 			if _, err := time.ParseDuration(s); err != nil {
@@ -790,6 +803,14 @@ func newRecordingSettingsScreen(state *wizardState, onBack func() screen) screen
 			}
 			return nil
 		}),
+		makeInputField("timeout_warning", "Timeout Warning", "Notify this long before the timeout (0s = off).", cfg.TimeoutWarning.String(), "30s", func(s string) error {
+			if _, err := time.ParseDuration(s); err != nil {
+				return fmt.Errorf("invalid duration format")
+			}
+			return nil
+		}),
+		makeInputField("on_timeout", "On Timeout", "inject, save (to recovery file), or discard.", cfg.OnTimeout, "save", validatePolicy),
+		makeInputField("on_shutdown", "On Daemon Shutdown", "inject, save (to recovery file), or discard.", cfg.OnShutdown, "save", validatePolicy),
 	}
 	screen := newFormScreen(state, "Recording Settings", nil, fields, func(values map[string]string) screen {
 		state.cfg.Recording.SampleRate, _ = strconv.Atoi(values["sample_rate"])
@@ -797,14 +818,26 @@ func newRecordingSettingsScreen(state *wizardState, onBack func() screen) screen
 		state.cfg.Recording.Format = values["format"]
 		state.cfg.Recording.BufferSize, _ = strconv.Atoi(values["buffer_size"])
 		state.cfg.Recording.ChannelBufferSize, _ = strconv.Atoi(values["channel_buffer"])
+		state.cfg.Recording.Backend = values["backend"]
 		state.cfg.Recording.Device = values["device"]
 		state.cfg.Recording.Timeout, _ = time.ParseDuration(values["timeout"])
+		state.cfg.Recording.TimeoutWarning, _ = time.ParseDuration(values["timeout_warning"])
+		state.cfg.Recording.OnTimeout = values["on_timeout"]
+		state.cfg.Recording.OnShutdown = values["on_shutdown"]
 		return onBack()
 	}, onBack)
 	screen.footer = "enter save • esc back"
 	return screen
 }
 
+func validatePolicy(s string) error {
+	switch s {
+	case config.PolicyInject, config.PolicySave, config.PolicyDiscard:
+		return nil
+	}
+	return fmt.Errorf("must be inject, save, or discard")
+}
+
 func newInjectionTimeoutsScreen(state *wizardState, onBack func() screen) screen {
 	cfg := state.cfg.Injection
 	fields := []formField{